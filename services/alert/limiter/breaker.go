@@ -0,0 +1,134 @@
+package limiter
+
+import (
+	"sync"
+	"time"
+)
+
+// BreakerState is the circuit breaker's current state.
+type BreakerState string
+
+const (
+	StateClosed   BreakerState = "closed"
+	StateOpen     BreakerState = "open"
+	StateHalfOpen BreakerState = "half-open"
+)
+
+// breaker is a failure-threshold circuit breaker: it opens after
+// FailureThreshold consecutive failures, then after OpenDuration allows
+// up to HalfOpenMaxCalls probe requests through; SuccessThreshold
+// consecutive probe successes close it again, while any probe failure
+// reopens it immediately.
+type breaker struct {
+	cfg CircuitBreakerConfig
+	now func() time.Time
+
+	mu               sync.Mutex
+	state            BreakerState
+	consecutiveFails int
+	consecutiveOK    int
+	openedAt         time.Time
+	halfOpenInFlight int
+}
+
+func newBreaker(cfg CircuitBreakerConfig) *breaker {
+	return &breaker{cfg: cfg, now: time.Now, state: StateClosed}
+}
+
+// Allow reports whether a call may proceed, transitioning Open to
+// HalfOpen once OpenDuration has elapsed.
+func (b *breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateClosed:
+		return true
+	case StateOpen:
+		if b.now().Sub(b.openedAt) >= b.cfg.OpenDuration {
+			b.state = StateHalfOpen
+			b.halfOpenInFlight = 0
+			b.consecutiveOK = 0
+		} else {
+			return false
+		}
+		fallthrough
+	case StateHalfOpen:
+		max := b.cfg.HalfOpenMaxCalls
+		if max < 1 {
+			max = 1
+		}
+		if b.halfOpenInFlight >= max {
+			return false
+		}
+		b.halfOpenInFlight++
+		return true
+	}
+	return false
+}
+
+// RecordResult reports the outcome of a call that Allow permitted.
+func (b *breaker) RecordResult(ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ok {
+		b.consecutiveFails = 0
+		switch b.state {
+		case StateHalfOpen:
+			if b.halfOpenInFlight > 0 {
+				b.halfOpenInFlight--
+			}
+			b.consecutiveOK++
+			threshold := b.cfg.SuccessThreshold
+			if threshold < 1 {
+				threshold = 1
+			}
+			if b.consecutiveOK >= threshold {
+				b.state = StateClosed
+			}
+		}
+		return
+	}
+
+	switch b.state {
+	case StateHalfOpen:
+		b.open()
+	case StateClosed:
+		b.consecutiveFails++
+		threshold := b.cfg.FailureThreshold
+		if threshold < 1 {
+			threshold = 1
+		}
+		if b.consecutiveFails >= threshold {
+			b.open()
+		}
+	}
+}
+
+func (b *breaker) open() {
+	b.state = StateOpen
+	b.openedAt = b.now()
+	b.consecutiveFails = 0
+	b.consecutiveOK = 0
+	b.halfOpenInFlight = 0
+}
+
+// State reports the breaker's current state, for the /debug/vars
+// exporter.
+func (b *breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// tripsOn reports whether statusCode is one of the configured
+// trip-on-status-codes.
+func (cfg CircuitBreakerConfig) tripsOn(statusCode int) bool {
+	for _, c := range cfg.TripOnStatusCodes {
+		if c == statusCode {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+// Package limiter wraps an alert handler's HTTP client with a rate
+// limiter and a circuit breaker, both configurable as first-class
+// options on any handler section (alerta, hipchat, opsgenie, and the
+// rest), so a flapping downstream doesn't bring down the whole alert
+// pipeline.
+package limiter
+
+import "time"
+
+// CircuitBreakerConfig is the `circuit-breaker` object nested under a
+// handler's Options.
+type CircuitBreakerConfig struct {
+	FailureThreshold int           `toml:"failure-threshold" override:"failure-threshold"`
+	SuccessThreshold int           `toml:"success-threshold" override:"success-threshold"`
+	OpenDuration     time.Duration `toml:"open-duration" override:"open-duration"`
+	HalfOpenMaxCalls int           `toml:"half-open-max-calls" override:"half-open-max-calls"`
+
+	// TripOnStatusCodes lists the HTTP response codes that count as a
+	// failure, e.g. [429, 500, 502, 503].
+	TripOnStatusCodes []int `toml:"trip-on-status-codes" override:"trip-on-status-codes"`
+}
+
+// Overflow controls what happens to an alert while the breaker is open.
+type Overflow string
+
+const (
+	OverflowDrop    Overflow = "drop"
+	OverflowDisk    Overflow = "disk"
+	OverflowForward Overflow = "forward"
+)
+
+// Config is the set of options this package adds to every alert
+// handler's Options map.
+type Config struct {
+	RateLimit float64 `toml:"rate-limit" override:"rate-limit"`
+	RateBurst int     `toml:"rate-burst" override:"rate-burst"`
+
+	CircuitBreaker CircuitBreakerConfig `toml:"circuit-breaker" override:"circuit-breaker"`
+
+	Overflow      Overflow `toml:"overflow" override:"overflow"`
+	OnOpenForward string   `toml:"on-open-forward" override:"on-open-forward"`
+}
+
+func NewConfig() Config {
+	return Config{
+		RateLimit: 0, // 0 means unlimited
+		RateBurst: 1,
+		CircuitBreaker: CircuitBreakerConfig{
+			FailureThreshold:  5,
+			SuccessThreshold:  2,
+			OpenDuration:      30 * time.Second,
+			HalfOpenMaxCalls:  1,
+			TripOnStatusCodes: []int{429, 500, 502, 503},
+		},
+		Overflow: OverflowDrop,
+	}
+}
@@ -0,0 +1,129 @@
+package limiter
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+type fakeRoundTripper struct {
+	status int
+	err    error
+	calls  int
+}
+
+func (f *fakeRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &http.Response{StatusCode: f.status, Request: req}, nil
+}
+
+func newRequest(t *testing.T) *http.Request {
+	req, err := http.NewRequest("POST", "http://example.com/alert", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func TestTransport_RateLimitDropsOverBurst(t *testing.T) {
+	cfg := NewConfig()
+	cfg.RateLimit = 0 // would be unlimited; override below by hand for determinism
+	next := &fakeRoundTripper{status: http.StatusOK}
+	tr := NewTransport("t1", next, cfg)
+
+	// Force a tiny, deterministic bucket: 1 token/sec, burst 1.
+	tr.bucket = newTokenBucket(1, 1)
+
+	if _, err := tr.RoundTrip(newRequest(t)); err != nil {
+		t.Fatalf("expected first request to be allowed, got %v", err)
+	}
+	if _, err := tr.RoundTrip(newRequest(t)); err != ErrRateLimited {
+		t.Fatalf("expected second immediate request to be rate limited, got %v", err)
+	}
+
+	c := tr.Counters()
+	if c.Sent != 1 || c.DroppedRateLimit != 1 {
+		t.Fatalf("unexpected counters: %+v", c)
+	}
+}
+
+func TestTransport_BreakerOpensAfterFailures(t *testing.T) {
+	cfg := NewConfig()
+	cfg.RateLimit = 0
+	cfg.CircuitBreaker.FailureThreshold = 2
+	cfg.CircuitBreaker.OpenDuration = time.Hour
+
+	next := &fakeRoundTripper{status: http.StatusServiceUnavailable}
+	tr := NewTransport("t2", next, cfg)
+
+	for i := 0; i < 2; i++ {
+		if _, err := tr.RoundTrip(newRequest(t)); err != nil {
+			t.Fatalf("expected call %d to reach the handler, got %v", i, err)
+		}
+	}
+
+	if _, err := tr.RoundTrip(newRequest(t)); err != ErrBreakerOpen {
+		t.Fatalf("expected the breaker to be open after %d consecutive trip codes, got %v", cfg.CircuitBreaker.FailureThreshold, err)
+	}
+	if tr.BreakerState() != StateOpen {
+		t.Fatalf("expected breaker state open, got %s", tr.BreakerState())
+	}
+
+	c := tr.Counters()
+	if c.DroppedBreaker != 1 {
+		t.Fatalf("expected one breaker-dropped call, got %+v", c)
+	}
+}
+
+func TestTransport_BreakerClosesAfterHalfOpenSuccesses(t *testing.T) {
+	cfg := NewConfig()
+	cfg.RateLimit = 0
+	cfg.CircuitBreaker.FailureThreshold = 1
+	cfg.CircuitBreaker.SuccessThreshold = 1
+	cfg.CircuitBreaker.OpenDuration = time.Millisecond
+
+	next := &fakeRoundTripper{status: http.StatusServiceUnavailable}
+	tr := NewTransport("t3", next, cfg)
+
+	if _, err := tr.RoundTrip(newRequest(t)); err != nil {
+		t.Fatal(err)
+	}
+	if tr.BreakerState() != StateOpen {
+		t.Fatalf("expected breaker to be open, got %s", tr.BreakerState())
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	next.status = http.StatusOK
+
+	if _, err := tr.RoundTrip(newRequest(t)); err != nil {
+		t.Fatalf("expected the half-open probe to go through, got %v", err)
+	}
+	if tr.BreakerState() != StateClosed {
+		t.Fatalf("expected breaker to close after a successful probe, got %s", tr.BreakerState())
+	}
+}
+
+func TestTransport_UnderlyingTransportErrorTripsBreaker(t *testing.T) {
+	cfg := NewConfig()
+	cfg.RateLimit = 0
+	cfg.CircuitBreaker.FailureThreshold = 1
+
+	next := &fakeRoundTripper{err: errTransport}
+	tr := NewTransport("t4", next, cfg)
+
+	if _, err := tr.RoundTrip(newRequest(t)); err != errTransport {
+		t.Fatalf("expected the underlying transport error to propagate, got %v", err)
+	}
+	if tr.BreakerState() != StateOpen {
+		t.Fatalf("expected a dial error to count as a failure and open the breaker, got %s", tr.BreakerState())
+	}
+}
+
+type transportError struct{}
+
+func (transportError) Error() string { return "dial tcp: connection refused" }
+
+var errTransport = transportError{}
@@ -0,0 +1,61 @@
+package limiter
+
+import (
+	"sync"
+	"time"
+)
+
+// tokenBucket is a simple requests/sec token bucket with a burst
+// capacity, refilled lazily on each Allow call rather than by a
+// background goroutine.
+type tokenBucket struct {
+	rate  float64 // tokens per second; <= 0 means unlimited
+	burst float64
+
+	mu       sync.Mutex
+	tokens   float64
+	lastFill time.Time
+	now      func() time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	b := float64(burst)
+	if b < 1 {
+		b = 1
+	}
+	return &tokenBucket{
+		rate:     rate,
+		burst:    b,
+		tokens:   b,
+		lastFill: time.Time{},
+		now:      time.Now,
+	}
+}
+
+// Allow reports whether a request may proceed now, consuming one token
+// if so.
+func (b *tokenBucket) Allow() bool {
+	if b.rate <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if b.lastFill.IsZero() {
+		b.lastFill = now
+	}
+	elapsed := now.Sub(b.lastFill).Seconds()
+	b.tokens += elapsed * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.lastFill = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
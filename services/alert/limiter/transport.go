@@ -0,0 +1,125 @@
+package limiter
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+)
+
+// Counters are the per-handler health counters this package exposes
+// under /kapacitor/v1/debug/vars.
+type Counters struct {
+	Sent             int64
+	DroppedRateLimit int64
+	DroppedBreaker   int64
+}
+
+// counterVars exposes every handler's Counters as
+// alert_limiter.<name>.{sent,dropped_ratelimit,dropped_breaker,breaker_state}.
+var counterVars = expvar.NewMap("alert_limiter")
+
+// ErrRateLimited and ErrBreakerOpen are returned by RoundTrip instead of
+// making the request, so callers can implement Overflow handling
+// (buffering to disk, forwarding to a fallback handler) around this
+// transport.
+type rateLimitedError struct{}
+
+func (rateLimitedError) Error() string { return "limiter: request dropped by rate limiter" }
+
+type breakerOpenError struct{}
+
+func (breakerOpenError) Error() string { return "limiter: request dropped, circuit breaker is open" }
+
+var (
+	ErrRateLimited = rateLimitedError{}
+	ErrBreakerOpen = breakerOpenError{}
+)
+
+// Transport wraps an http.RoundTripper with a token-bucket rate limiter
+// and a circuit breaker driven by Config, tracking per-handler counters.
+type Transport struct {
+	Name string
+	Next http.RoundTripper
+	Cfg  CircuitBreakerConfig
+
+	bucket  *tokenBucket
+	breaker *breaker
+
+	counters Counters
+}
+
+// NewTransport builds a Transport named name (used to key its exported
+// counters) wrapping next according to cfg.
+func NewTransport(name string, next http.RoundTripper, cfg Config) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Transport{
+		Name:    name,
+		Next:    next,
+		Cfg:     cfg.CircuitBreaker,
+		bucket:  newTokenBucket(cfg.RateLimit, cfg.RateBurst),
+		breaker: newBreaker(cfg.CircuitBreaker),
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.bucket.Allow() {
+		atomic.AddInt64(&t.counters.DroppedRateLimit, 1)
+		t.publish()
+		return nil, ErrRateLimited
+	}
+
+	if !t.breaker.Allow() {
+		atomic.AddInt64(&t.counters.DroppedBreaker, 1)
+		t.publish()
+		return nil, ErrBreakerOpen
+	}
+
+	resp, err := t.Next.RoundTrip(req)
+	if err != nil {
+		t.breaker.RecordResult(false)
+		t.publish()
+		return nil, err
+	}
+
+	if t.Cfg.tripsOn(resp.StatusCode) {
+		t.breaker.RecordResult(false)
+	} else {
+		t.breaker.RecordResult(true)
+		atomic.AddInt64(&t.counters.Sent, 1)
+	}
+	t.publish()
+	return resp, nil
+}
+
+// Counters returns a snapshot of t's current counters.
+func (t *Transport) Counters() Counters {
+	return Counters{
+		Sent:             atomic.LoadInt64(&t.counters.Sent),
+		DroppedRateLimit: atomic.LoadInt64(&t.counters.DroppedRateLimit),
+		DroppedBreaker:   atomic.LoadInt64(&t.counters.DroppedBreaker),
+	}
+}
+
+// BreakerState reports the breaker's current state.
+func (t *Transport) BreakerState() BreakerState {
+	return t.breaker.State()
+}
+
+func (t *Transport) publish() {
+	c := t.Counters()
+	counterVars.Set(t.Name+".sent", expvarInt(c.Sent))
+	counterVars.Set(t.Name+".dropped_ratelimit", expvarInt(c.DroppedRateLimit))
+	counterVars.Set(t.Name+".dropped_breaker", expvarInt(c.DroppedBreaker))
+	counterVars.Set(t.Name+".breaker_state", expvarString(t.BreakerState()))
+}
+
+type expvarInt int64
+
+func (v expvarInt) String() string { return fmt.Sprintf("%d", int64(v)) }
+
+type expvarString string
+
+func (v expvarString) String() string { return fmt.Sprintf("%q", string(v)) }
@@ -0,0 +1,89 @@
+// Package alerttemplate provides a single text/template pass, with a
+// safe allow-list of functions, for rendering a handler's per-message
+// string fields (e.g. a routing channel) against the triggering alert's
+// data. It lets one handler definition fan out across tags instead of
+// requiring one handler per tag value.
+package alerttemplate
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/influxdata/kapacitor/alert"
+)
+
+// Data is the view of an alert event exposed to a handler's templates.
+type Data struct {
+	ID      string
+	Message string
+	Level   string
+	Time    time.Time
+	Tags    map[string]string
+}
+
+// NewData builds a Data from event, computing ID the same way
+// services/pubsub does: a short hash of the message and tags, stable
+// across re-triggers of the same alert.
+func NewData(event alert.Event) Data {
+	var tags map[string]string
+	if event.Data.Result.Series != nil && len(event.Data.Result.Series) > 0 {
+		tags = event.Data.Result.Series[0].Tags
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%v", event.State.Message, tags)))
+	return Data{
+		ID:      hex.EncodeToString(sum[:8]),
+		Message: event.State.Message,
+		Level:   string(event.State.Level),
+		Time:    time.Now(),
+		Tags:    tags,
+	}
+}
+
+// Funcs is the allow-listed set of functions available to a handler
+// template, deliberately small since these templates run against
+// operator-supplied config, not untrusted input: printf, default, lower,
+// upper, and replace. index is available too, as one of text/template's
+// own builtin functions.
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"printf": fmt.Sprintf,
+		"default": func(fallback, value string) string {
+			if value == "" {
+				return fallback
+			}
+			return value
+		},
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+		"replace": func(old, new, s string) string {
+			return strings.Replace(s, old, new, -1)
+		},
+	}
+}
+
+// Parse compiles text with the allow-listed function set. An empty text
+// parses to a nil *template.Template; Render treats that as a no-op.
+func Parse(name, text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	return template.New(name).Funcs(Funcs()).Parse(text)
+}
+
+// Render executes tmpl against data and returns the result, or fallback
+// unchanged if tmpl is nil.
+func Render(tmpl *template.Template, fallback string, data Data) (string, error) {
+	if tmpl == nil {
+		return fallback, nil
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
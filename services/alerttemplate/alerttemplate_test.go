@@ -0,0 +1,55 @@
+package alerttemplate
+
+import "testing"
+
+func TestRender_SubstitutesTagIntoTemplate(t *testing.T) {
+	tmpl, err := Parse("channel", `#alerts-{{ index .Tags "env" }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := Data{Tags: map[string]string{"env": "prod"}}
+	got, err := Render(tmpl, "#alerts", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := "#alerts-prod"; got != exp {
+		t.Errorf("unexpected render got %s exp %s", got, exp)
+	}
+}
+
+func TestRender_NilTemplateReturnsFallback(t *testing.T) {
+	got, err := Render(nil, "#alerts", Data{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := "#alerts"; got != exp {
+		t.Errorf("unexpected render got %s exp %s", got, exp)
+	}
+}
+
+func TestRender_AllowListedFuncs(t *testing.T) {
+	tmpl, err := Parse("channel", `{{ .Tags.team | default "ops" | lower }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := Data{Tags: map[string]string{"team": "DB"}}
+	got, err := Render(tmpl, "", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := "db"; got != exp {
+		t.Errorf("unexpected render got %s exp %s", got, exp)
+	}
+}
+
+func TestParse_EmptyTextReturnsNilTemplate(t *testing.T) {
+	tmpl, err := Parse("channel", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tmpl != nil {
+		t.Errorf("expected nil template for empty text, got %v", tmpl)
+	}
+}
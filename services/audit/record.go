@@ -0,0 +1,38 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// Record is a single tamper-evident audit log entry. Hash covers every
+// other field plus the previous record's hash, forming a hash chain: any
+// edit, reorder, or deletion of a prior record invalidates every hash after
+// it.
+type Record struct {
+	Timestamp  time.Time `json:"ts"`
+	Principal  string    `json:"principal"`
+	Action     string    `json:"action"`
+	Resource   string    `json:"resource"`
+	ResourceID string    `json:"resource_id"`
+	PrevHash   string    `json:"prev_hash"`
+	Hash       string    `json:"hash"`
+}
+
+// canonicalJSON marshals the record without its own Hash field, so the hash
+// it computes cannot include itself.
+func (r Record) canonicalJSON() ([]byte, error) {
+	r.Hash = ""
+	return json.Marshal(r)
+}
+
+func (r Record) computeHash() (string, error) {
+	data, err := r.canonicalJSON()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(append([]byte(r.PrevHash), data...))
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,76 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// lastHashInFile returns the Hash of the last record in an existing audit
+// log, or "" if the file doesn't exist yet or is empty, so a fresh Log
+// continues the existing chain instead of restarting it.
+func lastHashInFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var last string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return "", fmt.Errorf("corrupt audit log: %s", err)
+		}
+		last = r.Hash
+	}
+	return last, scanner.Err()
+}
+
+// Verify walks every record in the audit log at path, recomputing each
+// hash and confirming it chains from the previous record's hash. It
+// returns the index of the first record at which the chain breaks (due to
+// tampering, reordering, or a gap left by a deleted record), or -1 if the
+// entire chain is intact.
+func Verify(path string) (brokenAt int, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return -1, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+
+	prevHash := ""
+	i := 0
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return i, fmt.Errorf("record %d: %s", i, err)
+		}
+		if r.PrevHash != prevHash {
+			return i, nil
+		}
+		expected, err := r.computeHash()
+		if err != nil {
+			return i, err
+		}
+		if expected != r.Hash {
+			return i, nil
+		}
+		prevHash = r.Hash
+		i++
+	}
+	if err := scanner.Err(); err != nil {
+		return i, err
+	}
+
+	return -1, nil
+}
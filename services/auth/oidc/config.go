@@ -0,0 +1,35 @@
+package oidc
+
+// Config configures an OIDC provider used for interactive user
+// authentication in place of (or alongside) static user credentials.
+type Config struct {
+	Enabled bool `toml:"enabled" override:"enabled"`
+
+	// IssuerURL is the provider's issuer, used to discover the
+	// authorization, token and JWKS endpoints via
+	// "{issuer}/.well-known/openid-configuration".
+	IssuerURL string `toml:"issuer-url" override:"issuer-url"`
+
+	ClientID     string `toml:"client-id" override:"client-id"`
+	ClientSecret string `toml:"client-secret" override:"client-secret,redact"`
+	RedirectURL  string `toml:"redirect-url" override:"redirect-url"`
+
+	// Scopes requested in the authorization code flow, "openid" is always
+	// implied.
+	Scopes []string `toml:"scopes" override:"scopes"`
+
+	// RoleMapping maps an OIDC group/role claim value to the set of
+	// Kapacitor permissions granted to principals carrying it.
+	RoleMapping map[string][]string `toml:"role-mapping" override:"role-mapping"`
+
+	// RoleClaim is the name of the ID token claim holding the list of
+	// groups/roles for a user, e.g. "groups" or "roles".
+	RoleClaim string `toml:"role-claim" override:"role-claim"`
+}
+
+func NewConfig() Config {
+	return Config{
+		Scopes:    []string{"openid", "profile", "email"},
+		RoleClaim: "groups",
+	}
+}
@@ -0,0 +1,173 @@
+// Package oidc implements user authentication against an external OIDC
+// provider (Google, Okta, Keycloak, ...) using the authorization code flow,
+// and maps the provider's group/role claims onto Kapacitor permissions.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync/atomic"
+
+	"github.com/coreos/go-oidc"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+// Principal identifies an authenticated user and the permissions derived
+// from their OIDC role claims, for use by downstream authorization checks.
+type Principal struct {
+	Subject     string
+	Email       string
+	Roles       []string
+	Permissions map[string]bool
+}
+
+func (p *Principal) HasPermission(perm string) bool {
+	return p.Permissions[perm]
+}
+
+type Service struct {
+	configValue atomic.Value
+	logger      *log.Logger
+
+	provider *oidc.Provider
+	verifier *oidc.IDTokenVerifier
+	oauth2   *oauth2.Config
+}
+
+func NewService(c Config, l *log.Logger) *Service {
+	s := &Service{logger: l}
+	s.configValue.Store(c)
+	return s
+}
+
+func (s *Service) config() Config {
+	return s.configValue.Load().(Config)
+}
+
+func (s *Service) Open() error {
+	c := s.config()
+	if !c.Enabled {
+		return nil
+	}
+
+	provider, err := oidc.NewProvider(context.Background(), c.IssuerURL)
+	if err != nil {
+		return errors.Wrap(err, "discovering OIDC provider")
+	}
+	s.provider = provider
+	s.verifier = provider.Verifier(&oidc.Config{ClientID: c.ClientID})
+	scopes := c.Scopes
+	hasOpenID := false
+	for _, sc := range scopes {
+		if sc == oidc.ScopeOpenID {
+			hasOpenID = true
+		}
+	}
+	if !hasOpenID {
+		scopes = append([]string{oidc.ScopeOpenID}, scopes...)
+	}
+	s.oauth2 = &oauth2.Config{
+		ClientID:     c.ClientID,
+		ClientSecret: c.ClientSecret,
+		RedirectURL:  c.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       scopes,
+	}
+	return nil
+}
+
+func (s *Service) Close() error {
+	return nil
+}
+
+func (s *Service) Update(newConfig []interface{}) error {
+	if l := len(newConfig); l != 1 {
+		return errors.Errorf("expected only one new config object, got %d", l)
+	}
+	c, ok := newConfig[0].(Config)
+	if !ok {
+		return errors.Errorf("expected config object to be of type %T, got %T", c, newConfig[0])
+	}
+	s.configValue.Store(c)
+	return s.Open()
+}
+
+// AuthCodeURL returns the URL to redirect a user to in order to begin the
+// authorization code flow, embedding state for CSRF protection.
+func (s *Service) AuthCodeURL(state string) string {
+	return s.oauth2.AuthCodeURL(state)
+}
+
+// Exchange completes the authorization code flow, verifies the returned ID
+// token, and builds a Principal from its claims mapped through RoleMapping.
+func (s *Service) Exchange(ctx context.Context, code string) (*Principal, *oauth2.Token, error) {
+	token, err := s.oauth2.Exchange(ctx, code)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "exchanging authorization code")
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, nil, errors.New("token response did not include an id_token")
+	}
+
+	idToken, err := s.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "verifying id_token")
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, nil, errors.Wrap(err, "decoding id_token claims")
+	}
+
+	c := s.config()
+	p := &Principal{
+		Subject:     idToken.Subject,
+		Permissions: make(map[string]bool),
+	}
+	if email, ok := claims["email"].(string); ok {
+		p.Email = email
+	}
+
+	for _, role := range rolesFromClaims(claims, c.RoleClaim) {
+		p.Roles = append(p.Roles, role)
+		for _, perm := range c.RoleMapping[role] {
+			p.Permissions[perm] = true
+		}
+	}
+
+	return p, token, nil
+}
+
+// rolesFromClaims extracts a string-slice claim, tolerating providers that
+// encode a single role as a bare string rather than a one-element array.
+func rolesFromClaims(claims map[string]interface{}, claim string) []string {
+	raw, ok := claims[claim]
+	if !ok {
+		return nil
+	}
+
+	switch v := raw.(type) {
+	case []interface{}:
+		roles := make([]string, 0, len(v))
+		for _, r := range v {
+			if s, ok := r.(string); ok {
+				roles = append(roles, s)
+			}
+		}
+		return roles
+	case string:
+		return []string{v}
+	default:
+		// Any other shape is re-encoded and decoded as a string array; a
+		// value that does not decode as one yields no roles.
+		var roles []string
+		if b, err := json.Marshal(v); err == nil {
+			json.Unmarshal(b, &roles)
+		}
+		return roles
+	}
+}
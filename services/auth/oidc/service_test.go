@@ -0,0 +1,55 @@
+package oidc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRolesFromClaims(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims map[string]interface{}
+		claim  string
+		exp    []string
+	}{
+		{
+			name:   "array of strings",
+			claims: map[string]interface{}{"groups": []interface{}{"admin", "operator"}},
+			claim:  "groups",
+			exp:    []string{"admin", "operator"},
+		},
+		{
+			name:   "bare string",
+			claims: map[string]interface{}{"groups": "admin"},
+			claim:  "groups",
+			exp:    []string{"admin"},
+		},
+		{
+			name:   "missing claim",
+			claims: map[string]interface{}{},
+			claim:  "groups",
+			exp:    nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := rolesFromClaims(tc.claims, tc.claim)
+			if !reflect.DeepEqual(got, tc.exp) {
+				t.Errorf("unexpected roles got %v exp %v", got, tc.exp)
+			}
+		})
+	}
+}
+
+func TestPrincipal_HasPermission(t *testing.T) {
+	p := &Principal{
+		Permissions: map[string]bool{"task:read": true},
+	}
+	if !p.HasPermission("task:read") {
+		t.Error("expected task:read permission")
+	}
+	if p.HasPermission("task:delete") {
+		t.Error("did not expect task:delete permission")
+	}
+}
@@ -0,0 +1,75 @@
+package authz
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Action is an operation a policy grants or withholds.
+type Action string
+
+const (
+	ActionCreate  Action = "create"
+	ActionRead    Action = "read"
+	ActionUpdate  Action = "update"
+	ActionDelete  Action = "delete"
+	ActionEnable  Action = "enable"
+	ActionDisable Action = "disable"
+	ActionExecute Action = "execute"
+)
+
+// Resource is the kind of object an Action applies to.
+type Resource string
+
+const (
+	ResourceTask     Resource = "task"
+	ResourceTemplate Resource = "template"
+	ResourceTopic    Resource = "topic"
+	ResourceHandler  Resource = "handler"
+)
+
+// Policy grants Subject permission to perform Action on any ResourceID of
+// Resource matching ResourceIDPattern: an exact id, "*", or a prefix
+// followed by a single trailing "*" (e.g. "prod-*").
+type Policy struct {
+	ID                string   `json:"id"`
+	Subject           string   `json:"subject"`
+	Action            Action   `json:"action"`
+	Resource          Resource `json:"resource"`
+	ResourceIDPattern string   `json:"resourceIdPattern"`
+}
+
+// Validate rejects policies that would grant broader access than was
+// explicitly asked for. In particular ResourceIDPattern must be set: its
+// zero value is also "match everything" in globMatch, so an omitted
+// pattern (an easy mistake in a POST body) must be rejected rather than
+// silently becoming a grant on every resource of Resource's kind. An
+// explicit "*" still means "all", it just has to be written down.
+func (p Policy) Validate() error {
+	if p.ResourceIDPattern == "" {
+		return errors.New("authz: resourceIdPattern is required; use \"*\" to match every resource")
+	}
+	return nil
+}
+
+// Matches reports whether the policy grants subject permission to perform
+// action on resource/resourceID.
+func (p Policy) Matches(subject string, action Action, resource Resource, resourceID string) bool {
+	if p.Subject != subject || p.Action != action || p.Resource != resource {
+		return false
+	}
+	return globMatch(p.ResourceIDPattern, resourceID)
+}
+
+// globMatch supports a single trailing "*" wildcard, which covers the
+// common "prefix-*" id patterns without pulling in a full glob library.
+func globMatch(pattern, s string) bool {
+	if pattern == "" || pattern == "*" {
+		return true
+	}
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(s, strings.TrimSuffix(pattern, "*"))
+	}
+	return pattern == s
+}
@@ -0,0 +1,101 @@
+package authz
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+func newTestService(t *testing.T, c Config) (*Service, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "authz-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := bolt.Open(filepath.Join(dir, "authz.db"), 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	s, err := NewService(c, db)
+	if err != nil {
+		db.Close()
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	return s, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestService_Check_DeniesWithoutPolicy(t *testing.T) {
+	s, cleanup := newTestService(t, Config{Enabled: true})
+	defer cleanup()
+
+	if err := s.Check("bob", ActionDelete, ResourceTask, "testTaskID"); err == nil {
+		t.Fatal("expected check to be denied without a matching policy")
+	}
+}
+
+func TestService_Check_AllowsWithPolicy(t *testing.T) {
+	s, cleanup := newTestService(t, Config{Enabled: true})
+	defer cleanup()
+
+	if err := s.store.Create(Policy{
+		ID:                "p1",
+		Subject:           "bob",
+		Action:            ActionDelete,
+		Resource:          ResourceTask,
+		ResourceIDPattern: "testTaskID",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Check("bob", ActionDelete, ResourceTask, "testTaskID"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestService_Check_BootstrapAdmin(t *testing.T) {
+	s, cleanup := newTestService(t, Config{Enabled: true, BootstrapAdminSubject: "admin"})
+	defer cleanup()
+
+	if err := s.Check("admin", ActionDelete, ResourceTask, "anything"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestService_Store_Create_RejectsEmptyResourceIDPattern(t *testing.T) {
+	s, cleanup := newTestService(t, Config{Enabled: true})
+	defer cleanup()
+
+	err := s.store.Create(Policy{
+		ID:       "p1",
+		Subject:  "bob",
+		Action:   ActionDelete,
+		Resource: ResourceTask,
+	})
+	if err == nil {
+		t.Fatal("expected an empty resourceIdPattern to be rejected rather than granting access to every resource")
+	}
+
+	if err := s.Check("bob", ActionDelete, ResourceTask, "anyTaskID"); err == nil {
+		t.Fatal("expected the rejected policy to not have been stored")
+	}
+}
+
+func TestService_Check_DisabledAllowsEverything(t *testing.T) {
+	s, cleanup := newTestService(t, Config{Enabled: false})
+	defer cleanup()
+
+	if err := s.Check("anyone", ActionDelete, ResourceTask, "anything"); err != nil {
+		t.Fatal(err)
+	}
+}
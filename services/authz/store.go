@@ -0,0 +1,59 @@
+package authz
+
+import (
+	"encoding/json"
+
+	"github.com/boltdb/bolt"
+	"github.com/pkg/errors"
+)
+
+var policyBucket = []byte("authz_policies")
+
+// Store persists policies in BoltDB, under the "authz_policies" bucket.
+type Store struct {
+	db *bolt.DB
+}
+
+func NewStore(db *bolt.DB) (*Store, error) {
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(policyBucket)
+		return err
+	}); err != nil {
+		return nil, errors.Wrap(err, "creating authz bucket")
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Create(p Policy) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		data, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(policyBucket).Put([]byte(p.ID), data)
+	})
+}
+
+func (s *Store) Delete(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(policyBucket).Delete([]byte(id))
+	})
+}
+
+func (s *Store) List() ([]Policy, error) {
+	var policies []Policy
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(policyBucket).ForEach(func(k, v []byte) error {
+			var p Policy
+			if err := json.Unmarshal(v, &p); err != nil {
+				return err
+			}
+			policies = append(policies, p)
+			return nil
+		})
+	})
+	return policies, err
+}
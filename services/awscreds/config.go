@@ -0,0 +1,54 @@
+// Package awscreds resolves AWS credentials for AWS-backed handlers (sns,
+// cloudwatch, the S3 recording sink) and the influxdb section, supporting
+// both static access keys and IRSA-style AssumeRoleWithWebIdentity
+// federation so a pod's projected service account token can stand in for
+// a long-lived access key.
+package awscreds
+
+import (
+	"errors"
+	"os"
+)
+
+// Config is the set of AWS credential options added to a handler or
+// influxdb config section, alongside that section's existing fields.
+type Config struct {
+	AccessKey string `toml:"aws-access-key" override:"aws-access-key"`
+	SecretKey string `toml:"aws-secret-key" override:"aws-secret-key,redact"`
+
+	RoleARN              string `toml:"aws-role-arn" override:"aws-role-arn"`
+	WebIdentityTokenFile string `toml:"aws-web-identity-token-file" override:"aws-web-identity-token-file"`
+	RoleSessionName      string `toml:"aws-role-session-name" override:"aws-role-session-name"`
+	Region               string `toml:"aws-region" override:"aws-region"`
+	STSEndpoint          string `toml:"aws-sts-endpoint" override:"aws-sts-endpoint"`
+}
+
+// NewConfig returns a Config with WebIdentityTokenFile defaulted from
+// AWS_WEB_IDENTITY_TOKEN_FILE, matching the convention EKS IRSA uses to
+// inject it into a pod's environment.
+func NewConfig() Config {
+	return Config{
+		WebIdentityTokenFile: os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"),
+		RoleSessionName:      "kapacitor",
+	}
+}
+
+// UsesWebIdentity reports whether c is configured for
+// AssumeRoleWithWebIdentity rather than static keys.
+func (c Config) UsesWebIdentity() bool {
+	return c.RoleARN != ""
+}
+
+// Validate checks that c does not configure both static keys and a role
+// ARN at the same time, since only one credential source can win and
+// silently preferring one over the other would surprise an operator.
+func (c Config) Validate() error {
+	hasStatic := c.AccessKey != "" || c.SecretKey != ""
+	if hasStatic && c.RoleARN != "" {
+		return errors.New("awscreds: aws-access-key/aws-secret-key and aws-role-arn are mutually exclusive")
+	}
+	if c.RoleARN != "" && c.WebIdentityTokenFile == "" {
+		return errors.New("awscreds: aws-role-arn requires aws-web-identity-token-file")
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package awscreds
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// refreshSkew is how long before Expiration a cached credential is
+// considered stale, so a request never races the credential's actual
+// expiry against STS.
+const refreshSkew = 5 * time.Minute
+
+// sts is the subset of stsClient this package depends on, so tests can
+// substitute a fake STS backend.
+type sts interface {
+	assumeRoleWithWebIdentity(cfg Config, token string) (*Credentials, error)
+}
+
+// Provider resolves a Config to a set of AWS credentials, transparently
+// exchanging a projected web identity token for temporary STS
+// credentials and caching them until they are close to expiring or the
+// token file on disk has been rotated (kubelet refreshes a projected
+// service account token roughly hourly).
+type Provider struct {
+	Config Config
+	STS    sts
+	Now    func() time.Time
+
+	mu         sync.Mutex
+	cached     *Credentials
+	tokenMTime time.Time
+}
+
+// NewProvider builds a Provider for cfg. If cfg does not configure a
+// role ARN, Get returns cfg's static keys unchanged.
+func NewProvider(cfg Config) *Provider {
+	return &Provider{
+		Config: cfg,
+		STS:    newSTSClient(),
+		Now:    time.Now,
+	}
+}
+
+// Get returns the current credentials, refreshing them via STS if the
+// cached set is stale, unset, or the web identity token file has been
+// rewritten since the last refresh.
+func (p *Provider) Get() (*Credentials, error) {
+	if !p.Config.UsesWebIdentity() {
+		return &Credentials{
+			AccessKeyID:     p.Config.AccessKey,
+			SecretAccessKey: p.Config.SecretKey,
+		}, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	info, err := os.Stat(p.Config.WebIdentityTokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("awscreds: stat web identity token file: %w", err)
+	}
+
+	rotated := info.ModTime().After(p.tokenMTime)
+	stale := p.cached == nil || p.Now().Add(refreshSkew).After(p.cached.Expiration)
+	if !rotated && !stale {
+		return p.cached, nil
+	}
+
+	token, err := os.ReadFile(p.Config.WebIdentityTokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("awscreds: reading web identity token file: %w", err)
+	}
+
+	creds, err := p.STS.assumeRoleWithWebIdentity(p.Config, string(token))
+	if err != nil {
+		return nil, err
+	}
+
+	p.cached = creds
+	p.tokenMTime = info.ModTime()
+	return creds, nil
+}
+
+// Status returns the operator-facing, non-redacted status of the
+// currently cached credentials, surfaced under the config element's
+// Status map so rotation can be confirmed without exposing secrets.
+func (p *Provider) Status() map[string]interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status := map[string]interface{}{
+		"uses-web-identity": p.Config.UsesWebIdentity(),
+	}
+	if p.cached != nil && p.Config.UsesWebIdentity() {
+		status["expires-at"] = p.cached.Expiration.Format(time.RFC3339)
+	}
+	return status
+}
@@ -0,0 +1,193 @@
+package awscreds
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fakeSTS struct {
+	calls int
+	creds *Credentials
+	err   error
+}
+
+func (f *fakeSTS) assumeRoleWithWebIdentity(cfg Config, token string) (*Credentials, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.creds, nil
+}
+
+func writeTokenFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestProvider_StaticKeysPassThroughWithoutCallingSTS(t *testing.T) {
+	cfg := Config{AccessKey: "AKIA...", SecretKey: "shh"}
+	fake := &fakeSTS{}
+	p := &Provider{Config: cfg, STS: fake, Now: time.Now}
+
+	creds, err := p.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.AccessKeyID != "AKIA..." || creds.SecretAccessKey != "shh" {
+		t.Fatalf("unexpected credentials: %+v", creds)
+	}
+	if fake.calls != 0 {
+		t.Fatalf("expected STS not to be called for static keys, got %d calls", fake.calls)
+	}
+}
+
+func TestProvider_WebIdentityFetchesAndCaches(t *testing.T) {
+	tokenFile := writeTokenFile(t, "jwt-token")
+	now := time.Date(2026, 7, 26, 12, 0, 0, 0, time.UTC)
+	fake := &fakeSTS{creds: &Credentials{
+		AccessKeyID:     "ASIA...",
+		SecretAccessKey: "wrapped",
+		SessionToken:    "session",
+		Expiration:      now.Add(time.Hour),
+	}}
+	p := &Provider{
+		Config: Config{RoleARN: "arn:aws:iam::123:role/kapacitor", WebIdentityTokenFile: tokenFile},
+		STS:    fake,
+		Now:    func() time.Time { return now },
+	}
+
+	creds, err := p.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.AccessKeyID != "ASIA..." {
+		t.Fatalf("unexpected credentials: %+v", creds)
+	}
+
+	if _, err := p.Get(); err != nil {
+		t.Fatal(err)
+	}
+	if fake.calls != 1 {
+		t.Fatalf("expected the cached credentials to be reused, got %d STS calls", fake.calls)
+	}
+}
+
+func TestProvider_RefreshesBeforeExpirationSkew(t *testing.T) {
+	tokenFile := writeTokenFile(t, "jwt-token")
+	start := time.Date(2026, 7, 26, 12, 0, 0, 0, time.UTC)
+	now := start
+	fake := &fakeSTS{creds: &Credentials{
+		AccessKeyID: "ASIA-1",
+		Expiration:  start.Add(10 * time.Minute),
+	}}
+	p := &Provider{
+		Config: Config{RoleARN: "arn:aws:iam::123:role/kapacitor", WebIdentityTokenFile: tokenFile},
+		STS:    fake,
+		Now:    func() time.Time { return now },
+	}
+
+	if _, err := p.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Within 5 minutes of expiry: must refresh even though the token
+	// file hasn't changed.
+	now = start.Add(6 * time.Minute)
+	fake.creds = &Credentials{AccessKeyID: "ASIA-2", Expiration: now.Add(time.Hour)}
+
+	creds, err := p.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.AccessKeyID != "ASIA-2" {
+		t.Fatalf("expected a refreshed credential near expiry, got %+v", creds)
+	}
+	if fake.calls != 2 {
+		t.Fatalf("expected exactly one refresh call, got %d total calls", fake.calls)
+	}
+}
+
+func TestProvider_RefreshesOnTokenFileRotation(t *testing.T) {
+	tokenFile := writeTokenFile(t, "jwt-token-v1")
+	now := time.Date(2026, 7, 26, 12, 0, 0, 0, time.UTC)
+	fake := &fakeSTS{creds: &Credentials{AccessKeyID: "ASIA-1", Expiration: now.Add(time.Hour)}}
+	p := &Provider{
+		Config: Config{RoleARN: "arn:aws:iam::123:role/kapacitor", WebIdentityTokenFile: tokenFile},
+		STS:    fake,
+		Now:    func() time.Time { return now },
+	}
+
+	if _, err := p.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate kubelet rotating the projected token: new contents, new
+	// mtime, well before the cached credential's expiry.
+	time.Sleep(10 * time.Millisecond)
+	if err := os.WriteFile(tokenFile, []byte("jwt-token-v2"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	fake.creds = &Credentials{AccessKeyID: "ASIA-2", Expiration: now.Add(time.Hour)}
+
+	creds, err := p.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.AccessKeyID != "ASIA-2" {
+		t.Fatalf("expected rotation of the token file to trigger a fresh AssumeRole call, got %+v", creds)
+	}
+	if fake.calls != 2 {
+		t.Fatalf("expected exactly one refresh call after rotation, got %d total calls", fake.calls)
+	}
+}
+
+func TestProvider_StatusSurfacesExpiresAtWithoutSecrets(t *testing.T) {
+	tokenFile := writeTokenFile(t, "jwt-token")
+	expiry := time.Date(2026, 7, 26, 13, 0, 0, 0, time.UTC)
+	fake := &fakeSTS{creds: &Credentials{AccessKeyID: "ASIA-1", SecretAccessKey: "shouldnt-appear", Expiration: expiry}}
+	p := &Provider{
+		Config: Config{RoleARN: "arn:aws:iam::123:role/kapacitor", WebIdentityTokenFile: tokenFile},
+		STS:    fake,
+		Now:    func() time.Time { return expiry.Add(-time.Hour) },
+	}
+	if _, err := p.Get(); err != nil {
+		t.Fatal(err)
+	}
+
+	status := p.Status()
+	if status["expires-at"] != expiry.Format(time.RFC3339) {
+		t.Fatalf("expected expires-at %s, got %v", expiry.Format(time.RFC3339), status["expires-at"])
+	}
+	for k := range status {
+		if k != "uses-web-identity" && k != "expires-at" {
+			t.Fatalf("unexpected status key %q leaking credential material", k)
+		}
+	}
+}
+
+func TestConfig_ValidateRejectsStaticKeysAndRoleARNTogether(t *testing.T) {
+	cfg := Config{AccessKey: "AKIA...", SecretKey: "shh", RoleARN: "arn:aws:iam::123:role/kapacitor", WebIdentityTokenFile: "/var/run/token"}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected an error when both static keys and a role ARN are set")
+	}
+}
+
+func TestConfig_ValidateRejectsRoleARNWithoutTokenFile(t *testing.T) {
+	cfg := Config{RoleARN: "arn:aws:iam::123:role/kapacitor"}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected an error when aws-role-arn is set without a token file")
+	}
+}
+
+func TestConfig_ValidateAcceptsStaticKeysAlone(t *testing.T) {
+	cfg := Config{AccessKey: "AKIA...", SecretKey: "shh"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected static keys alone to validate, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package awscreds
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultSTSEndpoint = "https://sts.amazonaws.com/"
+
+// Credentials is the temporary set of keys returned by
+// AssumeRoleWithWebIdentity.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Expiration      time.Time
+}
+
+// assumeRoleResponse mirrors the subset of the
+// AssumeRoleWithWebIdentityResponse XML body this package needs.
+type assumeRoleResponse struct {
+	XMLName xml.Name `xml:"AssumeRoleWithWebIdentityResponse"`
+	Result  struct {
+		Credentials struct {
+			AccessKeyID     string `xml:"AccessKeyId"`
+			SecretAccessKey string `xml:"SecretAccessKey"`
+			SessionToken    string `xml:"SessionToken"`
+			Expiration      string `xml:"Expiration"`
+		} `xml:"Credentials"`
+	} `xml:"AssumeRoleWithWebIdentityResult"`
+}
+
+// stsClient calls the AWS Security Token Service's
+// AssumeRoleWithWebIdentity action directly over the Query API, so this
+// package needs no AWS SDK dependency.
+type stsClient struct {
+	HTTPClient *http.Client
+}
+
+func newSTSClient() *stsClient {
+	return &stsClient{HTTPClient: http.DefaultClient}
+}
+
+func (c *stsClient) assumeRoleWithWebIdentity(cfg Config, token string) (*Credentials, error) {
+	endpoint := cfg.STSEndpoint
+	if endpoint == "" {
+		endpoint = defaultSTSEndpoint
+	}
+
+	form := url.Values{}
+	form.Set("Action", "AssumeRoleWithWebIdentity")
+	form.Set("Version", "2011-06-15")
+	form.Set("RoleArn", cfg.RoleARN)
+	form.Set("RoleSessionName", cfg.RoleSessionName)
+	form.Set("WebIdentityToken", token)
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("awscreds: calling AssumeRoleWithWebIdentity: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("awscreds: AssumeRoleWithWebIdentity returned %s", resp.Status)
+	}
+
+	var parsed assumeRoleResponse
+	if err := xml.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("awscreds: decoding AssumeRoleWithWebIdentity response: %w", err)
+	}
+
+	expiration, err := time.Parse(time.RFC3339, parsed.Result.Credentials.Expiration)
+	if err != nil {
+		return nil, fmt.Errorf("awscreds: parsing credential expiration: %w", err)
+	}
+
+	return &Credentials{
+		AccessKeyID:     parsed.Result.Credentials.AccessKeyID,
+		SecretAccessKey: parsed.Result.Credentials.SecretAccessKey,
+		SessionToken:    parsed.Result.Credentials.SessionToken,
+		Expiration:      expiration,
+	}, nil
+}
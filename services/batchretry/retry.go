@@ -0,0 +1,89 @@
+// Package batchretry retries a failed batch query with exponential
+// backoff and jitter instead of dropping the batch outright, so a task
+// survives a transient InfluxDB failure (or an operator mid-flight
+// swapping URLs) without waiting for its next scheduled fire.
+package batchretry
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Policy controls how a failed query is retried. The zero value disables
+// retries entirely, preserving the historical behavior of dropping the
+// batch on the first failure.
+type Policy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	Jitter         float64
+}
+
+// DefaultPolicy performs no retries, matching current behavior.
+func DefaultPolicy() Policy {
+	return Policy{MaxAttempts: 1}
+}
+
+// Stats reports how a single Do call went, for surfacing through the
+// task-stats endpoint.
+type Stats struct {
+	Attempts  int
+	LastError string
+}
+
+// Executor runs a query under a Policy, using Sleep to wait between
+// attempts so tests can substitute a no-op or recording sleep.
+type Executor struct {
+	Policy Policy
+	Sleep  func(time.Duration)
+}
+
+func NewExecutor(p Policy) *Executor {
+	return &Executor{Policy: p, Sleep: time.Sleep}
+}
+
+// Do calls query, retrying on error up to Policy.MaxAttempts times with
+// exponential backoff between attempts, bounded by MaxBackoff and
+// perturbed by up to +/-Jitter times the delay (Jitter is a fraction, so
+// 0.1 means 10%). It returns once query succeeds or
+// the attempt budget is exhausted; the final error, if any, is recorded in
+// the returned Stats rather than returned directly, since giving up means
+// moving on to the task's next scheduled fire rather than failing loudly.
+func (e *Executor) Do(query func() error) Stats {
+	policy := e.Policy
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 1
+	}
+
+	stats := Stats{}
+	backoff := policy.InitialBackoff
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		stats.Attempts = attempt
+		err := query()
+		if err == nil {
+			stats.LastError = ""
+			return stats
+		}
+		stats.LastError = err.Error()
+		if attempt == policy.MaxAttempts {
+			return stats
+		}
+
+		sleep := backoff
+		if policy.MaxBackoff > 0 && sleep > policy.MaxBackoff {
+			sleep = policy.MaxBackoff
+		}
+		if policy.Jitter > 0 {
+			sleep += time.Duration((rand.Float64()*2 - 1) * policy.Jitter * float64(sleep))
+		}
+		if sleep > 0 {
+			e.Sleep(sleep)
+		}
+
+		if policy.Multiplier > 0 {
+			backoff = time.Duration(float64(backoff) * policy.Multiplier)
+		}
+	}
+	return stats
+}
@@ -0,0 +1,89 @@
+package batchretry
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestExecutor_Do_NoRetryByDefault(t *testing.T) {
+	e := NewExecutor(DefaultPolicy())
+	e.Sleep = func(time.Duration) { t.Fatal("expected no sleep when retries are disabled") }
+
+	attempts := 0
+	stats := e.Do(func() error {
+		attempts++
+		return fmt.Errorf("boom")
+	})
+	if attempts != 1 {
+		t.Fatalf("expected exactly one attempt, got %d", attempts)
+	}
+	if stats.Attempts != 1 || stats.LastError == "" {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestExecutor_Do_RetriesUntilSuccess(t *testing.T) {
+	e := NewExecutor(Policy{
+		MaxAttempts:    5,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+		Multiplier:     2,
+	})
+	var slept []time.Duration
+	e.Sleep = func(d time.Duration) { slept = append(slept, d) }
+
+	attempts := 0
+	stats := e.Do(func() error {
+		attempts++
+		if attempts < 3 {
+			return fmt.Errorf("transient failure")
+		}
+		return nil
+	})
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+	if stats.LastError != "" {
+		t.Fatalf("expected success to clear LastError, got %q", stats.LastError)
+	}
+	if len(slept) != 2 {
+		t.Fatalf("expected 2 sleeps between 3 attempts, got %d", len(slept))
+	}
+}
+
+func TestExecutor_Do_GivesUpAtMaxAttempts(t *testing.T) {
+	e := NewExecutor(Policy{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+	e.Sleep = func(time.Duration) {}
+
+	attempts := 0
+	stats := e.Do(func() error {
+		attempts++
+		return fmt.Errorf("persistent failure")
+	})
+	if attempts != 3 {
+		t.Fatalf("expected exactly 3 attempts, got %d", attempts)
+	}
+	if stats.LastError == "" {
+		t.Fatal("expected last error to be recorded")
+	}
+}
+
+func TestExecutor_Do_BackoffIsCapped(t *testing.T) {
+	e := NewExecutor(Policy{
+		MaxAttempts:    4,
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     15 * time.Millisecond,
+		Multiplier:     10,
+	})
+	var slept []time.Duration
+	e.Sleep = func(d time.Duration) { slept = append(slept, d) }
+
+	e.Do(func() error { return fmt.Errorf("boom") })
+
+	for _, d := range slept {
+		if d > 15*time.Millisecond {
+			t.Fatalf("expected backoff to be capped at 15ms, got %s", d)
+		}
+	}
+}
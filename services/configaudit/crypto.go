@@ -0,0 +1,84 @@
+package configaudit
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// encPrefix marks a JSON string as ciphertext rather than a plaintext
+// value, so decryptValue can tell the two apart inside a patch.
+const encPrefix = "enc:"
+
+// encryptValue AES-GCM encrypts plaintext with key, returning it as a
+// JSON string (quoted) prefixed with encPrefix so it round-trips through
+// encoding/json like any other patch value.
+func encryptValue(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "configaudit: building cipher")
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+	token := encPrefix + base64.StdEncoding.EncodeToString(sealed)
+	return jsonString(token), nil
+}
+
+// decryptValue reverses encryptValue. value must be a JSON-encoded
+// string produced by it.
+func decryptValue(key, value []byte) ([]byte, error) {
+	token, err := jsonUnstring(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(token) < len(encPrefix) || token[:len(encPrefix)] != encPrefix {
+		return nil, errors.New("configaudit: value is not an encrypted patch field")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(token[len(encPrefix):])
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "configaudit: building cipher")
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("configaudit: ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+// jsonString and jsonUnstring add/strip the surrounding double quotes of
+// a JSON string without escaping or unescaping its contents, which is
+// enough for the prefixed base64 tokens encryptValue produces.
+func jsonString(s string) []byte {
+	b := make([]byte, 0, len(s)+2)
+	b = append(b, '"')
+	b = append(b, []byte(s)...)
+	b = append(b, '"')
+	return b
+}
+
+func jsonUnstring(b []byte) (string, error) {
+	if len(b) < 2 || b[0] != '"' || b[len(b)-1] != '"' {
+		return "", errors.New("configaudit: expected a JSON string")
+	}
+	return string(b[1 : len(b)-1]), nil
+}
@@ -0,0 +1,153 @@
+package configaudit
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// PatchOp is one field-level change within an Entry's Patch. It extends
+// plain RFC 6902 "replace" with OldValue, since Revert needs the prior
+// value to invert a change and a pure JSON Patch only carries the new
+// one.
+type PatchOp struct {
+	Op       string          `json:"op"`
+	Path     string          `json:"path"`
+	Value    json.RawMessage `json:"value,omitempty"`
+	OldValue json.RawMessage `json:"oldValue,omitempty"`
+}
+
+// Diff computes the field-level changes between before and after,
+// encrypting the value of any field named in redacted with encKey so the
+// secret never lands in the log in plaintext. It returns the marshaled
+// Patch and the list of redacted fields that were touched, ready to
+// assign directly to an Entry.
+func Diff(before, after map[string]interface{}, redacted map[string]bool, encKey []byte) (patch []byte, redactedFields []string, err error) {
+	fields := make(map[string]bool, len(before)+len(after))
+	for k := range before {
+		fields[k] = true
+	}
+	for k := range after {
+		fields[k] = true
+	}
+
+	names := make([]string, 0, len(fields))
+	for k := range fields {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var ops []PatchOp
+	for _, name := range names {
+		oldV, hadOld := before[name]
+		newV, hadNew := after[name]
+		if hadOld && hadNew && equalJSON(oldV, newV) {
+			continue
+		}
+
+		oldRaw, err := json.Marshal(oldV)
+		if err != nil {
+			return nil, nil, err
+		}
+		newRaw, err := json.Marshal(newV)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if redacted[name] {
+			if hadOld {
+				oldRaw, err = encryptValue(encKey, oldRaw)
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+			if hadNew {
+				newRaw, err = encryptValue(encKey, newRaw)
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+			redactedFields = append(redactedFields, name)
+		}
+
+		ops = append(ops, PatchOp{Op: "replace", Path: "/" + name, Value: newRaw, OldValue: oldRaw})
+	}
+
+	patch, err = json.Marshal(ops)
+	if err != nil {
+		return nil, nil, err
+	}
+	return patch, redactedFields, nil
+}
+
+// isEncrypted reports whether raw is a JSON string carrying an
+// encryptValue token, as opposed to a plain (possibly null) patch value.
+func isEncrypted(raw json.RawMessage) bool {
+	s, err := jsonUnstring(raw)
+	if err != nil {
+		return false
+	}
+	return len(s) >= len(encPrefix) && s[:len(encPrefix)] == encPrefix
+}
+
+func equalJSON(a, b interface{}) bool {
+	aRaw, errA := json.Marshal(a)
+	bRaw, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return string(aRaw) == string(bRaw)
+}
+
+// invertPatch swaps Value and OldValue in every op, so re-applying the
+// result restores the state the original patch changed away from.
+func invertPatch(patch []byte) ([]byte, error) {
+	var ops []PatchOp
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, errors.Wrap(err, "configaudit: decoding patch to invert")
+	}
+	for i := range ops {
+		ops[i].Value, ops[i].OldValue = ops[i].OldValue, ops[i].Value
+	}
+	return json.Marshal(ops)
+}
+
+// decryptPatch returns a copy of patch with every encrypted Value and
+// OldValue replaced by its plaintext, for Replay to hand real values to
+// its apply callback. Only fields named in redactedFields are assumed
+// to be encrypted.
+func decryptPatch(patch []byte, redactedFields []string, key []byte) ([]byte, error) {
+	if len(redactedFields) == 0 {
+		return patch, nil
+	}
+	redacted := make(map[string]bool, len(redactedFields))
+	for _, f := range redactedFields {
+		redacted["/"+f] = true
+	}
+
+	var ops []PatchOp
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, errors.Wrap(err, "configaudit: decoding patch to decrypt")
+	}
+	for i, op := range ops {
+		if !redacted[op.Path] {
+			continue
+		}
+		if isEncrypted(op.Value) {
+			plain, err := decryptValue(key, op.Value)
+			if err != nil {
+				return nil, err
+			}
+			ops[i].Value = plain
+		}
+		if isEncrypted(op.OldValue) {
+			plain, err := decryptValue(key, op.OldValue)
+			if err != nil {
+				return nil, err
+			}
+			ops[i].OldValue = plain
+		}
+	}
+	return json.Marshal(ops)
+}
@@ -0,0 +1,107 @@
+package configaudit
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+var testEncKey = []byte("0123456789abcdef0123456789abcdef")[:32]
+
+func TestDiff_OnlyChangedFieldsProduceOps(t *testing.T) {
+	before := map[string]interface{}{"channel": "#general", "enabled": false}
+	after := map[string]interface{}{"channel": "#general", "enabled": true}
+
+	patch, redacted, err := Diff(before, after, nil, testEncKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(redacted) != 0 {
+		t.Fatalf("expected no redacted fields, got %v", redacted)
+	}
+
+	var ops []PatchOp
+	mustUnmarshal(t, patch, &ops)
+	if len(ops) != 1 || ops[0].Path != "/enabled" {
+		t.Fatalf("expected a single op for /enabled, got %+v", ops)
+	}
+}
+
+func TestDiff_RedactedFieldsAreEncrypted(t *testing.T) {
+	before := map[string]interface{}{"url": "http://old.example.com/hook"}
+	after := map[string]interface{}{"url": "http://new.example.com/hook"}
+
+	patch, redacted, err := Diff(before, after, map[string]bool{"url": true}, testEncKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(redacted) != 1 || redacted[0] != "url" {
+		t.Fatalf("expected url to be reported redacted, got %v", redacted)
+	}
+
+	var ops []PatchOp
+	mustUnmarshal(t, patch, &ops)
+	if len(ops) != 1 {
+		t.Fatalf("expected one op, got %+v", ops)
+	}
+	if string(ops[0].Value) == `"http://new.example.com/hook"` {
+		t.Fatal("expected the redacted value to be encrypted, not stored as plaintext")
+	}
+
+	plain, err := decryptValue(testEncKey, ops[0].Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plain) != `"http://new.example.com/hook"` {
+		t.Fatalf("expected decryption to recover the plaintext, got %s", plain)
+	}
+}
+
+func TestInvertPatch_SwapsValueAndOldValue(t *testing.T) {
+	before := map[string]interface{}{"enabled": false}
+	after := map[string]interface{}{"enabled": true}
+	patch, _, err := Diff(before, after, nil, testEncKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inverted, err := invertPatch(patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ops []PatchOp
+	mustUnmarshal(t, inverted, &ops)
+	if string(ops[0].Value) != "false" || string(ops[0].OldValue) != "true" {
+		t.Fatalf("expected the inverted op to swap value/oldValue, got %+v", ops[0])
+	}
+}
+
+func TestDecryptPatch_LeavesUnredactedFieldsAlone(t *testing.T) {
+	before := map[string]interface{}{"channel": "#a", "url": "http://old"}
+	after := map[string]interface{}{"channel": "#b", "url": "http://new"}
+	patch, redactedFields, err := Diff(before, after, map[string]bool{"url": true}, testEncKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decrypted, err := decryptPatch(patch, redactedFields, testEncKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ops []PatchOp
+	mustUnmarshal(t, decrypted, &ops)
+	for _, op := range ops {
+		if op.Path == "/url" && string(op.Value) != `"http://new"` {
+			t.Fatalf("expected the decrypted url value, got %s", op.Value)
+		}
+		if op.Path == "/channel" && string(op.Value) != `"#b"` {
+			t.Fatalf("expected the untouched channel value, got %s", op.Value)
+		}
+	}
+}
+
+func mustUnmarshal(t *testing.T, data []byte, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,38 @@
+// Package configaudit records every mutation made through the config
+// update API, task/template CRUD, and similar actions as a tamper-evident
+// append-only log: each entry's MAC covers the previous entry's MAC plus
+// its own payload, so altering or removing an entry breaks the chain from
+// that point forward.
+package configaudit
+
+import "time"
+
+// Entry is a single audited mutation.
+type Entry struct {
+	Sequence uint64    `json:"sequence"`
+	Time     time.Time `json:"time"`
+	Actor    string    `json:"actor"`
+	Section  string    `json:"section"`
+	Element  string    `json:"element"`
+
+	// Patch is a JSON patch (RFC 6902) describing the before/after
+	// change, or nil for actions that aren't a config diff (e.g. task
+	// delete).
+	Patch []byte `json:"patch,omitempty"`
+
+	// RedactedFields lists the names of any redacted fields the patch
+	// touched, never their values. Within Patch itself, a redacted
+	// field's value and oldValue are AES-GCM encrypted at rest rather
+	// than omitted, so Replay can still reconstruct the exact change.
+	RedactedFields []string `json:"redactedFields,omitempty"`
+
+	// RevertOf is the Sequence of the entry this one undoes, or 0 if
+	// this entry is not a revert.
+	RevertOf uint64 `json:"revertOf,omitempty"`
+
+	// MAC is this entry's HMAC, computed over PrevMAC plus the entry's
+	// own payload (every field above). It is set by Log.Append and
+	// should not be set by callers.
+	MAC     []byte `json:"mac"`
+	PrevMAC []byte `json:"prevMac"`
+}
@@ -0,0 +1,65 @@
+package configaudit
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Forwarder streams batches of audit entries to an external sink
+// (syslog, a Kafka topic, an S3-compatible bucket) as NDJSON.
+type Forwarder interface {
+	Forward(batch []Entry) error
+}
+
+// BatchingForwarder buffers entries and flushes them to an underlying
+// Forwarder once BatchSize is reached, so a busy audit log doesn't make
+// one network call per mutation.
+type BatchingForwarder struct {
+	Forwarder Forwarder
+	BatchSize int
+
+	buf []Entry
+}
+
+// NewBatchingForwarder builds a BatchingForwarder that flushes to fwd
+// every batchSize entries.
+func NewBatchingForwarder(fwd Forwarder, batchSize int) *BatchingForwarder {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	return &BatchingForwarder{Forwarder: fwd, BatchSize: batchSize}
+}
+
+// Add buffers e, flushing automatically once BatchSize entries have
+// accumulated.
+func (b *BatchingForwarder) Add(e Entry) error {
+	b.buf = append(b.buf, e)
+	if len(b.buf) >= b.BatchSize {
+		return b.Flush()
+	}
+	return nil
+}
+
+// Flush forwards any buffered entries immediately, even if BatchSize
+// hasn't been reached.
+func (b *BatchingForwarder) Flush() error {
+	if len(b.buf) == 0 {
+		return nil
+	}
+	batch := b.buf
+	b.buf = nil
+	return b.Forwarder.Forward(batch)
+}
+
+// EncodeNDJSON renders entries as newline-delimited JSON, one entry per
+// line. This package has no built-in syslog, Kafka or S3 Forwarder.
+func EncodeNDJSON(entries []Entry) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,105 @@
+package configaudit
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Handler implements GET /kapacitor/v1/audit and
+// POST /kapacitor/v1/audit/{sequence}/revert.
+type Handler struct {
+	Log *Log
+	// ActorFrom extracts the acting subject from a revert request, e.g.
+	// from the same identity oidcauth attaches to the request context.
+	ActorFrom func(*http.Request) string
+}
+
+func NewHandler(l *Log) *Handler {
+	return &Handler{Log: l}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if sequence, ok := revertSequence(req.URL.Path); ok {
+		h.serveRevert(w, req, sequence)
+		return
+	}
+
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := req.URL.Query()
+	section := q.Get("section")
+
+	var since uint64
+	if s := q.Get("since"); s != "" {
+		v, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid since: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		since = v
+	}
+
+	limit := 100
+	if s := q.Get("limit"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			http.Error(w, "invalid limit: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		limit = v
+	}
+
+	entries, err := h.Log.List(since, section, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"entries": entries})
+}
+
+func (h *Handler) serveRevert(w http.ResponseWriter, req *http.Request, sequence uint64) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	actor := "unknown"
+	if h.ActorFrom != nil {
+		actor = h.ActorFrom(req)
+	}
+
+	entry, err := Revert(h.Log, sequence, actor)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entry)
+}
+
+// revertSequence extracts {sequence} from a path of the form
+// /kapacitor/v1/audit/{sequence}/revert.
+func revertSequence(path string) (uint64, bool) {
+	const suffix = "/revert"
+	if !strings.HasSuffix(path, suffix) {
+		return 0, false
+	}
+	trimmed := strings.TrimSuffix(path, suffix)
+	idx := strings.LastIndex(trimmed, "/")
+	if idx < 0 || idx == len(trimmed)-1 {
+		return 0, false
+	}
+	sequence, err := strconv.ParseUint(trimmed[idx+1:], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return sequence, true
+}
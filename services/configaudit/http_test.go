@@ -0,0 +1,79 @@
+package configaudit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func itoa(v uint64) string {
+	return strconv.FormatUint(v, 10)
+}
+
+func decodeJSON(t *testing.T, resp *http.Response, v interface{}) {
+	t.Helper()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHandler_RevertEndpoint(t *testing.T) {
+	db, cleanup := openTestDB(t)
+	defer cleanup()
+
+	l, err := NewLog(db, []byte("test-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch, _, err := Diff(map[string]interface{}{"enabled": false}, map[string]interface{}{"enabled": true}, nil, testEncKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original, err := l.Append(Entry{Actor: "alice", Section: "slack", Patch: patch})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := &Handler{Log: l, ActorFrom: func(r *http.Request) string { return "admin" }}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/kapacitor/v1/audit/"+itoa(original.Sequence)+"/revert", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var reverted Entry
+	decodeJSON(t, resp, &reverted)
+	if reverted.RevertOf != original.Sequence || reverted.Actor != "admin" {
+		t.Fatalf("unexpected revert entry: %+v", reverted)
+	}
+}
+
+func TestHandler_RevertUnknownSequenceIs400(t *testing.T) {
+	db, cleanup := openTestDB(t)
+	defer cleanup()
+	l, err := NewLog(db, []byte("test-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandler(l)
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/kapacitor/v1/audit/42/revert", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown sequence, got %d", resp.StatusCode)
+	}
+}
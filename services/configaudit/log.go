@@ -0,0 +1,227 @@
+package configaudit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/pkg/errors"
+)
+
+var entryBucket = []byte("configaudit_entries")
+
+// Log is an append-only, HMAC-chained audit log backed by BoltDB.
+type Log struct {
+	db  *bolt.DB
+	key []byte
+
+	mu      sync.Mutex
+	lastSeq uint64
+	lastMAC []byte
+}
+
+// NewLog opens (creating if necessary) the audit log bucket in db. key is
+// the server's HMAC signing key; it never leaves the process and is not
+// itself persisted.
+func NewLog(db *bolt.DB, key []byte) (*Log, error) {
+	l := &Log{db: db, key: key}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(entryBucket)
+		return err
+	}); err != nil {
+		return nil, errors.Wrap(err, "creating configaudit bucket")
+	}
+
+	last, err := l.last()
+	if err != nil {
+		return nil, err
+	}
+	if last != nil {
+		l.lastSeq = last.Sequence
+		l.lastMAC = last.MAC
+	}
+	return l, nil
+}
+
+func (l *Log) last() (*Entry, error) {
+	var e *Entry
+	err := l.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(entryBucket).Cursor()
+		k, v := c.Last()
+		if k == nil {
+			return nil
+		}
+		var entry Entry
+		if err := json.Unmarshal(v, &entry); err != nil {
+			return err
+		}
+		e = &entry
+		return nil
+	})
+	return e, err
+}
+
+// payload is the portion of an Entry that is covered by its MAC: every
+// field except the MAC itself.
+type payload struct {
+	Sequence       uint64    `json:"sequence"`
+	Time           time.Time `json:"time"`
+	Actor          string    `json:"actor"`
+	Section        string    `json:"section"`
+	Element        string    `json:"element"`
+	Patch          []byte    `json:"patch,omitempty"`
+	RedactedFields []string  `json:"redactedFields,omitempty"`
+	RevertOf       uint64    `json:"revertOf,omitempty"`
+	PrevMAC        []byte    `json:"prevMac"`
+}
+
+func (l *Log) computeMAC(e Entry) ([]byte, error) {
+	p := payload{
+		Sequence:       e.Sequence,
+		Time:           e.Time,
+		Actor:          e.Actor,
+		Section:        e.Section,
+		Element:        e.Element,
+		Patch:          e.Patch,
+		RedactedFields: e.RedactedFields,
+		RevertOf:       e.RevertOf,
+		PrevMAC:        e.PrevMAC,
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, l.key)
+	mac.Write(data)
+	return mac.Sum(nil), nil
+}
+
+// Append adds e to the log, assigning it the next sequence number and
+// chaining its MAC from the previous entry. The caller-supplied
+// Sequence, PrevMAC, and MAC fields, if any, are ignored and overwritten.
+func (l *Log) Append(e Entry) (Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e.Sequence = l.lastSeq + 1
+	e.PrevMAC = l.lastMAC
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	mac, err := l.computeMAC(e)
+	if err != nil {
+		return Entry{}, err
+	}
+	e.MAC = mac
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	if err := l.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(entryBucket).Put(sequenceKey(e.Sequence), data)
+	}); err != nil {
+		return Entry{}, errors.Wrap(err, "appending configaudit entry")
+	}
+
+	l.lastSeq = e.Sequence
+	l.lastMAC = e.MAC
+	return e, nil
+}
+
+// List returns up to limit entries with Sequence > since, optionally
+// filtered to a single section, in ascending sequence order. limit <= 0
+// means no limit.
+func (l *Log) List(since uint64, section string, limit int) ([]Entry, error) {
+	var entries []Entry
+	err := l.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(entryBucket).Cursor()
+		for k, v := c.Seek(sequenceKey(since + 1)); k != nil; k, v = c.Next() {
+			var e Entry
+			if err := json.Unmarshal(v, &e); err != nil {
+				return err
+			}
+			if section != "" && e.Section != section {
+				continue
+			}
+			entries = append(entries, e)
+			if limit > 0 && len(entries) >= limit {
+				break
+			}
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// Verify walks the entire chain from the beginning and recomputes each
+// entry's MAC, returning the sequence number of the first entry whose MAC
+// doesn't match (indicating tampering or corruption), or 0 if the chain
+// is intact.
+func (l *Log) Verify() (brokenAt uint64, err error) {
+	var prevMAC []byte
+	err = l.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(entryBucket).ForEach(func(k, v []byte) error {
+			var e Entry
+			if err := json.Unmarshal(v, &e); err != nil {
+				return err
+			}
+			if !hmac.Equal(e.PrevMAC, prevMAC) {
+				if brokenAt == 0 {
+					brokenAt = e.Sequence
+				}
+			}
+			want, err := l.computeMAC(Entry{
+				Sequence:       e.Sequence,
+				Time:           e.Time,
+				Actor:          e.Actor,
+				Section:        e.Section,
+				Element:        e.Element,
+				Patch:          e.Patch,
+				RedactedFields: e.RedactedFields,
+				RevertOf:       e.RevertOf,
+				PrevMAC:        e.PrevMAC,
+			})
+			if err != nil {
+				return err
+			}
+			if brokenAt == 0 && !hmac.Equal(want, e.MAC) {
+				brokenAt = e.Sequence
+			}
+			prevMAC = e.MAC
+			return nil
+		})
+	})
+	return brokenAt, err
+}
+
+// Get returns the entry at sequence, or nil if there is none.
+func (l *Log) Get(sequence uint64) (*Entry, error) {
+	var e *Entry
+	err := l.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(entryBucket).Get(sequenceKey(sequence))
+		if v == nil {
+			return nil
+		}
+		var entry Entry
+		if err := json.Unmarshal(v, &entry); err != nil {
+			return err
+		}
+		e = &entry
+		return nil
+	})
+	return e, err
+}
+
+func sequenceKey(seq uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, seq)
+	return b
+}
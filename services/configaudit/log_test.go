@@ -0,0 +1,188 @@
+package configaudit
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func openTestDB(t *testing.T) (*bolt.DB, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "configaudit-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := bolt.Open(filepath.Join(dir, "audit.db"), 0600, nil)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestLog_AppendChainsMACs(t *testing.T) {
+	db, cleanup := openTestDB(t)
+	defer cleanup()
+
+	l, err := NewLog(db, []byte("test-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e1, err := l.Append(Entry{Actor: "alice", Section: "influxdb", Element: "default"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e2, err := l.Append(Entry{Actor: "bob", Section: "alerta", Element: "default"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e1.Sequence != 1 || e2.Sequence != 2 {
+		t.Fatalf("expected sequential sequence numbers, got %d, %d", e1.Sequence, e2.Sequence)
+	}
+	if string(e2.PrevMAC) != string(e1.MAC) {
+		t.Fatal("expected the second entry's PrevMAC to chain from the first entry's MAC")
+	}
+	if len(e1.MAC) == 0 || len(e2.MAC) == 0 {
+		t.Fatal("expected both entries to have a non-empty MAC")
+	}
+}
+
+func TestLog_VerifyDetectsTampering(t *testing.T) {
+	db, cleanup := openTestDB(t)
+	defer cleanup()
+
+	l, err := NewLog(db, []byte("test-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := l.Append(Entry{Actor: "alice", Section: "influxdb"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	brokenAt, err := l.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if brokenAt != 0 {
+		t.Fatalf("expected an untampered chain to verify clean, broke at %d", brokenAt)
+	}
+
+	// Tamper with the second entry directly in BoltDB.
+	if err := db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(entryBucket)
+		v := b.Get(sequenceKey(2))
+		var e Entry
+		if err := json.Unmarshal(v, &e); err != nil {
+			return err
+		}
+		e.Actor = "mallory"
+		data, _ := json.Marshal(e)
+		return b.Put(sequenceKey(2), data)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	brokenAt, err = l.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if brokenAt != 2 {
+		t.Fatalf("expected tampering to be detected at sequence 2, got %d", brokenAt)
+	}
+}
+
+func TestLog_ListFiltersBySectionAndSince(t *testing.T) {
+	db, cleanup := openTestDB(t)
+	defer cleanup()
+
+	l, err := NewLog(db, []byte("test-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Append(Entry{Section: "influxdb"})
+	l.Append(Entry{Section: "alerta"})
+	l.Append(Entry{Section: "influxdb"})
+
+	entries, err := l.List(0, "influxdb", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 influxdb entries, got %d", len(entries))
+	}
+
+	entries, err = l.List(1, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Sequence != 2 {
+		t.Fatalf("expected entries after sequence 1, got %+v", entries)
+	}
+}
+
+func TestLog_ReopenContinuesChain(t *testing.T) {
+	db, cleanup := openTestDB(t)
+	defer cleanup()
+
+	l, err := NewLog(db, []byte("test-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e1, _ := l.Append(Entry{Section: "influxdb"})
+
+	l2, err := NewLog(db, []byte("test-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e2, err := l2.Append(Entry{Section: "alerta"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e2.Sequence != 2 {
+		t.Fatalf("expected reopening the log to continue the sequence, got %d", e2.Sequence)
+	}
+	if string(e2.PrevMAC) != string(e1.MAC) {
+		t.Fatal("expected reopening the log to continue the MAC chain")
+	}
+}
+
+type fakeForwarder struct {
+	batches [][]Entry
+}
+
+func (f *fakeForwarder) Forward(batch []Entry) error {
+	f.batches = append(f.batches, batch)
+	return nil
+}
+
+func TestBatchingForwarder_FlushesAtBatchSize(t *testing.T) {
+	fwd := &fakeForwarder{}
+	b := NewBatchingForwarder(fwd, 2)
+
+	b.Add(Entry{Sequence: 1})
+	if len(fwd.batches) != 0 {
+		t.Fatal("expected no flush before reaching batch size")
+	}
+	b.Add(Entry{Sequence: 2})
+	if len(fwd.batches) != 1 || len(fwd.batches[0]) != 2 {
+		t.Fatalf("expected a flush of 2 entries once batch size was reached, got %+v", fwd.batches)
+	}
+
+	b.Add(Entry{Sequence: 3})
+	b.Flush()
+	if len(fwd.batches) != 2 || len(fwd.batches[1]) != 1 {
+		t.Fatalf("expected an explicit Flush to send the remaining entry, got %+v", fwd.batches)
+	}
+}
@@ -0,0 +1,53 @@
+package configaudit
+
+import "github.com/pkg/errors"
+
+// ApplyFunc applies one audited change to a (presumably fresh) target
+// server, in the same shape the original config update used: a section,
+// an optional element name, and the patch to apply.
+type ApplyFunc func(section, element string, patch []byte) error
+
+// Replay re-applies entries, in order, against apply — used to rebuild
+// a fresh server's config from the audit trail for disaster recovery.
+// Any redacted field in an entry's patch is decrypted with key before
+// apply sees it, since apply needs the real value to actually configure
+// the target.
+func Replay(entries []Entry, key []byte, apply ApplyFunc) error {
+	for _, e := range entries {
+		patch, err := decryptPatch(e.Patch, e.RedactedFields, key)
+		if err != nil {
+			return errors.Wrapf(err, "configaudit: decrypting entry %d for replay", e.Sequence)
+		}
+		if err := apply(e.Section, e.Element, patch); err != nil {
+			return errors.Wrapf(err, "configaudit: replaying entry %d", e.Sequence)
+		}
+	}
+	return nil
+}
+
+// Revert builds and appends a new entry that inverts the change made by
+// the entry at sequence, restoring the fields it touched to their prior
+// values. The new entry's RevertOf names the entry it undoes.
+func Revert(l *Log, sequence uint64, actor string) (Entry, error) {
+	original, err := l.Get(sequence)
+	if err != nil {
+		return Entry{}, err
+	}
+	if original == nil {
+		return Entry{}, errors.Errorf("configaudit: no entry with sequence %d", sequence)
+	}
+
+	inverted, err := invertPatch(original.Patch)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	return l.Append(Entry{
+		Actor:          actor,
+		Section:        original.Section,
+		Element:        original.Element,
+		Patch:          inverted,
+		RedactedFields: original.RedactedFields,
+		RevertOf:       original.Sequence,
+	})
+}
@@ -0,0 +1,61 @@
+// Package configdryrun previews a config update without persisting it:
+// it decodes the candidate config, runs the section's own Validate, and
+// optionally exercises the section's existing Test/TestOptions pair (the
+// same connectivity check every alert handler already implements for its
+// "send test alert" action) so an operator can confirm credentials work
+// before committing the change.
+package configdryrun
+
+import "encoding/json"
+
+// Validator is satisfied by every section's Config type.
+type Validator interface {
+	Validate() error
+}
+
+// Tester is satisfied by every alert handler Service, reusing the same
+// Test/TestOptions pair the handler already exposes for its "send a test
+// alert" action as this package's live connectivity check.
+type Tester interface {
+	TestOptions() interface{}
+	Test(options interface{}) error
+}
+
+// Outcome is the result of a dry run.
+type Outcome struct {
+	Valid               bool     `json:"valid"`
+	Errors              []string `json:"errors,omitempty"`
+	ConnectivityChecked bool     `json:"connectivityChecked"`
+	ConnectivityOK      bool     `json:"connectivityOk,omitempty"`
+	ConnectivityError   string   `json:"connectivityError,omitempty"`
+}
+
+// Run decodes body into cfg, validates it, and — when checkConnectivity
+// is true and tester is non-nil — runs tester's existing test-alert path
+// as a live connectivity check. cfg is left populated with the decoded
+// candidate regardless of outcome, so the caller can still render it
+// back to the operator.
+func Run(body []byte, cfg Validator, checkConnectivity bool, tester Tester) (Outcome, error) {
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, cfg); err != nil {
+			return Outcome{}, err
+		}
+	}
+
+	var o Outcome
+	if err := cfg.Validate(); err != nil {
+		o.Errors = append(o.Errors, err.Error())
+		return o, nil
+	}
+	o.Valid = true
+
+	if checkConnectivity && tester != nil {
+		o.ConnectivityChecked = true
+		if err := tester.Test(tester.TestOptions()); err != nil {
+			o.ConnectivityError = err.Error()
+		} else {
+			o.ConnectivityOK = true
+		}
+	}
+	return o, nil
+}
@@ -0,0 +1,142 @@
+package configdryrun
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type fakeConfig struct {
+	Enabled bool   `json:"enabled"`
+	URL     string `json:"url"`
+}
+
+func (c *fakeConfig) Validate() error {
+	if c.Enabled && c.URL == "" {
+		return errEmptyURL
+	}
+	return nil
+}
+
+var errEmptyURL = errString("url must not be empty when enabled")
+
+type errString string
+
+func (e errString) Error() string { return string(e) }
+
+type fakeTester struct {
+	err error
+}
+
+func (t *fakeTester) TestOptions() interface{} { return nil }
+func (t *fakeTester) Test(interface{}) error   { return t.err }
+
+func TestRun_InvalidConfigReportsErrorsWithoutTesting(t *testing.T) {
+	cfg := &fakeConfig{}
+	tester := &fakeTester{}
+	o, err := Run([]byte(`{"enabled":true}`), cfg, true, tester)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Valid {
+		t.Fatal("expected validation to fail")
+	}
+	if o.ConnectivityChecked {
+		t.Fatal("expected connectivity check to be skipped when validation fails")
+	}
+}
+
+func TestRun_ValidConfigRunsConnectivityCheck(t *testing.T) {
+	cfg := &fakeConfig{}
+	tester := &fakeTester{}
+	o, err := Run([]byte(`{"enabled":true,"url":"http://hook"}`), cfg, true, tester)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !o.Valid || !o.ConnectivityChecked || !o.ConnectivityOK {
+		t.Fatalf("expected a valid, connectivity-ok outcome, got %+v", o)
+	}
+}
+
+func TestRun_ConnectivityFailureIsReported(t *testing.T) {
+	cfg := &fakeConfig{}
+	tester := &fakeTester{err: errString("dial tcp: connection refused")}
+	o, err := Run([]byte(`{"enabled":true,"url":"http://hook"}`), cfg, true, tester)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !o.Valid || !o.ConnectivityChecked || o.ConnectivityOK {
+		t.Fatalf("expected connectivity to fail, got %+v", o)
+	}
+	if !strings.Contains(o.ConnectivityError, "connection refused") {
+		t.Fatalf("expected the connectivity error to be surfaced, got %q", o.ConnectivityError)
+	}
+}
+
+func TestRun_SkipsConnectivityWhenNotRequested(t *testing.T) {
+	cfg := &fakeConfig{}
+	tester := &fakeTester{err: errString("should not be called")}
+	o, err := Run([]byte(`{"enabled":true,"url":"http://hook"}`), cfg, false, tester)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !o.Valid || o.ConnectivityChecked {
+		t.Fatalf("expected connectivity to be skipped, got %+v", o)
+	}
+}
+
+func TestHandler_DryRunEndpoint(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("webhook", SectionEntry{
+		New:    func() Validator { return &fakeConfig{} },
+		Tester: &fakeTester{},
+	})
+
+	h := NewHandler(registry, "/kapacitor/v1/config/")
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/kapacitor/v1/config/webhook?dry_run=true&test=true", "application/json",
+		strings.NewReader(`{"enabled":true,"url":"http://hook"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandler_RequiresDryRunQueryParam(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("webhook", SectionEntry{New: func() Validator { return &fakeConfig{} }})
+
+	h := NewHandler(registry, "/kapacitor/v1/config/")
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/kapacitor/v1/config/webhook", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 without dry_run=true, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandler_UnknownSectionIs404(t *testing.T) {
+	h := NewHandler(NewRegistry(), "/kapacitor/v1/config/")
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/kapacitor/v1/config/missing?dry_run=true", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unregistered section, got %d", resp.StatusCode)
+	}
+}
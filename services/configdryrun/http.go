@@ -0,0 +1,58 @@
+package configdryrun
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// Handler serves POST /kapacitor/v1/config/<section>[/<name>]?dry_run=true,
+// previewing a config update against Registry without persisting it. The
+// element name, when present, is accepted for parity with the real update
+// path but doesn't affect the dry run itself, since validation and
+// connectivity only depend on the section's type.
+type Handler struct {
+	Registry *Registry
+	// BasePath is the URL prefix before the section name, e.g.
+	// "/kapacitor/v1/config/".
+	BasePath string
+}
+
+func NewHandler(registry *Registry, basePath string) *Handler {
+	return &Handler{Registry: registry, BasePath: basePath}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if req.URL.Query().Get("dry_run") != "true" {
+		http.Error(w, "dry run handler requires dry_run=true", http.StatusBadRequest)
+		return
+	}
+
+	trimmed := strings.Trim(strings.TrimPrefix(req.URL.Path, h.BasePath), "/")
+	if trimmed == "" {
+		http.Error(w, "missing config section", http.StatusBadRequest)
+		return
+	}
+	section := strings.SplitN(trimmed, "/", 2)[0]
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	checkConnectivity := req.URL.Query().Get("test") == "true"
+	outcome, err := h.Registry.Run(section, body, checkConnectivity)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(outcome)
+}
@@ -0,0 +1,52 @@
+package configdryrun
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SectionEntry describes how to dry-run an update for one config section.
+type SectionEntry struct {
+	// New returns a zero-value Config for the section, ready to be
+	// unmarshaled into and validated.
+	New func() Validator
+	// Tester is the section's live Service, reused for connectivity
+	// checks. Nil if the section has none (or isn't wired up yet).
+	Tester Tester
+}
+
+// Registry maps config section names to the entries needed to dry-run
+// them, mirroring the shape of services/multiconfig's Store.
+type Registry struct {
+	mu      sync.Mutex
+	entries map[string]SectionEntry
+}
+
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[string]SectionEntry)}
+}
+
+func (r *Registry) Register(section string, entry SectionEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[section] = entry
+}
+
+func (r *Registry) entry(section string) (SectionEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, ok := r.entries[section]
+	if !ok {
+		return SectionEntry{}, fmt.Errorf("unknown config section %q", section)
+	}
+	return entry, nil
+}
+
+// Run looks up section and dry-runs body against it.
+func (r *Registry) Run(section string, body []byte, checkConnectivity bool) (Outcome, error) {
+	entry, err := r.entry(section)
+	if err != nil {
+		return Outcome{}, err
+	}
+	return Run(body, entry.New(), checkConnectivity, entry.Tester)
+}
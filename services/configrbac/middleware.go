@@ -0,0 +1,114 @@
+package configrbac
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/influxdata/kapacitor/services/oidcauth"
+)
+
+// SubjectFunc extracts the calling subject from a request — a
+// username, "token:<id>", or an mTLS SAN — for Policy.Allow to check.
+type SubjectFunc func(r *http.Request) string
+
+// DefaultSubject resolves the subject from, in order, an mTLS client
+// certificate's first SAN, an OIDC identity already attached to the
+// request's context by oidcauth.Middleware, or "anonymous" if neither
+// is present.
+func DefaultSubject(r *http.Request) string {
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		cert := r.TLS.PeerCertificates[0]
+		if len(cert.DNSNames) > 0 {
+			return cert.DNSNames[0]
+		}
+		return cert.Subject.CommonName
+	}
+	if identity, ok := oidcauth.IdentityFromContext(r.Context()); ok {
+		return identity.Subject
+	}
+	return "anonymous"
+}
+
+// configUpdateAction mirrors just enough of client.ConfigUpdateAction's
+// shape to tell which write action a request body is performing.
+type configUpdateAction struct {
+	Set    json.RawMessage `json:"set"`
+	Add    json.RawMessage `json:"add"`
+	Remove json.RawMessage `json:"remove"`
+	Delete json.RawMessage `json:"delete"`
+}
+
+// actionForRequest determines the Action a config API request
+// represents: any GET is a read, any DELETE is a remove, and a POST's
+// action is whichever of set/add/remove/delete its body sets, each
+// consumed non-destructively so the next handler still sees the full
+// body.
+func actionForRequest(r *http.Request) (Action, error) {
+	switch r.Method {
+	case http.MethodGet:
+		return ActionRead, nil
+	case http.MethodDelete:
+		return ActionRemove, nil
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return "", err
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	var action configUpdateAction
+	if err := json.Unmarshal(body, &action); err != nil {
+		return "", err
+	}
+	switch {
+	case len(action.Add) > 0:
+		return ActionAdd, nil
+	case len(action.Remove) > 0:
+		return ActionRemove, nil
+	case len(action.Delete) > 0:
+		return ActionRemove, nil
+	default:
+		return ActionUpdate, nil
+	}
+}
+
+// sectionFromConfigPath extracts the section name from a
+// /kapacitor/v1/config/<section>[/<element>] path.
+func sectionFromConfigPath(path, basePath string) string {
+	trimmed := strings.TrimPrefix(path, basePath)
+	trimmed = strings.Trim(trimmed, "/")
+	if trimmed == "" {
+		return ""
+	}
+	parts := strings.SplitN(trimmed, "/", 2)
+	return parts[0]
+}
+
+// Middleware wraps next, rejecting with 403 any request Policy denies
+// for its subject, section, and action before next ever runs.
+func Middleware(policy *Policy, basePath string, subjectOf SubjectFunc, next http.Handler) http.Handler {
+	if subjectOf == nil {
+		subjectOf = DefaultSubject
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		section := sectionFromConfigPath(r.URL.Path, basePath)
+
+		action, err := actionForRequest(r)
+		if err != nil {
+			http.Error(w, "configrbac: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		subject := subjectOf(r)
+		if !policy.Allow(subject, section, action) {
+			http.Error(w, "forbidden: "+subject+" may not "+string(action)+" "+section, http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,81 @@
+// Package configrbac authorizes requests against the config API's
+// sections and actions, modeled after an intention-filter layer: a
+// policy document maps subjects (user, token, mTLS SAN) to the sections
+// and actions they may touch, with a Consul-style default_policy of
+// allow or deny for anything no rule matches.
+package configrbac
+
+import (
+	"io"
+
+	"github.com/influxdata/toml"
+	"github.com/pkg/errors"
+)
+
+// Action is one of the operations the config API exposes.
+type Action string
+
+const (
+	ActionRead          Action = "read"
+	ActionUpdate        Action = "update"
+	ActionAdd           Action = "add"
+	ActionRemove        Action = "remove"
+	ActionRevealSecrets Action = "reveal-secrets"
+)
+
+// wildcard matches any subject, section, or action in a Rule.
+const wildcard = "*"
+
+// Rule grants every subject in Subjects the Actions listed against every
+// section in Sections. Any field may contain "*" to match anything.
+type Rule struct {
+	Subjects []string `toml:"subjects"`
+	Sections []string `toml:"sections"`
+	Actions  []string `toml:"actions"`
+}
+
+func (r Rule) matches(subject, section string, action Action) bool {
+	return contains(r.Subjects, subject) && contains(r.Sections, section) && contains(r.Actions, string(action))
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == wildcard || value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Policy is the full RBAC document: a default verdict plus an ordered
+// list of rules, the first matching rule wins.
+type Policy struct {
+	// DefaultPolicy is "allow" or "deny", applied when no rule matches.
+	DefaultPolicy string `toml:"default_policy"`
+	Rules         []Rule `toml:"rule"`
+}
+
+// LoadPolicy decodes a policy document. The format is TOML, matching
+// every other config document kapacitor reads, with repeated [[rule]]
+// tables.
+func LoadPolicy(r io.Reader) (*Policy, error) {
+	var p Policy
+	if _, err := toml.DecodeReader(r, &p); err != nil {
+		return nil, errors.Wrap(err, "configrbac: decoding policy")
+	}
+	if p.DefaultPolicy != "allow" && p.DefaultPolicy != "deny" {
+		return nil, errors.Errorf("configrbac: default_policy must be \"allow\" or \"deny\", got %q", p.DefaultPolicy)
+	}
+	return &p, nil
+}
+
+// Allow reports whether subject may perform action against section,
+// per the first matching rule, falling back to DefaultPolicy.
+func (p *Policy) Allow(subject, section string, action Action) bool {
+	for _, rule := range p.Rules {
+		if rule.matches(subject, section, action) {
+			return true
+		}
+	}
+	return p.DefaultPolicy == "allow"
+}
@@ -0,0 +1,163 @@
+package configrbac
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLoadPolicy_RejectsUnknownDefaultPolicy(t *testing.T) {
+	_, err := LoadPolicy(strings.NewReader(`default_policy = "maybe"`))
+	if err == nil {
+		t.Fatal("expected an error for an invalid default_policy")
+	}
+}
+
+func TestPolicy_AllowFallsBackToDefaultPolicy(t *testing.T) {
+	doc := `
+default_policy = "deny"
+
+[[rule]]
+  subjects = ["operator"]
+  sections = ["slack"]
+  actions = ["read", "update"]
+`
+	p, err := LoadPolicy(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !p.Allow("operator", "slack", ActionRead) {
+		t.Error("expected operator to read slack")
+	}
+	if p.Allow("operator", "pagerduty", ActionRead) {
+		t.Error("expected operator to be denied on pagerduty, no matching rule and default is deny")
+	}
+	if p.Allow("operator", "slack", ActionAdd) {
+		t.Error("expected operator to be denied add, only read/update granted")
+	}
+}
+
+func TestPolicy_WildcardsMatchAnySubjectSectionOrAction(t *testing.T) {
+	doc := `
+default_policy = "deny"
+
+[[rule]]
+  subjects = ["*"]
+  sections = ["*"]
+  actions = ["read"]
+`
+	p, err := LoadPolicy(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Allow("anyone", "anything", ActionRead) {
+		t.Error("expected the wildcard rule to grant read to anyone on anything")
+	}
+	if p.Allow("anyone", "anything", ActionUpdate) {
+		t.Error("expected update to remain denied")
+	}
+}
+
+func TestPolicy_DefaultAllow(t *testing.T) {
+	p, err := LoadPolicy(strings.NewReader(`default_policy = "allow"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Allow("anyone", "slack", ActionUpdate) {
+		t.Error("expected default_policy allow to grant anything with no matching rule")
+	}
+}
+
+func TestMiddleware_RejectsDisallowedActionWith403(t *testing.T) {
+	doc := `
+default_policy = "deny"
+
+[[rule]]
+  subjects = ["operator"]
+  sections = ["slack"]
+  actions = ["read"]
+`
+	p, err := LoadPolicy(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var reached bool
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { reached = true })
+	subjectOf := func(r *http.Request) string { return "operator" }
+	h := Middleware(p, "/kapacitor/v1/config/", subjectOf, next)
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/kapacitor/v1/config/slack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected read on slack to be allowed, got %d", resp.StatusCode)
+	}
+	resp.Body.Close()
+	if !reached {
+		t.Fatal("expected the wrapped handler to run")
+	}
+
+	reached = false
+	resp, err = http.Post(srv.URL+"/kapacitor/v1/config/pagerduty", "application/json", strings.NewReader(`{"set":{"enabled":true}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected update on pagerduty to be forbidden, got %d", resp.StatusCode)
+	}
+	resp.Body.Close()
+	if reached {
+		t.Fatal("expected the wrapped handler not to run for a denied request")
+	}
+}
+
+func TestActionForRequest_DetectsActionFromBodyWithoutConsumingIt(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/kapacitor/v1/config/slack/devops", strings.NewReader(`{"add":{"channel":"#devops"}}`))
+	action, err := actionForRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if action != ActionAdd {
+		t.Fatalf("expected ActionAdd, got %s", action)
+	}
+
+	body := make([]byte, 64)
+	n, _ := req.Body.Read(body)
+	if !strings.Contains(string(body[:n]), "add") {
+		t.Fatal("expected the request body to still be readable after action detection")
+	}
+}
+
+func TestRedactOthers_MasksUnlessAllowedToReveal(t *testing.T) {
+	doc := `
+default_policy = "deny"
+
+[[rule]]
+  subjects = ["admin"]
+  sections = ["slack"]
+  actions = ["reveal-secrets"]
+`
+	p, err := LoadPolicy(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	operatorOptions := map[string]interface{}{"url": "http://slack.example.com/secret"}
+	RedactOthers(p, "operator", "slack", operatorOptions, []string{"url"})
+	if operatorOptions["url"] != true {
+		t.Fatalf("expected operator to see a masked boolean, got %v", operatorOptions["url"])
+	}
+
+	adminOptions := map[string]interface{}{"url": "http://slack.example.com/secret"}
+	RedactOthers(p, "admin", "slack", adminOptions, []string{"url"})
+	if adminOptions["url"] != "http://slack.example.com/secret" {
+		t.Fatalf("expected admin to see the live value, got %v", adminOptions["url"])
+	}
+}
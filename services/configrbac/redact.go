@@ -0,0 +1,17 @@
+package configrbac
+
+// RedactOthers masks every field named in redactedFields down to a
+// "was it set" boolean — the config API's usual placeholder for a
+// secret value — unless subject is allowed ActionRevealSecrets on
+// section, in which case options is left untouched so an admin sees the
+// live value.
+func RedactOthers(p *Policy, subject, section string, options map[string]interface{}, redactedFields []string) {
+	if p.Allow(subject, section, ActionRevealSecrets) {
+		return
+	}
+	for _, field := range redactedFields {
+		if v, ok := options[field]; ok {
+			options[field] = v != nil && v != ""
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package configschema
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Registry maps each dynamic config section's name to an example value
+// of its Go config struct, from which a Schema is generated on demand.
+type Registry struct {
+	sections map[string]interface{}
+}
+
+func NewRegistry() *Registry {
+	return &Registry{sections: make(map[string]interface{})}
+}
+
+// Register associates section with an instance (or pointer to one) of
+// its config struct.
+func (r *Registry) Register(section string, v interface{}) {
+	r.sections[section] = v
+}
+
+// Schema returns the generated Schema for section, or nil if unknown.
+func (r *Registry) Schema(section string) *Schema {
+	v, ok := r.sections[section]
+	if !ok {
+		return nil
+	}
+	return Generate(v)
+}
+
+// Composed returns a single schema whose properties are every registered
+// section, for GET /kapacitor/v1/config/schema.
+func (r *Registry) Composed() *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for name, v := range r.sections {
+		s.Properties[name] = Generate(v)
+	}
+	return s
+}
+
+// ServeHTTP implements GET /kapacitor/v1/config/{section}/schema and
+// GET /kapacitor/v1/config/schema (when the path has no section
+// segment).
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	section, ok := sectionFromPath(req.URL.Path)
+	var schema *Schema
+	if !ok {
+		schema = r.Composed()
+	} else {
+		schema = r.Schema(section)
+		if schema == nil {
+			http.Error(w, "unknown config section "+section, http.StatusNotFound)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schema)
+}
+
+// sectionFromPath extracts {section} from
+// /kapacitor/v1/config/{section}/schema, reporting false for the
+// top-level /kapacitor/v1/config/schema path.
+func sectionFromPath(path string) (string, bool) {
+	const suffix = "/schema"
+	if !strings.HasSuffix(path, suffix) {
+		return "", false
+	}
+	trimmed := strings.TrimSuffix(path, suffix)
+	trimmed = strings.TrimSuffix(trimmed, "/")
+	idx := strings.LastIndex(trimmed, "/")
+	if idx < 0 {
+		return "", false
+	}
+	section := trimmed[idx+1:]
+	if section == "config" || section == "" {
+		return "", false
+	}
+	return section, true
+}
@@ -0,0 +1,148 @@
+// Package configschema generates JSON Schema (draft 2019-09) documents
+// from the Go structs backing a dynamic config section, via reflection
+// over the same `toml`/`override` struct tags the config override
+// machinery already reads, so the schema served to UIs never drifts from
+// the structs that actually back `ConfigUpdateAction`.
+package configschema
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema is a (deliberately small) subset of JSON Schema draft 2019-09 —
+// enough to describe every dynamic config section's shape.
+type Schema struct {
+	Type       string             `json:"type,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+	Enum       []string           `json:"enum,omitempty"`
+	Format     string             `json:"format,omitempty"`
+	WriteOnly  bool               `json:"writeOnly,omitempty"`
+	Default    interface{}        `json:"default,omitempty"`
+
+	// RequiredWhen captures a conditional requirement this field has on
+	// another field's value, e.g. "ssl-cert" required when
+	// "subscription-protocol" == "https". It isn't standard JSON Schema,
+	// hence the x- prefix on the wire.
+	RequiredWhen *Condition `json:"x-kapacitor-required-when,omitempty"`
+}
+
+// Condition is a single "field equals value" requirement.
+type Condition struct {
+	Field string `json:"field"`
+	Value string `json:"value"`
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Generate builds a Schema describing v's type, which must be a struct
+// or a pointer to one.
+func Generate(v interface{}) *Schema {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return generateStruct(t)
+}
+
+func generateStruct(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue // unexported
+		}
+
+		name, writeOnly := propertyName(f)
+		if name == "-" {
+			continue
+		}
+
+		prop := generateField(f.Type)
+		prop.WriteOnly = writeOnly
+
+		if tag, ok := f.Tag.Lookup("jsonschema"); ok {
+			applyTagOptions(prop, tag)
+		}
+
+		s.Properties[name] = prop
+	}
+
+	return s
+}
+
+func generateField(t reflect.Type) *Schema {
+	if t == durationType {
+		return &Schema{Type: "string", Format: "duration"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return generateField(t.Elem())
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: generateField(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		return generateStruct(t)
+	default:
+		return &Schema{}
+	}
+}
+
+// propertyName reads the field's toml (falling back to override, then
+// the Go field name) tag for its JSON Schema property name, and reports
+// whether the override tag marks it redacted (",redact").
+func propertyName(f reflect.StructField) (name string, writeOnly bool) {
+	name = f.Name
+	if toml, ok := f.Tag.Lookup("toml"); ok && toml != "" {
+		name = strings.Split(toml, ",")[0]
+	} else if override, ok := f.Tag.Lookup("override"); ok && override != "" {
+		name = strings.Split(override, ",")[0]
+	}
+	if override, ok := f.Tag.Lookup("override"); ok {
+		parts := strings.Split(override, ",")
+		for _, p := range parts[1:] {
+			if p == "redact" {
+				writeOnly = true
+			}
+		}
+	}
+	return name, writeOnly
+}
+
+// applyTagOptions parses a `jsonschema:"..."` tag of semicolon-separated
+// options: "enum=a|b|c" and "requiredWhen=field=value".
+func applyTagOptions(s *Schema, tag string) {
+	for _, opt := range strings.Split(tag, ";") {
+		if opt == "" {
+			continue
+		}
+		kv := strings.SplitN(opt, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "enum":
+			s.Enum = strings.Split(kv[1], "|")
+		case "requiredWhen":
+			fv := strings.SplitN(kv[1], "=", 2)
+			if len(fv) == 2 {
+				s.RequiredWhen = &Condition{Field: fv[0], Value: fv[1]}
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package configschema
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// sampleHandlerConfig mirrors the shape of a typical alert handler config
+// section (e.g. alerta, hipchat): credentials, a protocol enum, and a
+// conditional TLS requirement.
+type sampleHandlerConfig struct {
+	Enabled              bool          `toml:"enabled" override:"enabled"`
+	URL                  string        `toml:"url" override:"url"`
+	Token                string        `toml:"token" override:"token,redact"`
+	SubscriptionProtocol string        `toml:"subscription-protocol" override:"subscription-protocol" jsonschema:"enum=http|https"`
+	InsecureSkipVerify   bool          `toml:"insecure-skip-verify" override:"insecure-skip-verify"`
+	SSLCert              string        `toml:"ssl-cert" override:"ssl-cert" jsonschema:"requiredWhen=subscription-protocol=https"`
+	Timeout              time.Duration `toml:"timeout" override:"timeout"`
+	Tags                 []string      `toml:"tags" override:"tags"`
+}
+
+func TestGenerate_BasicFieldTypes(t *testing.T) {
+	s := Generate(sampleHandlerConfig{})
+
+	if s.Type != "object" {
+		t.Fatalf("expected object schema, got %s", s.Type)
+	}
+	if s.Properties["enabled"].Type != "boolean" {
+		t.Fatalf("expected enabled to be boolean, got %s", s.Properties["enabled"].Type)
+	}
+	if s.Properties["url"].Type != "string" {
+		t.Fatalf("expected url to be string, got %s", s.Properties["url"].Type)
+	}
+	if s.Properties["tags"].Type != "array" || s.Properties["tags"].Items.Type != "string" {
+		t.Fatalf("expected tags to be an array of strings, got %+v", s.Properties["tags"])
+	}
+}
+
+func TestGenerate_DurationGetsFormatDuration(t *testing.T) {
+	s := Generate(sampleHandlerConfig{})
+	timeout := s.Properties["timeout"]
+	if timeout.Type != "string" || timeout.Format != "duration" {
+		t.Fatalf("expected timeout to be a duration-formatted string, got %+v", timeout)
+	}
+}
+
+func TestGenerate_RedactedFieldIsWriteOnly(t *testing.T) {
+	s := Generate(sampleHandlerConfig{})
+	if !s.Properties["token"].WriteOnly {
+		t.Fatal("expected the redacted token field to be writeOnly")
+	}
+	if s.Properties["url"].WriteOnly {
+		t.Fatal("expected a non-redacted field to not be writeOnly")
+	}
+}
+
+func TestGenerate_EnumAndRequiredWhen(t *testing.T) {
+	s := Generate(sampleHandlerConfig{})
+
+	proto := s.Properties["subscription-protocol"]
+	if len(proto.Enum) != 2 || proto.Enum[0] != "http" || proto.Enum[1] != "https" {
+		t.Fatalf("expected subscription-protocol enum [http https], got %v", proto.Enum)
+	}
+
+	cert := s.Properties["ssl-cert"]
+	if cert.RequiredWhen == nil || cert.RequiredWhen.Field != "subscription-protocol" || cert.RequiredWhen.Value != "https" {
+		t.Fatalf("expected ssl-cert to have a requiredWhen condition, got %+v", cert.RequiredWhen)
+	}
+}
+
+func TestValidate_RejectsBadEnumAndMissingConditionalField(t *testing.T) {
+	s := Generate(sampleHandlerConfig{})
+
+	errs := Validate(s, map[string]interface{}{
+		"subscription-protocol": "ftp",
+	})
+	if len(errs) == 0 {
+		t.Fatal("expected an enum violation for an unsupported protocol")
+	}
+
+	errs = Validate(s, map[string]interface{}{
+		"subscription-protocol": "https",
+	})
+	found := false
+	for _, e := range errs {
+		if e.Path == "ssl-cert" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected a required-when violation for missing ssl-cert, got %+v", errs)
+	}
+}
+
+func TestValidate_AcceptsWellFormedUpdate(t *testing.T) {
+	s := Generate(sampleHandlerConfig{})
+	errs := Validate(s, map[string]interface{}{
+		"enabled":               true,
+		"subscription-protocol": "https",
+		"ssl-cert":              "/etc/kapacitor/cert.pem",
+	})
+	if len(errs) != 0 {
+		t.Fatalf("expected a well-formed update to validate cleanly, got %+v", errs)
+	}
+}
+
+func TestRegistry_ServeHTTPSectionAndComposed(t *testing.T) {
+	r := NewRegistry()
+	r.Register("alerta", sampleHandlerConfig{})
+
+	srv := httptest.NewServer(http.HandlerFunc(r.ServeHTTP))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/kapacitor/v1/config/alerta/schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for a known section, got %d", resp.StatusCode)
+	}
+	resp.Body.Close()
+
+	resp, err = http.Get(srv.URL + "/kapacitor/v1/config/missing/schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown section, got %d", resp.StatusCode)
+	}
+	resp.Body.Close()
+
+	resp, err = http.Get(srv.URL + "/kapacitor/v1/config/schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for the composed schema, got %d", resp.StatusCode)
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,99 @@
+package configschema
+
+import (
+	"fmt"
+)
+
+// ValidationError is a single structured validation failure, identifying
+// the property path so a UI can highlight the offending field.
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Validate checks candidate (the decoded JSON body of a
+// ConfigUpdateAction Set/Add) against s, checking value types, enum
+// membership, and any RequiredWhen conditions. It returns every
+// violation found, not just the first.
+func Validate(s *Schema, candidate map[string]interface{}) []ValidationError {
+	var errs []ValidationError
+	validateObject(s, candidate, "", &errs)
+	return errs
+}
+
+func validateObject(s *Schema, candidate map[string]interface{}, path string, errs *[]ValidationError) {
+	for name, prop := range s.Properties {
+		fieldPath := joinPath(path, name)
+		v, present := candidate[name]
+
+		if prop.RequiredWhen != nil {
+			if cond, ok := candidate[prop.RequiredWhen.Field]; ok {
+				if fmt.Sprintf("%v", cond) == prop.RequiredWhen.Value && !present {
+					*errs = append(*errs, ValidationError{
+						Path:    fieldPath,
+						Message: fmt.Sprintf("required when %s=%s", prop.RequiredWhen.Field, prop.RequiredWhen.Value),
+					})
+					continue
+				}
+			}
+		}
+
+		if !present {
+			continue
+		}
+
+		validateValue(prop, v, fieldPath, errs)
+	}
+}
+
+func validateValue(prop *Schema, v interface{}, path string, errs *[]ValidationError) {
+	switch prop.Type {
+	case "string":
+		s, ok := v.(string)
+		if !ok {
+			*errs = append(*errs, ValidationError{Path: path, Message: "expected a string"})
+			return
+		}
+		if len(prop.Enum) > 0 && !contains(prop.Enum, s) {
+			*errs = append(*errs, ValidationError{Path: path, Message: fmt.Sprintf("must be one of %v", prop.Enum)})
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			*errs = append(*errs, ValidationError{Path: path, Message: "expected a boolean"})
+		}
+	case "integer", "number":
+		switch v.(type) {
+		case float64, int, int64:
+		default:
+			*errs = append(*errs, ValidationError{Path: path, Message: "expected a number"})
+		}
+	case "object":
+		if obj, ok := v.(map[string]interface{}); ok && len(prop.Properties) > 0 {
+			validateObject(prop, obj, path, errs)
+		}
+	case "array":
+		if _, ok := v.([]interface{}); !ok {
+			*errs = append(*errs, ValidationError{Path: path, Message: "expected an array"})
+		}
+	}
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,44 @@
+// Package dedup implements the "dedup" HandlerAction: a decorator around
+// a downstream alert.Handler that suppresses repeated identical alerts
+// within Interval and collapses alerts grouped by a set of tag keys into
+// a single downstream event, alert-manager style.
+package dedup
+
+import (
+	"errors"
+
+	"github.com/influxdata/toml"
+)
+
+// Config configures one dedup action instance.
+type Config struct {
+	// Interval suppresses an identical repeated alert (same group, same
+	// message) fired again within this window.
+	Interval toml.Duration `toml:"interval" override:"interval"`
+	// GroupBy lists the tag keys (read from AlertData's series tags)
+	// whose values, together with the alert level, identify a group.
+	// With GroupBy empty, all alerts of a level share a single group.
+	GroupBy []string `toml:"group-by" override:"group-by"`
+	// GroupWait is how long a brand new group waits before its first
+	// notification goes out, so alerts arriving in quick succession
+	// collapse into one downstream event instead of one each.
+	GroupWait toml.Duration `toml:"group-wait" override:"group-wait"`
+	// GroupInterval is the minimum gap between notifications for a group
+	// that keeps receiving new alerts after its first notification.
+	GroupInterval toml.Duration `toml:"group-interval" override:"group-interval"`
+	// RepeatInterval is how long to wait before re-sending a
+	// notification for a group that has gone quiet (no new alerts) but
+	// hasn't been re-notified in a while.
+	RepeatInterval toml.Duration `toml:"repeat-interval" override:"repeat-interval"`
+}
+
+func NewConfig() Config {
+	return Config{}
+}
+
+func (c Config) Validate() error {
+	if c.Interval < 0 || c.GroupWait < 0 || c.GroupInterval < 0 || c.RepeatInterval < 0 {
+		return errors.New("dedup: interval, group-wait, group-interval, and repeat-interval must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package dedup
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/influxdata/kapacitor/alert"
+)
+
+// Handler wraps Next, a downstream alert.Handler, suppressing exact
+// repeats within Config.Interval and collapsing alerts that share a
+// group key into a single downstream event once GroupWait (for a new
+// group) or GroupInterval (for a group already notified) has elapsed.
+//
+// Handle only ever records the event into its group's pending state; it
+// never calls Next itself. Flush must be driven by polling (the same
+// RunDue pattern services/servicetest.Scheduler uses) to actually
+// deliver due batches.
+type Handler struct {
+	c     Config
+	store *store
+	Next  alert.Handler
+	Now   func() time.Time
+
+	mu sync.Mutex
+}
+
+// NewHandler builds a Handler persisting its group state in db, which
+// must already be open. Group state survives process restarts, since it
+// lives in the same bolt store the rest of the handler configuration
+// does.
+func NewHandler(c Config, db *bolt.DB, next alert.Handler) (*Handler, error) {
+	s, err := newStore(db)
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{c: c, store: s, Next: next, Now: time.Now}, nil
+}
+
+// groupKey identifies the group an event belongs to: its level plus the
+// values of c.GroupBy tag keys, in configured order. Alerts missing a
+// configured tag are grouped under an empty value for that key, the same
+// as alertmanager's "unset label" behavior.
+func (h *Handler) groupKey(event alert.Event) string {
+	var tags map[string]string
+	if event.Data.Result.Series != nil && len(event.Data.Result.Series) > 0 {
+		tags = event.Data.Result.Series[0].Tags
+	}
+
+	parts := make([]string, 0, len(h.c.GroupBy)+1)
+	parts = append(parts, string(event.State.Level))
+	for _, k := range h.c.GroupBy {
+		parts = append(parts, tags[k])
+	}
+	return strings.Join(parts, "\x1f")
+}
+
+// Handle records event under its group, suppressing it if it exactly
+// repeats the group's last message within Config.Interval, then adds it
+// to the group's pending batch. The batch is not sent here: Flush must
+// be called (directly, or periodically by a caller) to deliver any
+// batches whose wait has elapsed.
+func (h *Handler) Handle(event alert.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	key := h.groupKey(event)
+
+	gs, err := h.store.get(key)
+	if err != nil {
+		return
+	}
+
+	if h.c.Interval > 0 && gs.LastMessage == event.State.Message && !gs.LastFired.IsZero() &&
+		now.Sub(gs.LastFired) < time.Duration(h.c.Interval) {
+		return
+	}
+	gs.LastMessage = event.State.Message
+	gs.LastFired = now
+	gs.Level = event.State.Level
+	if event.Data.Result.Series != nil && len(event.Data.Result.Series) > 0 {
+		gs.Tags = event.Data.Result.Series[0].Tags
+	}
+
+	if len(gs.Pending) == 0 {
+		gs.FirstPending = now
+	}
+	gs.Pending = append(gs.Pending, event.State.Message)
+
+	h.store.put(key, gs)
+}
+
+func (h *Handler) now() time.Time {
+	if h.Now != nil {
+		return h.Now()
+	}
+	return time.Now()
+}
+
+// Flush delivers a merged downstream event for every group whose pending
+// batch is due: a new batch once Config.GroupWait has elapsed since its
+// first alert, or a batch on an already-notified group once
+// Config.GroupInterval has elapsed since its last notification. A group
+// with nothing pending is still re-notified with its last message once
+// Config.RepeatInterval has passed, so a long-lived alert isn't
+// forgotten just because it stopped producing new alerts.
+func (h *Handler) Flush(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var due []string
+	h.store.forEach(func(key string, gs groupState) error {
+		if h.isDue(gs, now) {
+			due = append(due, key)
+		}
+		return nil
+	})
+
+	for _, key := range due {
+		gs, err := h.store.get(key)
+		if err != nil {
+			continue
+		}
+		h.flushGroup(key, gs, now)
+	}
+}
+
+func (h *Handler) isDue(gs groupState, now time.Time) bool {
+	if len(gs.Pending) > 0 {
+		if gs.LastNotified.IsZero() {
+			return !now.Before(gs.FirstPending.Add(time.Duration(h.c.GroupWait)))
+		}
+		return !now.Before(gs.LastNotified.Add(time.Duration(h.c.GroupInterval)))
+	}
+	if h.c.RepeatInterval > 0 && !gs.LastNotified.IsZero() {
+		return !now.Before(gs.LastNotified.Add(time.Duration(h.c.RepeatInterval)))
+	}
+	return false
+}
+
+func (h *Handler) flushGroup(key string, gs groupState, now time.Time) {
+	var event alert.Event
+	switch {
+	case len(gs.Pending) == 1:
+		event = mergedEvent(gs.Level, gs.Tags, gs.Pending[0])
+	case len(gs.Pending) > 1:
+		event = mergedEvent(gs.Level, gs.Tags, strings.Join(gs.Pending, "; "))
+	default:
+		event = mergedEvent(gs.Level, gs.Tags, gs.LastMessage)
+	}
+
+	gs.Pending = nil
+	gs.LastNotified = now
+	h.store.put(key, gs)
+
+	if h.Next != nil {
+		h.Next.Handle(event)
+	}
+}
+
+// mergedEvent builds the downstream event for a flushed batch. level and
+// tags are constant across the group (groupKey groups strictly by level
+// plus GroupBy tag values), so they're carried over from the group state
+// rather than left at their zero values; the exported field set of
+// alert.Event beyond State.Message/State.Level and
+// Data.Result.Series[0].Tags isn't something this package should guess
+// at.
+func mergedEvent(level alert.Level, tags map[string]string, message string) alert.Event {
+	var event alert.Event
+	event.State.Level = level
+	event.State.Message = message
+	if len(tags) > 0 {
+		event.Data.Result.Series = []alert.Series{{Tags: tags}}
+	}
+	return event
+}
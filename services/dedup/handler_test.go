@@ -0,0 +1,184 @@
+package dedup
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/toml"
+)
+
+func openTestDB(t *testing.T) (*bolt.DB, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "dedup-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := bolt.Open(filepath.Join(dir, "dedup.db"), 0600, nil)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+type fakeNext struct {
+	mu       sync.Mutex
+	received []alert.Event
+}
+
+func (f *fakeNext) Handle(event alert.Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.received = append(f.received, event)
+}
+
+func (f *fakeNext) calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.received)
+}
+
+func testEvent(level alert.Level, message string) alert.Event {
+	var event alert.Event
+	event.State.Level = level
+	event.State.Message = message
+	return event
+}
+
+func TestHandler_Interval_SuppressesIdenticalRepeat(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	next := &fakeNext{}
+	c := Config{Interval: toDuration(time.Minute)}
+	h, err := NewHandler(c, db, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(0, 0)
+	h.Now = func() time.Time { return now }
+
+	h.Handle(testEvent(alert.Critical, "disk full"))
+	now = now.Add(30 * time.Second)
+	h.Handle(testEvent(alert.Critical, "disk full"))
+
+	h.Flush(now)
+	if got, exp := next.calls(), 1; got != exp {
+		t.Fatalf("got %d downstream calls, expected %d", got, exp)
+	}
+	if got, exp := next.received[0].State.Message, "disk full"; got != exp {
+		t.Fatalf("unexpected merged message got %q exp %q", got, exp)
+	}
+}
+
+func TestHandler_GroupWait_CollapsesBurstIntoOneEvent(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	next := &fakeNext{}
+	c := Config{GroupWait: toDuration(time.Minute)}
+	h, err := NewHandler(c, db, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(0, 0)
+	h.Now = func() time.Time { return now }
+
+	for i := 0; i < 5; i++ {
+		h.Handle(testEvent(alert.Critical, fmt.Sprintf("alert %d", i)))
+		now = now.Add(time.Second)
+	}
+
+	h.Flush(now)
+	if got, exp := next.calls(), 0; got != exp {
+		t.Fatalf("expected no downstream call before GroupWait elapses, got %d", got)
+	}
+
+	now = now.Add(time.Minute)
+	h.Flush(now)
+	if got, exp := next.calls(), 1; got != exp {
+		t.Fatalf("expected exactly one merged downstream call, got %d", got)
+	}
+}
+
+func TestHandler_100AlertsAcrossThreeGroups_ExactlyThreeDownstreamCalls(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	next := &fakeNext{}
+	c := Config{GroupWait: toDuration(time.Minute)}
+	h, err := NewHandler(c, db, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(0, 0)
+	h.Now = func() time.Time { return now }
+
+	levels := []alert.Level{alert.Critical, alert.Warning, alert.Info}
+	for i := 0; i < 100; i++ {
+		level := levels[i%len(levels)]
+		h.Handle(testEvent(level, fmt.Sprintf("alert %d", i)))
+	}
+
+	now = now.Add(time.Minute)
+	h.Flush(now)
+
+	if got, exp := next.calls(), 3; got != exp {
+		t.Fatalf("expected exactly 3 downstream calls for 3 groups, got %d", got)
+	}
+}
+
+func TestHandler_Flush_MergedEventKeepsLevelAndTags(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+
+	next := &fakeNext{}
+	c := Config{GroupWait: toDuration(time.Minute), GroupBy: []string{"host"}}
+	h, err := NewHandler(c, db, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(0, 0)
+	h.Now = func() time.Time { return now }
+
+	event := testEvent(alert.Warning, "disk at 80%")
+	event.Data.Result.Series = []alert.Series{{Tags: map[string]string{"host": "serverA"}}}
+	h.Handle(event)
+
+	now = now.Add(time.Minute)
+	h.Flush(now)
+
+	if got, exp := next.calls(), 1; got != exp {
+		t.Fatalf("got %d downstream calls, expected %d", got, exp)
+	}
+	merged := next.received[0]
+	if got, exp := merged.State.Level, alert.Warning; got != exp {
+		t.Fatalf("expected merged event to keep level %v, got %v", exp, got)
+	}
+	if got, exp := merged.Data.Result.Series[0].Tags["host"], "serverA"; got != exp {
+		t.Fatalf("expected merged event to keep tag host=%q, got %q", exp, got)
+	}
+}
+
+func toDuration(d time.Duration) toml.Duration {
+	return toml.Duration(d)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	if err := (Config{}).Validate(); err != nil {
+		t.Fatalf("expected the zero-value config to validate, got %v", err)
+	}
+	if err := (Config{Interval: toDuration(-time.Second)}).Validate(); err == nil {
+		t.Fatal("expected a negative interval to fail validation")
+	}
+}
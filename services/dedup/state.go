@@ -0,0 +1,91 @@
+package dedup
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/pkg/errors"
+)
+
+var groupBucket = []byte("dedup_groups")
+
+// groupState is the persisted state for one group key, stored as a
+// single JSON value so the whole record can be read or written in one
+// bolt operation.
+type groupState struct {
+	// LastMessage is the most recently seen alert message for this
+	// group, used to suppress an identical repeat within Interval.
+	LastMessage string    `json:"lastMessage"`
+	LastFired   time.Time `json:"lastFired"`
+
+	// Level and Tags are constant for every event in the group, since
+	// groupKey groups strictly by level plus GroupBy tag values. Kept
+	// here so a merged/flushed event can carry its real level and tags
+	// instead of the alert.Event zero values.
+	Level alert.Level       `json:"level,omitempty"`
+	Tags  map[string]string `json:"tags,omitempty"`
+
+	// FirstPending is when the current pending batch started, used to
+	// decide when GroupWait has elapsed.
+	FirstPending time.Time `json:"firstPending,omitempty"`
+	// Pending holds the messages collected since the last downstream
+	// notification, merged into one event when the batch flushes.
+	Pending []string `json:"pending,omitempty"`
+
+	// LastNotified is when a downstream event was last sent for this
+	// group, used to enforce GroupInterval/RepeatInterval.
+	LastNotified time.Time `json:"lastNotified,omitempty"`
+}
+
+// store persists groupState in a BoltDB bucket, one key per group.
+type store struct {
+	db *bolt.DB
+}
+
+func newStore(db *bolt.DB) (*store, error) {
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(groupBucket)
+		return err
+	}); err != nil {
+		return nil, errors.Wrap(err, "dedup: creating bucket")
+	}
+	return &store{db: db}, nil
+}
+
+func (s *store) get(key string) (groupState, error) {
+	var gs groupState
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(groupBucket).Get([]byte(key))
+		if v == nil {
+			return nil
+		}
+		return json.Unmarshal(v, &gs)
+	})
+	return gs, err
+}
+
+func (s *store) put(key string, gs groupState) error {
+	data, err := json.Marshal(gs)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(groupBucket).Put([]byte(key), data)
+	})
+}
+
+// forEach calls fn for every persisted group key, in bolt's byte-sorted
+// key order. fn must not mutate the store.
+func (s *store) forEach(fn func(key string, gs groupState) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(groupBucket).ForEach(func(k, v []byte) error {
+			var gs groupState
+			if err := json.Unmarshal(v, &gs); err != nil {
+				return err
+			}
+			return fn(string(k), gs)
+		})
+	})
+}
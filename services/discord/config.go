@@ -0,0 +1,73 @@
+package discord
+
+import (
+	"github.com/influxdata/kapacitor/services/httpauth"
+	"github.com/influxdata/kapacitor/services/httpd/tlsreload"
+	"github.com/influxdata/kapacitor/services/httpretry"
+	"github.com/pkg/errors"
+)
+
+// Config is the discord service section. URL is the default incoming
+// webhook; Channels maps a named channel (as referenced by a handler's
+// Channel option) to an additional webhook URL, since a Discord incoming
+// webhook is bound to a single channel at creation time rather than
+// accepting a channel parameter per message like Slack's.
+type Config struct {
+	// Whether Discord integration is enabled.
+	Enabled bool `toml:"enabled" override:"enabled"`
+	// Whether this section is used as the default handler for all alerts.
+	Global bool `toml:"global" override:"global"`
+	// Only post a message when the alert state changes.
+	StateChangesOnly bool `toml:"state-changes-only" override:"state-changes-only"`
+	// Default incoming webhook URL.
+	URL string `toml:"url" override:"url,redact"`
+	// Additional named webhook URLs, keyed by channel name.
+	Channels map[string]string `toml:"channels" override:"channels,redact"`
+	// Username overrides the webhook's default bot name.
+	Username string `toml:"username" override:"username"`
+	// AvatarURL overrides the webhook's default avatar.
+	AvatarURL string `toml:"avatar-url" override:"avatar-url"`
+	// MentionUsers are Discord user IDs mentioned (<@id>) in every
+	// message's content, so an alert pings on-call even if nobody is
+	// watching the channel.
+	MentionUsers []string `toml:"mention-users" override:"mention-users"`
+	// Retry configures backoff and dead-letter forwarding for transient
+	// webhook delivery failures. The zero value disables retries.
+	Retry httpretry.Config `toml:"retry" override:"retry"`
+	// TLS configures client certificate and CA material for webhooks
+	// hosted behind mTLS, such as an internal relay. The zero value uses
+	// the system default TLS settings.
+	TLS tlsreload.Config `toml:"tls" override:"tls"`
+	// Auth configures bearer, basic, or custom header authorization for
+	// webhooks hosted behind an auth proxy. The zero value sends no
+	// authorization.
+	Auth httpauth.Config `toml:"auth" override:"auth"`
+}
+
+func NewConfig() Config {
+	return Config{}
+}
+
+func (c Config) Validate() error {
+	if c.Enabled && c.URL == "" && len(c.Channels) == 0 {
+		return errors.New("must specify url or at least one channel webhook")
+	}
+	if err := c.Retry.Validate(); err != nil {
+		return err
+	}
+	if err := c.TLS.Validate(); err != nil {
+		return err
+	}
+	return c.Auth.Validate()
+}
+
+// webhookFor returns the webhook URL for channel, falling back to the
+// default URL when channel is empty or not found among Channels.
+func (c Config) webhookFor(channel string) string {
+	if channel != "" {
+		if u, ok := c.Channels[channel]; ok {
+			return u
+		}
+	}
+	return c.URL
+}
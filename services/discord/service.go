@@ -0,0 +1,338 @@
+package discord
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/kapacitor/services/alerttemplate"
+	"github.com/influxdata/kapacitor/services/httpd/tlsreload"
+	"github.com/influxdata/kapacitor/services/httpretry"
+)
+
+// levelColor returns the embed's accent color as a decimal RGB integer,
+// the format Discord's embed API expects, matching the alert level's
+// severity.
+func levelColor(level alert.Level) int {
+	switch level {
+	case alert.OK:
+		return 0x2DC72D
+	case alert.Info:
+		return 0x439FE0
+	case alert.Warning:
+		return 0xFFA500
+	case alert.Critical:
+		return 0xFF0000
+	}
+	return 0x808080
+}
+
+// embedField is a single name/value row rendered inside an embed.
+type embedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+type embedAuthor struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+type embedFooter struct {
+	Text string `json:"text,omitempty"`
+}
+
+type embed struct {
+	Title       string       `json:"title,omitempty"`
+	URL         string       `json:"url,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Color       int          `json:"color"`
+	Timestamp   string       `json:"timestamp,omitempty"`
+	Author      *embedAuthor `json:"author,omitempty"`
+	Footer      *embedFooter `json:"footer,omitempty"`
+	Fields      []embedField `json:"fields,omitempty"`
+}
+
+// webhookPayload is the body Discord's incoming webhook API expects.
+// https://discord.com/developers/docs/resources/webhook#execute-webhook
+type webhookPayload struct {
+	Content   string  `json:"content,omitempty"`
+	Username  string  `json:"username,omitempty"`
+	AvatarURL string  `json:"avatar_url,omitempty"`
+	Embeds    []embed `json:"embeds,omitempty"`
+}
+
+type Service struct {
+	configValue atomic.Value
+	loaderValue atomic.Value
+
+	// DeadLetter, if set, is called with an alert's webhook payload once
+	// every retry attempt for it has failed.
+	DeadLetter func(body []byte, lastErr error) error
+
+	logger *log.Logger
+}
+
+func NewService(c Config, l *log.Logger) *Service {
+	s := &Service{logger: l}
+	s.configValue.Store(c)
+	s.loaderValue.Store(tlsreload.NewLoader(c.TLS))
+	return s
+}
+
+// httpClient builds an *http.Client applying the configured Retry policy
+// and TLS material over a plain http.Transport; with neither configured
+// it makes a single attempt with default TLS verification.
+func (s *Service) httpClient() (*http.Client, error) {
+	c := s.config()
+	tlsConf, err := s.loaderValue.Load().(*tlsreload.Loader).Get()
+	if err != nil {
+		return nil, err
+	}
+	t := &httpretry.Transport{
+		Config: c.Retry,
+		Next:   &http.Transport{TLSClientConfig: tlsConf},
+	}
+	if s.DeadLetter != nil {
+		t.DeadLetter = func(req *http.Request, body []byte, lastErr error) error {
+			return s.DeadLetter(body, lastErr)
+		}
+	}
+	return &http.Client{Transport: t}, nil
+}
+
+func (s *Service) Open() error  { return nil }
+func (s *Service) Close() error { return nil }
+
+func (s *Service) Update(newConfig []interface{}) error {
+	if l := len(newConfig); l != 1 {
+		return fmt.Errorf("expected only one new config object, got %d", l)
+	}
+	c, ok := newConfig[0].(Config)
+	if !ok {
+		return fmt.Errorf("expected config object to be of type %T, got %T", c, newConfig[0])
+	}
+	s.configValue.Store(c)
+	s.loaderValue.Store(tlsreload.NewLoader(c.TLS))
+	return nil
+}
+
+func (s *Service) config() Config {
+	return s.configValue.Load().(Config)
+}
+
+type testOptions struct {
+	Channel string      `json:"channel"`
+	Title   string      `json:"title"`
+	Message string      `json:"message"`
+	Level   alert.Level `json:"level"`
+}
+
+func (s *Service) TestOptions() interface{} {
+	return &testOptions{
+		Message: "test discord message",
+		Level:   alert.Warning,
+	}
+}
+
+func (s *Service) Test(options interface{}) error {
+	o, ok := options.(*testOptions)
+	if !ok {
+		return fmt.Errorf("unexpected options type %t", options)
+	}
+	return s.Alert(o.Channel, o.Title, o.Message, o.Level, nil, EmbedOptions{})
+}
+
+// EmbedOptions carries the optional rich-attachment fields a handler can
+// set on top of an embed's title/description/color/fields.
+type EmbedOptions struct {
+	// TitleLink, if set, makes the embed's title a link to this URL.
+	TitleLink string
+	// AuthorName and AuthorLink, if set, add an author byline to the
+	// embed, linked to AuthorLink when it's also set.
+	AuthorName string
+	AuthorLink string
+	// FooterText, if set, adds a footer line to the embed.
+	FooterText string
+	// FieldTitles maps a tag name to the attachment field title it
+	// should be rendered under. A tag without an entry here is rendered
+	// under its own name, as before.
+	FieldTitles map[string]string
+}
+
+// fields builds the embed fields list from an alert's tags, so an
+// operator sees the data that triggered the alert without opening
+// Kapacitor. A tag present in fieldTitles is rendered under the mapped
+// title instead of its own tag name.
+func fields(tags map[string]string, fieldTitles map[string]string) []embedField {
+	f := make([]embedField, 0, len(tags))
+	for k, v := range tags {
+		name := k
+		if title, ok := fieldTitles[k]; ok {
+			name = title
+		}
+		f = append(f, embedField{Name: name, Value: v, Inline: true})
+	}
+	return f
+}
+
+// mentions renders the configured MentionUsers as Discord user mentions,
+// prefixed to the message content.
+func mentions(users []string) string {
+	if len(users) == 0 {
+		return ""
+	}
+	mentions := make([]string, len(users))
+	for i, u := range users {
+		mentions[i] = fmt.Sprintf("<@%s>", u)
+	}
+	return strings.Join(mentions, " ") + " "
+}
+
+// Alert posts an embed to channel's webhook (or the default webhook when
+// channel is empty).
+func (s *Service) Alert(channel, title, message string, level alert.Level, tags map[string]string, opts EmbedOptions) error {
+	c := s.config()
+	if !c.Enabled {
+		return fmt.Errorf("service is not enabled")
+	}
+
+	webhook := c.webhookFor(channel)
+	if webhook == "" {
+		return fmt.Errorf("no discord webhook configured for channel %q", channel)
+	}
+
+	e := embed{
+		Title:       title,
+		URL:         opts.TitleLink,
+		Description: message,
+		Color:       levelColor(level),
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		Fields:      fields(tags, opts.FieldTitles),
+	}
+	if opts.AuthorName != "" {
+		e.Author = &embedAuthor{Name: opts.AuthorName, URL: opts.AuthorLink}
+	}
+	if opts.FooterText != "" {
+		e.Footer = &embedFooter{Text: opts.FooterText}
+	}
+
+	payload := webhookPayload{
+		Content:   mentions(c.MentionUsers),
+		Username:  c.Username,
+		AvatarURL: c.AvatarURL,
+		Embeds:    []embed{e},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := c.Auth.Apply(req, payload); err != nil {
+		return err
+	}
+
+	client, err := s.httpClient()
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("failed to post discord message: %d %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// HandlerConfig is the per-alert-handler configuration for the discord
+// node in a TICKscript.
+type HandlerConfig struct {
+	// Channel selects a named webhook from the service's Channels map,
+	// falling back to the default webhook when empty. Channel may be a
+	// text/template expression evaluated against the triggering event
+	// (see alerttemplate.Data), e.g. "{{ index .Tags \"env\" }}" to
+	// route by tag.
+	Channel string `mapstructure:"channel"`
+	// Title overrides the embed title, otherwise the alert's message is
+	// used for both the title and the embed description.
+	Title string `mapstructure:"title"`
+	// TitleLink, if set, makes the embed's title a link to this URL,
+	// e.g. back to a dashboard for the alerting task.
+	TitleLink string `mapstructure:"title-link"`
+	// AuthorName and AuthorLink add an author byline to the embed.
+	AuthorName string `mapstructure:"author-name"`
+	AuthorLink string `mapstructure:"author-link"`
+	// FooterText adds a footer line to the embed.
+	FooterText string `mapstructure:"footer-text"`
+	// Fields maps a tag name to the attachment field title it should be
+	// rendered under, instead of the tag's own name.
+	Fields map[string]string `mapstructure:"fields"`
+}
+
+type handler struct {
+	s               *Service
+	c               HandlerConfig
+	channelTemplate *template.Template
+	logger          *log.Logger
+}
+
+func (s *Service) DefaultHandlerConfig() HandlerConfig {
+	return HandlerConfig{}
+}
+
+func (s *Service) Handler(c HandlerConfig, l *log.Logger) alert.Handler {
+	channelTemplate, err := alerttemplate.Parse("channel", c.Channel)
+	if err != nil {
+		l.Println("E! invalid discord channel template", err)
+	}
+	return &handler{s: s, c: c, channelTemplate: channelTemplate, logger: l}
+}
+
+func (h *handler) Handle(event alert.Event) {
+	title := h.c.Title
+	if title == "" {
+		title = event.State.Message
+	}
+
+	var tags map[string]string
+	if event.Data.Result.Series != nil && len(event.Data.Result.Series) > 0 {
+		tags = event.Data.Result.Series[0].Tags
+	}
+
+	channel, err := alerttemplate.Render(h.channelTemplate, h.c.Channel, alerttemplate.NewData(event))
+	if err != nil {
+		h.logger.Println("E! failed to render discord channel template", err)
+		return
+	}
+
+	opts := EmbedOptions{
+		TitleLink:   h.c.TitleLink,
+		AuthorName:  h.c.AuthorName,
+		AuthorLink:  h.c.AuthorLink,
+		FooterText:  h.c.FooterText,
+		FieldTitles: h.c.Fields,
+	}
+	if err := h.s.Alert(channel, title, event.State.Message, event.State.Level, tags, opts); err != nil {
+		h.logger.Println("E! failed to send event to Discord", err)
+	}
+}
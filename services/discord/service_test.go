@@ -0,0 +1,250 @@
+package discord
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/kapacitor/services/httpauth"
+	"github.com/influxdata/kapacitor/services/httpretry/httpretrytest"
+)
+
+func NewTestService(c Config) *Service {
+	return NewService(c, log.New(ioutil.Discard, "", 0))
+}
+
+func TestService_Alert_PostsEmbed(t *testing.T) {
+	var received webhookPayload
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Fatal(err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	c := Config{Enabled: true, URL: ts.URL, MentionUsers: []string{"123"}}
+	s := NewTestService(c)
+
+	if err := s.Alert("", "CPU high", "cpu usage above threshold", alert.Critical, map[string]string{"host": "serverA"}, EmbedOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, exp := received.Content, "<@123> "; got != exp {
+		t.Errorf("unexpected content got %s exp %s", got, exp)
+	}
+	if len(received.Embeds) != 1 {
+		t.Fatalf("expected a single embed, got %+v", received.Embeds)
+	}
+	e := received.Embeds[0]
+	if got, exp := e.Color, 0xFF0000; got != exp {
+		t.Errorf("unexpected color got %#x exp %#x", got, exp)
+	}
+	if got, exp := e.Title, "CPU high"; got != exp {
+		t.Errorf("unexpected title got %s exp %s", got, exp)
+	}
+	if len(e.Fields) != 1 || e.Fields[0].Name != "host" {
+		t.Fatalf("expected a tag-derived field for host, got %+v", e.Fields)
+	}
+}
+
+func TestService_Alert_EmbedOptionsPopulateRichAttachmentFields(t *testing.T) {
+	var received webhookPayload
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	c := Config{Enabled: true, URL: ts.URL}
+	s := NewTestService(c)
+
+	opts := EmbedOptions{
+		TitleLink:  "https://dashboard.example.com/cpu",
+		AuthorName: "kapacitor",
+		AuthorLink: "https://kapacitor.example.com",
+		FooterText: "prod cluster",
+		FieldTitles: map[string]string{
+			"host": "Host",
+		},
+	}
+	if err := s.Alert("", "CPU high", "cpu usage above threshold", alert.Critical, map[string]string{"host": "serverA"}, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(received.Embeds) != 1 {
+		t.Fatalf("expected a single embed, got %+v", received.Embeds)
+	}
+	e := received.Embeds[0]
+	if e.URL != opts.TitleLink {
+		t.Errorf("unexpected title link got %s exp %s", e.URL, opts.TitleLink)
+	}
+	if e.Author == nil || e.Author.Name != "kapacitor" || e.Author.URL != opts.AuthorLink {
+		t.Errorf("unexpected author got %+v", e.Author)
+	}
+	if e.Footer == nil || e.Footer.Text != "prod cluster" {
+		t.Errorf("unexpected footer got %+v", e.Footer)
+	}
+	if len(e.Fields) != 1 || e.Fields[0].Name != "Host" || e.Fields[0].Value != "serverA" {
+		t.Fatalf("expected the host tag to be rendered under the mapped field title, got %+v", e.Fields)
+	}
+}
+
+func TestService_Alert_ChannelOverrideUsesNamedWebhook(t *testing.T) {
+	var hitDefault, hitOps bool
+	defaultTs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hitDefault = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer defaultTs.Close()
+	opsTs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hitOps = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer opsTs.Close()
+
+	c := Config{
+		Enabled:  true,
+		URL:      defaultTs.URL,
+		Channels: map[string]string{"ops": opsTs.URL},
+	}
+	s := NewTestService(c)
+
+	if err := s.Alert("ops", "title", "message", alert.Warning, nil, EmbedOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !hitOps || hitDefault {
+		t.Fatalf("expected the ops channel webhook to be used, hitDefault=%v hitOps=%v", hitDefault, hitOps)
+	}
+}
+
+func TestService_Alert_NotEnabled(t *testing.T) {
+	s := NewTestService(Config{})
+	if err := s.Alert("", "t", "m", alert.Info, nil, EmbedOptions{}); err == nil {
+		t.Error("expected error when service is not enabled")
+	}
+}
+
+func TestService_Alert_RetriesTransientFailureThenSucceeds(t *testing.T) {
+	ts, calls := httpretrytest.FlakyServer(2, http.StatusServiceUnavailable, http.StatusNoContent)
+	defer ts.Close()
+
+	c := Config{
+		Enabled: true,
+		URL:     ts.URL,
+		Retry:   httpretrytest.Config(3),
+	}
+	s := NewTestService(c)
+
+	if err := s.Alert("", "t", "m", alert.Critical, nil, EmbedOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if *calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", *calls)
+	}
+}
+
+func TestService_Alert_DeadLettersOnFinalFailure(t *testing.T) {
+	ts := httpretrytest.AlwaysFailingServer(http.StatusServiceUnavailable)
+	defer ts.Close()
+
+	c := Config{
+		Enabled: true,
+		URL:     ts.URL,
+		Retry:   httpretrytest.Config(2),
+	}
+	s := NewTestService(c)
+
+	var deadLettered bool
+	s.DeadLetter = func(body []byte, lastErr error) error {
+		deadLettered = true
+		return nil
+	}
+
+	if err := s.Alert("", "t", "m", alert.Critical, nil, EmbedOptions{}); err == nil {
+		t.Fatal("expected the final failure to be returned as an error")
+	}
+	if !deadLettered {
+		t.Fatal("expected DeadLetter to be called after retries were exhausted")
+	}
+}
+
+func TestService_Alert_BearerAuthSetsAuthorizationHeader(t *testing.T) {
+	var gotAuth string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	c := Config{
+		Enabled: true,
+		URL:     ts.URL,
+		Auth:    httpauth.Config{Type: httpauth.TypeBearer, Token: "s3cr3t"},
+	}
+	s := NewTestService(c)
+
+	if err := s.Alert("", "t", "m", alert.Critical, nil, EmbedOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := gotAuth, "Bearer s3cr3t"; got != exp {
+		t.Errorf("unexpected Authorization header got %q exp %q", got, exp)
+	}
+}
+
+func TestService_Alert_FailsOnUntrustedTLSCertWithoutCAConfigured(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	c := Config{Enabled: true, URL: ts.URL}
+	s := NewTestService(c)
+
+	if err := s.Alert("", "t", "m", alert.Critical, nil, EmbedOptions{}); err == nil {
+		t.Fatal("expected certificate verification to fail against a self-signed server with no configured CA")
+	}
+}
+
+func TestHandler_Handle_ChannelTemplateRoutesByLevel(t *testing.T) {
+	var hit bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	channelKey := strings.ToLower(string(alert.Critical))
+	c := Config{
+		Enabled:  true,
+		Channels: map[string]string{channelKey: ts.URL},
+	}
+	s := NewTestService(c)
+
+	h := s.Handler(HandlerConfig{Channel: "{{ .Level | lower }}"}, log.New(ioutil.Discard, "", 0))
+	var event alert.Event
+	event.State.Level = alert.Critical
+	event.State.Message = "cpu usage above threshold"
+	h.Handle(event)
+
+	if !hit {
+		t.Fatal("expected the rendered channel's webhook to be hit")
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	c := Config{Enabled: true}
+	if err := c.Validate(); err == nil {
+		t.Error("expected error when no url or channels are configured")
+	}
+
+	c = Config{Enabled: true, URL: "http://example.com/webhook"}
+	if err := c.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,11 @@
+// Package grpc exposes the same template and task operations as the HTTP
+// client package over gRPC, with a server-streaming WatchTaskStats RPC so
+// subscribers receive incremental results with backpressure and deadline
+// propagation instead of polling the HTTP API.
+//
+// The generated message and service code lives in the rpc subpackage and
+// is produced from rpc/taskrpc.proto; run `go generate` after changing the
+// proto to regenerate it.
+package grpc
+
+//go:generate protoc -I rpc --go_out=rpc --go_opt=paths=source_relative --go-grpc_out=rpc --go-grpc_opt=paths=source_relative rpc/taskrpc.proto
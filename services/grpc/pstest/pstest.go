@@ -0,0 +1,51 @@
+// Package pstest provides an in-process fake TaskService gRPC server,
+// modeled after the pstest fakes used by Google Cloud client libraries, so
+// the existing TestServer_*Template* cases can be re-run against the gRPC
+// transport by swapping in this server's client connection instead of an
+// HTTP one.
+package pstest
+
+import (
+	"context"
+	"net"
+
+	kapacitorgrpc "github.com/influxdata/kapacitor/services/grpc"
+	"github.com/influxdata/kapacitor/services/grpc/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const bufSize = 1024 * 1024
+
+// Server is an in-process TaskService gRPC server backed by an in-memory
+// Store, dialed over a bufconn listener instead of a real socket.
+type Server struct {
+	GRPCServer *grpc.Server
+	listener   *bufconn.Listener
+}
+
+// NewServer starts an in-process server backed by store and returns it
+// along with a dialer suitable for grpc.DialContext's WithContextDialer.
+func NewServer(store kapacitorgrpc.Store) *Server {
+	lis := bufconn.Listen(bufSize)
+	gs := grpc.NewServer()
+	rpc.RegisterTaskServiceServer(gs, kapacitorgrpc.NewServer(store))
+
+	s := &Server{GRPCServer: gs, listener: lis}
+	go gs.Serve(lis)
+	return s
+}
+
+// Dialer returns a function suitable for grpc.WithContextDialer, connecting
+// to this in-process server.
+func (s *Server) Dialer() func(context.Context, string) (net.Conn, error) {
+	return func(ctx context.Context, _ string) (net.Conn, error) {
+		return s.listener.DialContext(ctx)
+	}
+}
+
+// Close stops the server and closes its listener.
+func (s *Server) Close() {
+	s.GRPCServer.Stop()
+	s.listener.Close()
+}
@@ -0,0 +1,147 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/influxdata/kapacitor/services/grpc/rpc"
+)
+
+// Server implements rpc.TaskServiceServer against a Store, translating
+// between the store's native types and the generated protobuf messages.
+type Server struct {
+	rpc.UnimplementedTaskServiceServer
+
+	Store Store
+}
+
+func NewServer(store Store) *Server {
+	return &Server{Store: store}
+}
+
+func (s *Server) CreateTemplate(ctx context.Context, req *rpc.CreateTemplateRequest) (*rpc.Template, error) {
+	t, err := s.Store.CreateTemplate(req.Id, req.Type, req.Tickscript)
+	if err != nil {
+		return nil, err
+	}
+	return templateToRPC(t), nil
+}
+
+func (s *Server) UpdateTemplate(ctx context.Context, req *rpc.UpdateTemplateRequest) (*rpc.Template, error) {
+	t, err := s.Store.UpdateTemplate(req.Id, req.Tickscript)
+	if err != nil {
+		return nil, err
+	}
+	return templateToRPC(t), nil
+}
+
+func (s *Server) DeleteTemplate(ctx context.Context, req *rpc.DeleteTemplateRequest) (*rpc.DeleteTemplateResponse, error) {
+	if err := s.Store.DeleteTemplate(req.Id); err != nil {
+		return nil, err
+	}
+	return &rpc.DeleteTemplateResponse{}, nil
+}
+
+func (s *Server) CreateTask(ctx context.Context, req *rpc.CreateTaskRequest) (*rpc.Task, error) {
+	task, err := s.Store.CreateTask(req.Id, req.TemplateId, req.Type, dbrpsFromRPC(req.Dbrps), req.Status)
+	if err != nil {
+		return nil, err
+	}
+	return taskToRPC(task), nil
+}
+
+func (s *Server) UpdateTask(ctx context.Context, req *rpc.UpdateTaskRequest) (*rpc.Task, error) {
+	task, err := s.Store.UpdateTask(req.Id, req.Status)
+	if err != nil {
+		return nil, err
+	}
+	return taskToRPC(task), nil
+}
+
+func (s *Server) Task(ctx context.Context, req *rpc.TaskRequest) (*rpc.Task, error) {
+	task, err := s.Store.Task(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return taskToRPC(task), nil
+}
+
+func (s *Server) ListTasks(ctx context.Context, req *rpc.ListTasksRequest) (*rpc.ListTasksResponse, error) {
+	tasks, err := s.Store.ListTasks(req.TemplateId)
+	if err != nil {
+		return nil, err
+	}
+	resp := &rpc.ListTasksResponse{Tasks: make([]*rpc.Task, len(tasks))}
+	for i, task := range tasks {
+		resp.Tasks[i] = taskToRPC(task)
+	}
+	return resp, nil
+}
+
+func (s *Server) WatchTaskStats(req *rpc.WatchTaskStatsRequest, stream rpc.TaskService_WatchTaskStatsServer) error {
+	ctx := stream.Context()
+	stop := make(chan struct{})
+	defer close(stop)
+
+	stats, err := s.Store.WatchTaskStats(req.Id, stop)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case st, ok := <-stats:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(&rpc.TaskStats{
+				Id:              st.ID,
+				PointsProcessed: st.PointsProcessed,
+				Errors:          st.Errors,
+				ExecLatencyNs:   int64(st.ExecLatency),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func templateToRPC(t TemplateInfo) *rpc.Template {
+	out := &rpc.Template{
+		Id:         t.ID,
+		Type:       t.Type,
+		Tickscript: t.TICKscript,
+	}
+	if t.Error != "" {
+		out.Error = []string{t.Error}
+	}
+	return out
+}
+
+func taskToRPC(t TaskInfo) *rpc.Task {
+	return &rpc.Task{
+		Id:         t.ID,
+		TemplateId: t.TemplateID,
+		Type:       t.Type,
+		Dbrps:      dbrpsToRPC(t.DBRPs),
+		Tickscript: t.TICKscript,
+		Status:     t.Status,
+	}
+}
+
+func dbrpsToRPC(dbrps []DBRP) []*rpc.DBRP {
+	out := make([]*rpc.DBRP, len(dbrps))
+	for i, d := range dbrps {
+		out[i] = &rpc.DBRP{Db: d.Database, Rp: d.RetentionPolicy}
+	}
+	return out
+}
+
+func dbrpsFromRPC(dbrps []*rpc.DBRP) []DBRP {
+	out := make([]DBRP, len(dbrps))
+	for i, d := range dbrps {
+		out[i] = DBRP{Database: d.Db, RetentionPolicy: d.Rp}
+	}
+	return out
+}
@@ -0,0 +1,117 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/kapacitor/services/grpc/rpc"
+)
+
+type memStore struct {
+	templates map[string]TemplateInfo
+	tasks     map[string]TaskInfo
+}
+
+func newMemStore() *memStore {
+	return &memStore{
+		templates: make(map[string]TemplateInfo),
+		tasks:     make(map[string]TaskInfo),
+	}
+}
+
+func (m *memStore) CreateTemplate(id, typ, tickscript string) (TemplateInfo, error) {
+	t := TemplateInfo{ID: id, Type: typ, TICKscript: tickscript}
+	m.templates[id] = t
+	return t, nil
+}
+
+func (m *memStore) UpdateTemplate(id, tickscript string) (TemplateInfo, error) {
+	t := m.templates[id]
+	t.TICKscript = tickscript
+	m.templates[id] = t
+	return t, nil
+}
+
+func (m *memStore) DeleteTemplate(id string) error {
+	delete(m.templates, id)
+	return nil
+}
+
+func (m *memStore) CreateTask(id, templateID, typ string, dbrps []DBRP, status string) (TaskInfo, error) {
+	task := TaskInfo{ID: id, TemplateID: templateID, Type: typ, DBRPs: dbrps, Status: status}
+	m.tasks[id] = task
+	return task, nil
+}
+
+func (m *memStore) UpdateTask(id, status string) (TaskInfo, error) {
+	task := m.tasks[id]
+	task.Status = status
+	m.tasks[id] = task
+	return task, nil
+}
+
+func (m *memStore) Task(id string) (TaskInfo, error) {
+	return m.tasks[id], nil
+}
+
+func (m *memStore) ListTasks(templateID string) ([]TaskInfo, error) {
+	var out []TaskInfo
+	for _, task := range m.tasks {
+		if templateID == "" || task.TemplateID == templateID {
+			out = append(out, task)
+		}
+	}
+	return out, nil
+}
+
+func (m *memStore) WatchTaskStats(id string, stop <-chan struct{}) (<-chan TaskStatsInfo, error) {
+	ch := make(chan TaskStatsInfo)
+	go func() {
+		<-stop
+		close(ch)
+	}()
+	return ch, nil
+}
+
+func TestServer_CreateUpdateListTasks(t *testing.T) {
+	store := newMemStore()
+	s := NewServer(store)
+	ctx := context.Background()
+
+	if _, err := s.CreateTemplate(ctx, &rpc.CreateTemplateRequest{
+		Id:         "testTemplateID",
+		Type:       "stream",
+		Tickscript: "stream\n    |from()\n",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	task, err := s.CreateTask(ctx, &rpc.CreateTaskRequest{
+		Id:         "testTaskID",
+		TemplateId: "testTemplateID",
+		Dbrps:      []*rpc.DBRP{{Db: "mydb", Rp: "myrp"}},
+		Status:     "enabled",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Status != "enabled" {
+		t.Fatalf("unexpected status got %s exp enabled", task.Status)
+	}
+
+	updated, err := s.UpdateTask(ctx, &rpc.UpdateTaskRequest{Id: "testTaskID", Status: "disabled"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Status != "disabled" {
+		t.Fatalf("unexpected status got %s exp disabled", updated.Status)
+	}
+
+	list, err := s.ListTasks(ctx, &rpc.ListTasksRequest{TemplateId: "testTemplateID"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Tasks) != 1 {
+		t.Fatalf("unexpected number of tasks got %d exp 1", len(list.Tasks))
+	}
+}
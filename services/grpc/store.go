@@ -0,0 +1,53 @@
+package grpc
+
+import "time"
+
+// DBRP identifies a database and retention policy pair a task is bound to.
+type DBRP struct {
+	Database        string
+	RetentionPolicy string
+}
+
+// TemplateInfo is the store's view of a template.
+type TemplateInfo struct {
+	ID         string
+	Type       string
+	TICKscript string
+	Error      string
+}
+
+// TaskInfo is the store's view of a task.
+type TaskInfo struct {
+	ID         string
+	TemplateID string
+	Type       string
+	DBRPs      []DBRP
+	TICKscript string
+	Status     string
+}
+
+// TaskStatsInfo is a point-in-time snapshot of a task's runtime counters.
+type TaskStatsInfo struct {
+	ID              string
+	PointsProcessed int64
+	Errors          int64
+	ExecLatency     time.Duration
+}
+
+// Store is the subset of the task store this service needs, satisfied by
+// the server's task and template stores.
+type Store interface {
+	CreateTemplate(id, typ, tickscript string) (TemplateInfo, error)
+	UpdateTemplate(id, tickscript string) (TemplateInfo, error)
+	DeleteTemplate(id string) error
+
+	CreateTask(id, templateID, typ string, dbrps []DBRP, status string) (TaskInfo, error)
+	UpdateTask(id, status string) (TaskInfo, error)
+	Task(id string) (TaskInfo, error)
+	ListTasks(templateID string) ([]TaskInfo, error)
+
+	// WatchTaskStats returns a channel that receives a TaskStatsInfo
+	// whenever the named task's runtime counters change. The channel is
+	// closed when stop is closed.
+	WatchTaskStats(id string, stop <-chan struct{}) (<-chan TaskStatsInfo, error)
+}
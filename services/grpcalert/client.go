@@ -0,0 +1,225 @@
+package grpcalert
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/kapacitor/services/grpcalert/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+
+	// keepaliveTime and keepaliveTimeout keep the single shared
+	// ClientConn alive across alerts, so a burst of events after a long
+	// idle period doesn't pay a fresh dial's connection-setup cost.
+	keepaliveTime    = 30 * time.Second
+	keepaliveTimeout = 10 * time.Second
+
+	maxRetries = 3
+)
+
+// Service maintains a single *grpc.ClientConn to the configured external
+// handler, reused across alerts rather than dialed per-event.
+type Service struct {
+	mu     sync.Mutex
+	c      Config
+	conn   *grpc.ClientConn
+	client rpc.AlertServiceClient
+
+	// dialOpts are appended to every Dial, letting tests substitute a
+	// bufconn dialer in place of a real network connection.
+	dialOpts []grpc.DialOption
+
+	logger *log.Logger
+}
+
+func NewService(c Config, l *log.Logger) *Service {
+	return &Service{c: c, logger: l}
+}
+
+func (s *Service) Open() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dialLocked()
+}
+
+func (s *Service) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closeLocked()
+}
+
+func (s *Service) Update(newConfig []interface{}) error {
+	if l := len(newConfig); l != 1 {
+		return fmt.Errorf("expected only one new config object, got %d", l)
+	}
+	c, ok := newConfig[0].(Config)
+	if !ok {
+		return fmt.Errorf("expected config object to be of type %T, got %T", c, newConfig[0])
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.closeLocked(); err != nil {
+		return err
+	}
+	s.c = c
+	if !c.Enabled {
+		return nil
+	}
+	return s.dialLocked()
+}
+
+func (s *Service) closeLocked() error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	s.client = nil
+	return err
+}
+
+func (s *Service) dialLocked() error {
+	if !s.c.Enabled || s.c.Address == "" {
+		return nil
+	}
+
+	tc, err := s.c.TLS.tlsConfig()
+	if err != nil {
+		return err
+	}
+	creds := insecure.NewCredentials()
+	if tc != nil {
+		creds = credentials.NewTLS(tc)
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    keepaliveTime,
+			Timeout: keepaliveTimeout,
+		}),
+	}
+	if s.c.MaxMessageSize > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(s.c.MaxMessageSize),
+			grpc.MaxCallSendMsgSize(s.c.MaxMessageSize),
+		))
+	}
+	opts = append(opts, s.dialOpts...)
+
+	conn, err := grpc.Dial(s.c.Address, opts...)
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+	s.client = rpc.NewAlertServiceClient(conn)
+	return nil
+}
+
+func (s *Service) clientAndTimeout() (rpc.AlertServiceClient, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.c.Enabled {
+		return nil, 0, fmt.Errorf("service is not enabled")
+	}
+	if s.client == nil {
+		return nil, 0, fmt.Errorf("no connection to grpc alert handler at %q", s.c.Address)
+	}
+	return s.client, time.Duration(s.c.Timeout), nil
+}
+
+type testOptions struct {
+	Message string      `json:"message"`
+	Level   alert.Level `json:"level"`
+}
+
+func (s *Service) TestOptions() interface{} {
+	return &testOptions{
+		Message: "test grpcalert message",
+		Level:   alert.Warning,
+	}
+}
+
+func (s *Service) Test(options interface{}) error {
+	o, ok := options.(*testOptions)
+	if !ok {
+		return fmt.Errorf("unexpected options type %t", options)
+	}
+	return s.Alert(o.Message, o.Level, nil)
+}
+
+// Alert sends a single event to the external handler via HandleAlert,
+// retrying a handful of times when the RPC fails with codes.Unavailable,
+// since that's the status the keepalive-managed ClientConn surfaces
+// while it's re-establishing a dropped connection.
+func (s *Service) Alert(message string, level alert.Level, tags map[string]string) error {
+	client, timeout, err := s.clientAndTimeout()
+	if err != nil {
+		return err
+	}
+
+	data := &rpc.AlertData{
+		Message:      message,
+		Level:        level.String(),
+		TimeUnixNano: time.Now().UnixNano(),
+		Tags:         tags,
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		_, err := client.HandleAlert(ctx, data)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if status.Code(err) != codes.Unavailable {
+			return err
+		}
+	}
+	return fmt.Errorf("grpc alert handler unavailable after %d attempts: %v", maxRetries, lastErr)
+}
+
+// HandlerConfig is the per-alert-handler configuration for the grpc node
+// in a TICKscript. The handler kind has no per-node options of its own;
+// everything is configured at the service level since a TLS-secured
+// ClientConn is expensive to keep per-node.
+type HandlerConfig struct{}
+
+type handler struct {
+	s      *Service
+	logger *log.Logger
+}
+
+func (s *Service) DefaultHandlerConfig() HandlerConfig {
+	return HandlerConfig{}
+}
+
+func (s *Service) Handler(c HandlerConfig, l *log.Logger) alert.Handler {
+	return &handler{s: s, logger: l}
+}
+
+func (h *handler) Handle(event alert.Event) {
+	var tags map[string]string
+	if event.Data.Result.Series != nil && len(event.Data.Result.Series) > 0 {
+		tags = event.Data.Result.Series[0].Tags
+	}
+
+	if err := h.s.Alert(event.State.Message, event.State.Level, tags); err != nil {
+		h.logger.Println("E! failed to send event to grpc alert handler", err)
+	}
+}
@@ -0,0 +1,135 @@
+package grpcalert
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/kapacitor/services/grpcalert/grpcalerttest"
+	"github.com/influxdata/kapacitor/services/grpcalert/rpc"
+	"github.com/influxdata/toml"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func newTestService(t *testing.T, dialer func(context.Context, string) (net.Conn, error)) *Service {
+	t.Helper()
+	s := NewService(Config{Enabled: true, Address: "bufnet", Timeout: toml.Duration(5 * time.Second)}, log.New(ioutil.Discard, "", 0))
+	s.dialOpts = []grpc.DialOption{
+		grpc.WithContextDialer(dialer),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestService_Alert_DeliversToServer(t *testing.T) {
+	srv := grpcalerttest.NewServer(nil,
+		grpc.UnaryInterceptor(UnaryPanicRecoveryInterceptor(log.New(ioutil.Discard, "", 0))))
+	defer srv.Close()
+
+	s := newTestService(t, srv.Dialer())
+	if err := s.Alert("cpu high", alert.Critical, map[string]string{"host": "serverA"}); err != nil {
+		t.Fatal(err)
+	}
+
+	received := srv.Received()
+	if len(received) != 1 || received[0].Message != "cpu high" {
+		t.Fatalf("expected the message to be delivered, got %+v", received)
+	}
+	if received[0].Tags["host"] != "serverA" {
+		t.Fatalf("expected tags to be delivered, got %+v", received[0].Tags)
+	}
+}
+
+func TestService_Alert_PanicInHandlerIsRecoveredAsInternalError(t *testing.T) {
+	srv := grpcalerttest.NewServer(
+		func(*rpc.AlertData) error { panic("boom") },
+		grpc.UnaryInterceptor(UnaryPanicRecoveryInterceptor(log.New(ioutil.Discard, "", 0))))
+	defer srv.Close()
+
+	s := newTestService(t, srv.Dialer())
+	err := s.Alert("cpu high", alert.Critical, nil)
+	if err == nil {
+		t.Fatal("expected an error from the panicking handler")
+	}
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("expected codes.Internal, got %v", status.Code(err))
+	}
+}
+
+func TestService_Alert_RetriesOnUnavailable(t *testing.T) {
+	var calls int64
+	srv := grpcalerttest.NewServer(func(*rpc.AlertData) error {
+		if atomic.AddInt64(&calls, 1) == 1 {
+			return status.Error(codes.Unavailable, "temporarily down")
+		}
+		return nil
+	})
+	defer srv.Close()
+
+	s := newTestService(t, srv.Dialer())
+	if err := s.Alert("cpu high", alert.Critical, nil); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt64(&calls) != 2 {
+		t.Fatalf("expected a retry after the first Unavailable response, got %d calls", calls)
+	}
+}
+
+func TestService_Alert_GivesUpAfterMaxRetries(t *testing.T) {
+	srv := grpcalerttest.NewServer(func(*rpc.AlertData) error {
+		return status.Error(codes.Unavailable, "down")
+	})
+	defer srv.Close()
+
+	s := newTestService(t, srv.Dialer())
+	err := s.Alert("cpu high", alert.Critical, nil)
+	if err == nil {
+		t.Fatal("expected an error after exhausting retries")
+	}
+}
+
+func TestService_Alert_NonUnavailableErrorIsNotRetried(t *testing.T) {
+	var calls int64
+	srv := grpcalerttest.NewServer(func(*rpc.AlertData) error {
+		atomic.AddInt64(&calls, 1)
+		return status.Error(codes.InvalidArgument, "bad request")
+	})
+	defer srv.Close()
+
+	s := newTestService(t, srv.Dialer())
+	if err := s.Alert("cpu high", alert.Critical, nil); err == nil {
+		t.Fatal("expected an error")
+	}
+	if atomic.LoadInt64(&calls) != 1 {
+		t.Fatalf("expected exactly one call for a non-retryable error, got %d", calls)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	c := Config{Enabled: true}
+	if err := c.Validate(); err == nil {
+		t.Error("expected error when no address is configured")
+	}
+
+	c = Config{Enabled: true, Address: "alerts.internal:9090"}
+	if err := c.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	c = Config{Enabled: true, Address: "alerts.internal:9090", MaxMessageSize: -1}
+	if err := c.Validate(); err == nil {
+		t.Error("expected error for a negative max-message-size")
+	}
+}
@@ -0,0 +1,91 @@
+package grpcalert
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+
+	"github.com/influxdata/toml"
+)
+
+// TLSConfig configures a TLS connection to the external handler.
+type TLSConfig struct {
+	CertFile           string `toml:"cert" override:"cert"`
+	KeyFile            string `toml:"key" override:"key"`
+	CAFile             string `toml:"ca" override:"ca"`
+	ServerName         string `toml:"server-name" override:"server-name"`
+	InsecureSkipVerify bool   `toml:"insecure-skip-verify" override:"insecure-skip-verify"`
+}
+
+func (c TLSConfig) isZero() bool {
+	return c == TLSConfig{}
+}
+
+// tlsConfig builds a *tls.Config from c, loading the client certificate
+// and CA pool if configured. It returns nil if c is the zero value, since
+// a nil *tls.Config tells the dialer to use a plaintext connection.
+func (c TLSConfig) tlsConfig() (*tls.Config, error) {
+	if c.isZero() {
+		return nil, nil
+	}
+	tc := &tls.Config{
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+	if c.CAFile != "" {
+		pem, err := ioutil.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("grpcalert: failed to parse ca file")
+		}
+		tc.RootCAs = pool
+	}
+	return tc, nil
+}
+
+// Config is the grpcalert service section.
+type Config struct {
+	// Whether the grpc alert handler is enabled.
+	Enabled bool `toml:"enabled" override:"enabled"`
+	// Whether this section is used as the default handler for all alerts.
+	Global bool `toml:"global" override:"global"`
+	// Only send an event when the alert state changes.
+	StateChangesOnly bool `toml:"state-changes-only" override:"state-changes-only"`
+	// Address of the external AlertService, e.g. "alerts.internal:9090".
+	Address string `toml:"address" override:"address"`
+	// TLS configures a secure connection to Address; the zero value dials
+	// plaintext.
+	TLS TLSConfig `toml:"tls" override:"tls"`
+	// Timeout bounds a single HandleAlert call.
+	Timeout toml.Duration `toml:"timeout" override:"timeout"`
+	// MaxMessageSize caps the size in bytes of a single gRPC message,
+	// both sent and received. Zero uses grpc's default.
+	MaxMessageSize int `toml:"max-message-size" override:"max-message-size"`
+}
+
+func NewConfig() Config {
+	return Config{
+		Timeout: toml.Duration(defaultTimeout),
+	}
+}
+
+func (c Config) Validate() error {
+	if c.Enabled && c.Address == "" {
+		return errors.New("grpcalert: must specify address")
+	}
+	if c.MaxMessageSize < 0 {
+		return errors.New("grpcalert: max-message-size must not be negative")
+	}
+	return nil
+}
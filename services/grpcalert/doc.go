@@ -0,0 +1,11 @@
+// Package grpcalert implements the "grpc" alert handler kind, streaming
+// alert events to a user-supplied gRPC service over a single long-lived
+// ClientConn rather than dialing per-event, the way the exec handler
+// shells out to a long-lived process rather than forking one per alert.
+//
+// The generated message and service code lives in the rpc subpackage and
+// is produced from rpc/alertservice.proto; run `go generate` after
+// changing the proto to regenerate it.
+package grpcalert
+
+//go:generate protoc -I rpc --go_out=rpc --go_opt=paths=source_relative --go-grpc_out=rpc --go-grpc_opt=paths=source_relative rpc/alertservice.proto
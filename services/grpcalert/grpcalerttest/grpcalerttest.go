@@ -0,0 +1,82 @@
+// Package grpcalerttest provides an in-process fake AlertService gRPC
+// server, dialed over a bufconn listener instead of a real socket, so
+// grpcalert's client can be exercised without a network dependency.
+package grpcalerttest
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"github.com/influxdata/kapacitor/services/grpcalert/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const bufSize = 1024 * 1024
+
+// Handler receives decoded AlertData from HandleAlert. A panic inside it
+// is expected to be translated into a codes.Internal error by the
+// server's recovery interceptor, not to crash the test process.
+type Handler func(*rpc.AlertData) error
+
+// Server is an in-process AlertService gRPC server backed by a Handler.
+type Server struct {
+	rpc.UnimplementedAlertServiceServer
+
+	GRPCServer *grpc.Server
+	listener   *bufconn.Listener
+
+	mu       sync.Mutex
+	handler  Handler
+	received []*rpc.AlertData
+}
+
+// NewServer starts an in-process server invoking handler for every
+// HandleAlert call, wrapped in opts (typically the recovery and counter
+// interceptors).
+func NewServer(handler Handler, opts ...grpc.ServerOption) *Server {
+	lis := bufconn.Listen(bufSize)
+	gs := grpc.NewServer(opts...)
+	s := &Server{GRPCServer: gs, listener: lis, handler: handler}
+	rpc.RegisterAlertServiceServer(gs, s)
+	go gs.Serve(lis)
+	return s
+}
+
+func (s *Server) HandleAlert(ctx context.Context, data *rpc.AlertData) (*rpc.HandleAlertResponse, error) {
+	s.mu.Lock()
+	s.received = append(s.received, data)
+	handler := s.handler
+	s.mu.Unlock()
+
+	if handler != nil {
+		if err := handler(data); err != nil {
+			return nil, err
+		}
+	}
+	return &rpc.HandleAlertResponse{}, nil
+}
+
+// Received returns every AlertData delivered so far.
+func (s *Server) Received() []*rpc.AlertData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*rpc.AlertData, len(s.received))
+	copy(out, s.received)
+	return out
+}
+
+// Dialer returns a function suitable for grpc.WithContextDialer,
+// connecting to this in-process server.
+func (s *Server) Dialer() func(context.Context, string) (net.Conn, error) {
+	return func(ctx context.Context, _ string) (net.Conn, error) {
+		return s.listener.DialContext(ctx)
+	}
+}
+
+// Close stops the server and closes its listener.
+func (s *Server) Close() {
+	s.GRPCServer.Stop()
+	s.listener.Close()
+}
@@ -0,0 +1,63 @@
+package grpcalert
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryPanicRecoveryInterceptor recovers a panic raised by user handler
+// code inside a unary RPC and turns it into a codes.Internal error, so a
+// crashing external handler's gRPC server cannot take down the process
+// hosting it.
+func UnaryPanicRecoveryInterceptor(l *log.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				l.Printf("E! recovered from panic in %s: %v", info.FullMethod, r)
+				err = status.Errorf(codes.Internal, "panic in %s: %v", info.FullMethod, r)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamPanicRecoveryInterceptor is UnaryPanicRecoveryInterceptor's
+// server-streaming counterpart.
+func StreamPanicRecoveryInterceptor(l *log.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				l.Printf("E! recovered from panic in %s: %v", info.FullMethod, r)
+				err = status.Errorf(codes.Internal, "panic in %s: %v", info.FullMethod, r)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// StreamCounter tracks how many streaming RPCs are open at once, so it
+// can be published the same way the UDF supervisor publishes its restart
+// counters via expvar.
+type StreamCounter struct {
+	active int64
+	total  int64
+}
+
+func (c *StreamCounter) Active() int64 { return atomic.LoadInt64(&c.active) }
+func (c *StreamCounter) Total() int64  { return atomic.LoadInt64(&c.total) }
+
+// StreamCounterInterceptor increments c for the duration of every
+// streaming RPC, so metrics stay accurate even when a handler panics.
+func StreamCounterInterceptor(c *StreamCounter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		atomic.AddInt64(&c.active, 1)
+		atomic.AddInt64(&c.total, 1)
+		defer atomic.AddInt64(&c.active, -1)
+		return handler(srv, ss)
+	}
+}
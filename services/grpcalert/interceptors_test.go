@@ -0,0 +1,93 @@
+package grpcalert
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryPanicRecoveryInterceptor_TranslatesPanicToInternal(t *testing.T) {
+	interceptor := UnaryPanicRecoveryInterceptor(log.New(ioutil.Discard, "", 0))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("boom")
+	}
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/rpc.AlertService/HandleAlert"}, handler)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("expected codes.Internal, got %v", status.Code(err))
+	}
+}
+
+func TestUnaryPanicRecoveryInterceptor_PassesThroughOnSuccess(t *testing.T) {
+	interceptor := UnaryPanicRecoveryInterceptor(log.New(ioutil.Discard, "", 0))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	resp, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, handler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp != "ok" {
+		t.Fatalf("expected the handler's response to pass through, got %v", resp)
+	}
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+}
+
+func (fakeServerStream) Context() context.Context { return context.Background() }
+
+func TestStreamPanicRecoveryInterceptor_TranslatesPanicToInternal(t *testing.T) {
+	interceptor := StreamPanicRecoveryInterceptor(log.New(ioutil.Discard, "", 0))
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		panic("boom")
+	}
+
+	err := interceptor(nil, fakeServerStream{}, &grpc.StreamServerInfo{FullMethod: "/rpc.AlertService/WatchAlerts"}, handler)
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("expected codes.Internal, got %v", status.Code(err))
+	}
+}
+
+func TestStreamCounterInterceptor_TracksActiveAndTotal(t *testing.T) {
+	counter := &StreamCounter{}
+	interceptor := StreamCounterInterceptor(counter)
+
+	inside := make(chan struct{})
+	release := make(chan struct{})
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		close(inside)
+		<-release
+		return nil
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- interceptor(nil, fakeServerStream{}, &grpc.StreamServerInfo{}, handler)
+	}()
+
+	<-inside
+	if counter.Active() != 1 {
+		t.Fatalf("expected one active stream, got %d", counter.Active())
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if counter.Active() != 0 {
+		t.Fatalf("expected no active streams after completion, got %d", counter.Active())
+	}
+	if counter.Total() != 1 {
+		t.Fatalf("expected total to record the completed stream, got %d", counter.Total())
+	}
+}
@@ -0,0 +1,214 @@
+// Package handlerchain turns a flat list of alert handler actions into a
+// sequential pipeline: each step can be skipped based on a condition
+// evaluated against the event (and the previous step's outcome), can
+// rewrite the message seen by later steps, can short-circuit the
+// remaining steps, or can fan out into parallel sub-chains.
+//
+// This tree has no client.HandlerOptions/HandlerAction types to extend
+// directly, so Chain is a standalone package: build a Chain from Steps
+// and it implements alert.Handler itself, so it can be plugged in
+// wherever a single handler is expected today.
+package handlerchain
+
+import (
+	"bytes"
+	"log"
+	"sync"
+	"text/template"
+
+	"github.com/influxdata/kapacitor/alert"
+)
+
+// State is the mutable view of an event threaded through a Chain's
+// steps. Message and Details start out from the triggering event and can
+// be rewritten by a Step's Transform for steps that run after it.
+// PreviousError is nil until a step's Action returns an error, letting a
+// later step's When condition react to it.
+type State struct {
+	Level         alert.Level
+	Message       string
+	Details       string
+	Tags          map[string]string
+	PreviousError error
+}
+
+// Action is invoked for a step that isn't skipped. It mirrors the error
+// return of the per-kind Alert methods (discord.Service.Alert and
+// similar) rather than alert.Handler's Handle, since Handle is
+// fire-and-forget and a step here needs to know whether it succeeded.
+type Action func(State) error
+
+// Transform rewrites State for steps that run after the current one.
+// An empty template leaves the corresponding field unchanged.
+type Transform struct {
+	MessageTemplate string
+	DetailsTemplate string
+}
+
+// apply renders t's templates against s and returns the rewritten state.
+func (t Transform) apply(s State) (State, error) {
+	if t.MessageTemplate != "" {
+		msg, err := render(t.MessageTemplate, s)
+		if err != nil {
+			return s, err
+		}
+		s.Message = msg
+	}
+	if t.DetailsTemplate != "" {
+		details, err := render(t.DetailsTemplate, s)
+		if err != nil {
+			return s, err
+		}
+		s.Details = details
+	}
+	return s, nil
+}
+
+func render(tmplText string, s State) (string, error) {
+	tmpl, err := template.New("handlerchain").Parse(tmplText)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, s); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Step is one entry in a Chain.
+type Step struct {
+	// Name identifies the step in log messages.
+	Name string
+	// When, if set, is a template rendered against State; the step runs
+	// only if it renders to exactly "true". An empty When always runs.
+	When string
+	// Action is invoked when the step isn't skipped. Exactly one of
+	// Action or Fanout should be set.
+	Action Action
+	// Fanout, if set, runs each of its steps against its own copy of the
+	// current State, concurrently. The main chain's PreviousError is set
+	// to the first sub-step error encountered, in Fanout order, once all
+	// sub-steps finish.
+	Fanout []Step
+	// Transform, if set, rewrites State for steps after this one.
+	Transform *Transform
+	// Stop, if true, ends the chain after this step runs (a step that
+	// was skipped by When never stops the chain).
+	Stop bool
+}
+
+// Chain is a sequence of Steps. A Chain implements alert.Handler, so it
+// can be used as a single handler in this tree's existing handler
+// infrastructure.
+type Chain struct {
+	Steps  []Step
+	logger *log.Logger
+}
+
+// NewChain returns a Chain running steps in order against events it
+// handles.
+func NewChain(steps []Step, l *log.Logger) *Chain {
+	return &Chain{Steps: steps, logger: l}
+}
+
+// Handle implements alert.Handler by building the initial State from
+// event and running the chain, logging the final step's error if any.
+func (c *Chain) Handle(event alert.Event) {
+	var tags map[string]string
+	if event.Data.Result.Series != nil && len(event.Data.Result.Series) > 0 {
+		tags = event.Data.Result.Series[0].Tags
+	}
+	s := State{
+		Level:   event.State.Level,
+		Message: event.State.Message,
+		Tags:    tags,
+	}
+	if err := c.Run(s); err != nil {
+		c.logger.Println("E! handlerchain step failed", err)
+	}
+}
+
+// Run executes the chain starting from s, stopping early after a step
+// that has Stop set and was not skipped, and returns the error of the
+// last step it reached (nil when When skipped that step). A step's
+// error does not by itself halt the chain; only Stop does, so that
+// later steps can still react to PreviousError (e.g. "retry on slack
+// if the primary handler failed").
+func (c *Chain) Run(s State) error {
+	var err error
+	for i := range c.Steps {
+		step := c.Steps[i]
+		stop := false
+		if step.Stop {
+			stop, _ = c.shouldRun(step, s)
+		}
+		s, err = c.runStep(step, s)
+		if stop {
+			break
+		}
+	}
+	return err
+}
+
+func (c *Chain) runStep(step Step, s State) (State, error) {
+	run, err := c.shouldRun(step, s)
+	if err != nil {
+		return s, err
+	}
+	if !run {
+		return s, nil
+	}
+
+	var stepErr error
+	switch {
+	case step.Fanout != nil:
+		stepErr = c.runFanout(step.Fanout, s)
+	case step.Action != nil:
+		stepErr = step.Action(s)
+	}
+	s.PreviousError = stepErr
+
+	if step.Transform != nil {
+		s, err = step.Transform.apply(s)
+		if err != nil {
+			return s, err
+		}
+	}
+
+	return s, stepErr
+}
+
+func (c *Chain) shouldRun(step Step, s State) (bool, error) {
+	if step.When == "" {
+		return true, nil
+	}
+	rendered, err := render(step.When, s)
+	if err != nil {
+		return false, err
+	}
+	return rendered == "true", nil
+}
+
+// runFanout runs each sub-step concurrently against its own copy of s,
+// returning the first non-nil error in sub-step order.
+func (c *Chain) runFanout(steps []Step, s State) error {
+	errs := make([]error, len(steps))
+
+	var wg sync.WaitGroup
+	for i := range steps {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = c.runStep(steps[i], s)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package handlerchain
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestChain_WhenFalseSkipsStep(t *testing.T) {
+	var ran bool
+	steps := []Step{
+		{
+			Name:   "post",
+			When:   "false",
+			Action: func(s State) error { ran = true; return nil },
+		},
+	}
+	c := NewChain(steps, nil)
+	if err := c.Run(State{}); err != nil {
+		t.Fatal(err)
+	}
+	if ran {
+		t.Fatal("expected the step to be skipped")
+	}
+}
+
+func TestChain_SecondStepOnlyFiresWhenFirstStepFailed(t *testing.T) {
+	var slackRan bool
+	steps := []Step{
+		{
+			Name:   "post",
+			Action: func(s State) error { return fmt.Errorf("non-2xx response") },
+		},
+		{
+			Name:   "slack",
+			When:   "{{if .PreviousError}}true{{else}}false{{end}}",
+			Action: func(s State) error { slackRan = true; return nil },
+		},
+	}
+	c := NewChain(steps, nil)
+	if err := c.Run(State{}); err != nil {
+		t.Fatal(err)
+	}
+	if !slackRan {
+		t.Fatal("expected the slack step to fire after the post step failed")
+	}
+}
+
+func TestChain_SecondStepSkippedWhenFirstStepSucceeded(t *testing.T) {
+	var slackRan bool
+	steps := []Step{
+		{
+			Name:   "post",
+			Action: func(s State) error { return nil },
+		},
+		{
+			Name:   "slack",
+			When:   "{{if .PreviousError}}true{{else}}false{{end}}",
+			Action: func(s State) error { slackRan = true; return nil },
+		},
+	}
+	c := NewChain(steps, nil)
+	if err := c.Run(State{}); err != nil {
+		t.Fatal(err)
+	}
+	if slackRan {
+		t.Fatal("expected the slack step to be skipped after the post step succeeded")
+	}
+}
+
+func TestChain_StopShortCircuitsRemainingSteps(t *testing.T) {
+	var secondRan bool
+	steps := []Step{
+		{Name: "first", Action: func(s State) error { return nil }, Stop: true},
+		{Name: "second", Action: func(s State) error { secondRan = true; return nil }},
+	}
+	c := NewChain(steps, nil)
+	if err := c.Run(State{}); err != nil {
+		t.Fatal(err)
+	}
+	if secondRan {
+		t.Fatal("expected Stop to short-circuit the remaining steps")
+	}
+}
+
+func TestChain_TransformRewritesMessageForLaterSteps(t *testing.T) {
+	var seen string
+	steps := []Step{
+		{
+			Name:      "first",
+			Action:    func(s State) error { return nil },
+			Transform: &Transform{MessageTemplate: "wrapped: {{.Message}}"},
+		},
+		{
+			Name:   "second",
+			Action: func(s State) error { seen = s.Message; return nil },
+		},
+	}
+	c := NewChain(steps, nil)
+	if err := c.Run(State{Message: "disk full"}); err != nil {
+		t.Fatal(err)
+	}
+	if seen != "wrapped: disk full" {
+		t.Fatalf("expected the rewritten message to reach the second step, got %q", seen)
+	}
+}
+
+func TestChain_FanoutRunsSubStepsAndSurfacesFirstError(t *testing.T) {
+	steps := []Step{
+		{
+			Name: "notify",
+			Fanout: []Step{
+				{Name: "a", Action: func(s State) error { return nil }},
+				{Name: "b", Action: func(s State) error { return fmt.Errorf("b failed") }},
+			},
+		},
+	}
+	c := NewChain(steps, nil)
+	if err := c.Run(State{}); err == nil {
+		t.Fatal("expected the fanout step's error to propagate")
+	}
+}
@@ -0,0 +1,78 @@
+package handlerkind_test
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/influxdata/kapacitor/services/discord"
+	"github.com/influxdata/kapacitor/services/grpcalert"
+	"github.com/influxdata/kapacitor/services/handlerkind"
+	"github.com/influxdata/kapacitor/services/jira"
+	"github.com/influxdata/kapacitor/services/kafka"
+	"github.com/influxdata/kapacitor/services/mattermost"
+	"github.com/influxdata/kapacitor/services/msteams"
+	"github.com/influxdata/kapacitor/services/pubsub"
+	"github.com/influxdata/kapacitor/services/pushbullet"
+)
+
+var testLogger = log.New(ioutil.Discard, "", 0)
+
+// TestBuild_EveryRegisteredHandlerPackage exercises the registry end to
+// end against each handler package's real Service type, proving that a
+// kind string and that kind's own options can be turned into a working
+// alert.Handler without the caller importing the handler package
+// itself. This is the integration point these packages were missing:
+// previously nothing outside a package's own tests ever referenced it.
+func TestBuild_EveryRegisteredHandlerPackage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "handlerkind-jira-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	db, err := bolt.Open(filepath.Join(dir, "jira.db"), 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	jiraSvc, err := jira.NewService(jira.Config{}, db, testLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		kind string
+		svc  interface{}
+	}{
+		{"discord", discord.NewService(discord.Config{}, testLogger)},
+		{"msteams", msteams.NewService(msteams.Config{}, testLogger)},
+		{"mattermost", mattermost.NewService(mattermost.Config{}, testLogger)},
+		{"jira", jiraSvc},
+		{"kafka", kafka.NewService(kafka.Config{}, testLogger)},
+		{"pubsub", pubsub.NewService(pubsub.Config{}, testLogger)},
+		{"grpcalert", grpcalert.NewService(grpcalert.Config{}, testLogger)},
+		{"pushbullet", pushbullet.NewService(pushbullet.Config{}, testLogger)},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.kind, func(t *testing.T) {
+			h, err := handlerkind.Build(tc.kind, tc.svc, nil, testLogger)
+			if err != nil {
+				t.Fatalf("Build(%q): %s", tc.kind, err)
+			}
+			if h == nil {
+				t.Fatalf("Build(%q): expected a non-nil handler", tc.kind)
+			}
+		})
+	}
+}
+
+func TestBuild_RejectsMismatchedServiceType(t *testing.T) {
+	if _, err := handlerkind.Build("discord", "not-a-discord-service", nil, testLogger); err == nil {
+		t.Fatal("expected an error when svc is not the kind's own Service type")
+	}
+}
@@ -0,0 +1,68 @@
+// Package handlerkind is a registry from an alert handler "kind" string
+// (e.g. "discord", "jira") to a Builder that turns that kind's own
+// already-opened Service plus a handler spec's options into an
+// alert.Handler. Each handler package registers its Builder in an
+// init() alongside its Service type, so looking up a kind by name does
+// not require every caller to import every handler package.
+//
+// The registry deliberately does not own a services-by-kind map: it
+// only resolves (kind, svc, options) -> alert.Handler once the caller
+// already has the right kind's *Service in hand. Building and opening
+// each Service from its own config section, and keeping a kind->Service
+// map around to pass in here, is the job of whatever assembles the full
+// set of running services.
+package handlerkind
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/influxdata/kapacitor/alert"
+)
+
+// Builder constructs an alert.Handler from svc (the kind's own
+// *Service, already opened) and options (a handler spec's raw options,
+// mapstructure-decoded into that kind's own HandlerConfig).
+type Builder func(svc interface{}, options map[string]interface{}, l *log.Logger) (alert.Handler, error)
+
+var (
+	mu       sync.RWMutex
+	builders = make(map[string]Builder)
+)
+
+// Register adds b under kind. It panics on a duplicate kind, since that
+// can only happen from a programming error at package init time.
+func Register(kind string, b Builder) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, exists := builders[kind]; exists {
+		panic(fmt.Sprintf("handlerkind: kind %q already registered", kind))
+	}
+	builders[kind] = b
+}
+
+// Build looks up kind's Builder and invokes it with svc and options,
+// returning an error if kind has no registered Builder.
+func Build(kind string, svc interface{}, options map[string]interface{}, l *log.Logger) (alert.Handler, error) {
+	mu.RLock()
+	b, ok := builders[kind]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("handlerkind: no handler registered for kind %q", kind)
+	}
+	return b(svc, options, l)
+}
+
+// Kinds returns the currently registered kind names, sorted.
+func Kinds() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	kinds := make([]string, 0, len(builders))
+	for k := range builders {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
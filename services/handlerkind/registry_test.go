@@ -0,0 +1,83 @@
+package handlerkind
+
+import (
+	"log"
+	"testing"
+
+	"github.com/influxdata/kapacitor/alert"
+)
+
+type fakeHandler struct {
+	handled []alert.Event
+}
+
+func (h *fakeHandler) Handle(event alert.Event) {
+	h.handled = append(h.handled, event)
+}
+
+func TestRegister_PanicsOnDuplicateKind(t *testing.T) {
+	const kind = "test-duplicate"
+	Register(kind, func(svc interface{}, options map[string]interface{}, l *log.Logger) (alert.Handler, error) {
+		return &fakeHandler{}, nil
+	})
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic when registering a kind twice")
+		}
+	}()
+	Register(kind, func(svc interface{}, options map[string]interface{}, l *log.Logger) (alert.Handler, error) {
+		return &fakeHandler{}, nil
+	})
+}
+
+func TestBuild_UnknownKindReturnsError(t *testing.T) {
+	if _, err := Build("not-a-registered-kind", nil, nil, nil); err == nil {
+		t.Fatal("expected an error for an unregistered kind")
+	}
+}
+
+func TestBuild_DispatchesToRegisteredBuilder(t *testing.T) {
+	const kind = "test-dispatch"
+	var gotSvc interface{}
+	var gotOptions map[string]interface{}
+	want := &fakeHandler{}
+	Register(kind, func(svc interface{}, options map[string]interface{}, l *log.Logger) (alert.Handler, error) {
+		gotSvc = svc
+		gotOptions = options
+		return want, nil
+	})
+
+	svc := "a-fake-service-instance"
+	options := map[string]interface{}{"channel": "ops"}
+	got, err := Build(kind, svc, options, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != alert.Handler(want) {
+		t.Fatalf("expected the registered builder's handler to be returned")
+	}
+	if gotSvc != svc {
+		t.Fatalf("expected the builder to receive the passed-in svc, got %v", gotSvc)
+	}
+	if gotOptions["channel"] != "ops" {
+		t.Fatalf("expected the builder to receive the passed-in options, got %v", gotOptions)
+	}
+}
+
+func TestKinds_IncludesRegisteredKind(t *testing.T) {
+	const kind = "test-kinds-listing"
+	Register(kind, func(svc interface{}, options map[string]interface{}, l *log.Logger) (alert.Handler, error) {
+		return &fakeHandler{}, nil
+	})
+
+	found := false
+	for _, k := range Kinds() {
+		if k == kind {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected %q in Kinds(), got %v", kind, Kinds())
+	}
+}
@@ -0,0 +1,59 @@
+package handlerpatch
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Handler serves POST /kapacitor/v1preview/alerts/handlers/_bulk,
+// applying a batch of handler patches in one storage transaction.
+type Handler struct {
+	Store     Store
+	Validator SchemaValidator
+}
+
+func NewHandler(store Store, validator SchemaValidator) *Handler {
+	return &Handler{Store: store, Validator: validator}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var patches []LinkPatch
+	if err := json.NewDecoder(req.Body).Decode(&patches); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	results, err := Bulk(h.Store, patches, h.Validator)
+	if err != nil {
+		status := http.StatusBadRequest
+		if isTestFailure(err) {
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"handlers": results})
+}
+
+// isTestFailure reports whether err is (or wraps) a failed "test" op,
+// the one failure mode this endpoint surfaces as 409 rather than 400.
+func isTestFailure(err error) bool {
+	for err != nil {
+		if _, ok := err.(*TestFailedError); ok {
+			return true
+		}
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return false
+		}
+		err = u.Unwrap()
+	}
+	return false
+}
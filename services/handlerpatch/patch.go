@@ -0,0 +1,122 @@
+package handlerpatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// PatchOp is a single RFC 6902 operation.
+type PatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// TestFailedError is returned when a "test" op doesn't match, identifying
+// the failing pointer so callers can return it in a 409 response.
+type TestFailedError struct {
+	Path string
+}
+
+func (e *TestFailedError) Error() string {
+	return fmt.Sprintf("test operation failed for path %q", e.Path)
+}
+
+// SchemaValidator is consulted whenever an add/replace targets
+// "/actions/-", to check the new action's options against its kind's
+// schema before the patch is allowed to persist.
+type SchemaValidator func(action map[string]interface{}) error
+
+// Apply clones doc, applies ops in order, and returns the result. If any
+// op fails — including a "test" mismatch — doc is left untouched and the
+// error identifies the offending op's path. validator, if non-nil, is
+// called for every add/replace whose path is "/actions/-".
+func Apply(doc map[string]interface{}, ops []PatchOp, validator SchemaValidator) (map[string]interface{}, error) {
+	working, err := clone(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var root interface{} = working
+	for _, op := range ops {
+		var err error
+		switch op.Op {
+		case "test":
+			var current interface{}
+			current, err = get(root, op.Path)
+			if err == nil && !deepEqualJSON(current, op.Value) {
+				return nil, &TestFailedError{Path: op.Path}
+			}
+		case "add":
+			if validator != nil && op.Path == "/actions/-" {
+				if verr := validateAction(validator, op.Value); verr != nil {
+					return nil, verr
+				}
+			}
+			root, err = set(root, op.Path, op.Value, false)
+		case "replace":
+			if validator != nil && op.Path == "/actions/-" {
+				if verr := validateAction(validator, op.Value); verr != nil {
+					return nil, verr
+				}
+			}
+			root, err = set(root, op.Path, op.Value, true)
+		case "remove":
+			root, err = remove(root, op.Path)
+		default:
+			err = fmt.Errorf("unsupported op %q", op.Op)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result, ok := root.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("patch must not replace the document root with a non-object")
+	}
+	return result, nil
+}
+
+func validateAction(validator SchemaValidator, value interface{}) error {
+	action, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("action value must be an object")
+	}
+	return validator(action)
+}
+
+// clone round-trips doc through JSON so Apply can mutate the copy
+// in place without touching the caller's document on failure.
+func clone(doc map[string]interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// deepEqualJSON compares a and b the way RFC 6902's "test" op requires:
+// structurally, after both have passed through the same JSON
+// representation, so e.g. a literal float64(1) and a decoded json.Number
+// compare equal.
+func deepEqualJSON(a, b interface{}) bool {
+	an, aerr := json.Marshal(a)
+	bn, berr := json.Marshal(b)
+	if aerr != nil || berr != nil {
+		return reflect.DeepEqual(a, b)
+	}
+	var av, bv interface{}
+	if err := json.Unmarshal(an, &av); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(bn, &bv); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
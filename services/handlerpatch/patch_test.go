@@ -0,0 +1,194 @@
+package handlerpatch
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func handlerDoc(id string, enabled bool) map[string]interface{} {
+	return map[string]interface{}{
+		"id":      id,
+		"enabled": enabled,
+		"actions": []interface{}{
+			map[string]interface{}{"kind": "slack", "options": map[string]interface{}{"channel": "#ops"}},
+		},
+	}
+}
+
+func TestApply_AddReplaceRemove(t *testing.T) {
+	doc := handlerDoc("h1", false)
+
+	patched, err := Apply(doc, []PatchOp{
+		{Op: "replace", Path: "/enabled", Value: true},
+		{Op: "add", Path: "/actions/-", Value: map[string]interface{}{"kind": "log", "options": map[string]interface{}{}}},
+		{Op: "remove", Path: "/actions/0"},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if patched["enabled"] != true {
+		t.Fatalf("expected enabled=true, got %+v", patched["enabled"])
+	}
+	actions := patched["actions"].([]interface{})
+	if len(actions) != 1 {
+		t.Fatalf("expected one action after remove, got %+v", actions)
+	}
+	if doc["enabled"] != false {
+		t.Fatal("expected the original document to be left untouched")
+	}
+}
+
+func TestApply_TestOpFailureAbortsWholePatch(t *testing.T) {
+	doc := handlerDoc("h1", false)
+
+	_, err := Apply(doc, []PatchOp{
+		{Op: "test", Path: "/enabled", Value: true},
+		{Op: "replace", Path: "/enabled", Value: true},
+	}, nil)
+	if err == nil {
+		t.Fatal("expected the test op to fail")
+	}
+	tf, ok := err.(*TestFailedError)
+	if !ok {
+		t.Fatalf("expected a *TestFailedError, got %T", err)
+	}
+	if tf.Path != "/enabled" {
+		t.Fatalf("unexpected failing path %q", tf.Path)
+	}
+	if doc["enabled"] != false {
+		t.Fatal("expected no mutation after a failed test op")
+	}
+}
+
+func TestApply_TestOpSuccessContinues(t *testing.T) {
+	doc := handlerDoc("h1", false)
+
+	patched, err := Apply(doc, []PatchOp{
+		{Op: "test", Path: "/enabled", Value: false},
+		{Op: "replace", Path: "/enabled", Value: true},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if patched["enabled"] != true {
+		t.Fatalf("expected enabled=true, got %+v", patched["enabled"])
+	}
+}
+
+func TestApply_SchemaValidationRejectsInvalidAction(t *testing.T) {
+	doc := handlerDoc("h1", false)
+	validator := func(action map[string]interface{}) error {
+		if action["kind"] != "slack" {
+			return fmt.Errorf("unsupported kind %v", action["kind"])
+		}
+		return nil
+	}
+
+	_, err := Apply(doc, []PatchOp{
+		{Op: "add", Path: "/actions/-", Value: map[string]interface{}{"kind": "unknown"}},
+	}, validator)
+	if err == nil {
+		t.Fatal("expected schema validation to reject the unknown kind")
+	}
+}
+
+type memStore struct {
+	docs map[string]map[string]interface{}
+}
+
+func newMemStore() *memStore {
+	return &memStore{docs: make(map[string]map[string]interface{})}
+}
+
+func (s *memStore) Get(link string) (map[string]interface{}, error) {
+	doc, ok := s.docs[link]
+	if !ok {
+		return nil, fmt.Errorf("no handler at %q", link)
+	}
+	return doc, nil
+}
+
+func (s *memStore) CommitAll(docs map[string]map[string]interface{}) error {
+	for link, doc := range docs {
+		s.docs[link] = doc
+	}
+	return nil
+}
+
+func TestBulk_AppliesAllOnSuccess(t *testing.T) {
+	store := newMemStore()
+	store.docs["/handlers/a"] = handlerDoc("a", false)
+	store.docs["/handlers/b"] = handlerDoc("b", false)
+
+	_, err := Bulk(store, []LinkPatch{
+		{Link: "/handlers/a", Patch: []PatchOp{{Op: "replace", Path: "/enabled", Value: true}}},
+		{Link: "/handlers/b", Patch: []PatchOp{{Op: "replace", Path: "/enabled", Value: true}}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.docs["/handlers/a"]["enabled"] != true || store.docs["/handlers/b"]["enabled"] != true {
+		t.Fatal("expected both handlers to be patched")
+	}
+}
+
+func TestBulk_RollsBackOnFirstFailure(t *testing.T) {
+	store := newMemStore()
+	store.docs["/handlers/a"] = handlerDoc("a", false)
+	store.docs["/handlers/b"] = handlerDoc("b", false)
+
+	_, err := Bulk(store, []LinkPatch{
+		{Link: "/handlers/a", Patch: []PatchOp{{Op: "replace", Path: "/enabled", Value: true}}},
+		{Link: "/handlers/b", Patch: []PatchOp{{Op: "test", Path: "/enabled", Value: true}}},
+	}, nil)
+	if err == nil {
+		t.Fatal("expected the second handler's failing test op to fail the whole batch")
+	}
+	if store.docs["/handlers/a"]["enabled"] != false {
+		t.Fatal("expected the first handler's successful patch to be rolled back")
+	}
+}
+
+func TestHandler_BulkEndpoint(t *testing.T) {
+	store := newMemStore()
+	store.docs["/handlers/a"] = handlerDoc("a", false)
+
+	h := NewHandler(store, nil)
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	body := `[{"link":"/handlers/a","patch":[{"op":"replace","path":"/enabled","value":true}]}]`
+	resp, err := http.Post(srv.URL, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if store.docs["/handlers/a"]["enabled"] != true {
+		t.Fatal("expected the bulk endpoint to persist the patch")
+	}
+}
+
+func TestHandler_BulkEndpointReturns409OnTestFailure(t *testing.T) {
+	store := newMemStore()
+	store.docs["/handlers/a"] = handlerDoc("a", false)
+
+	h := NewHandler(store, nil)
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	body := `[{"link":"/handlers/a","patch":[{"op":"test","path":"/enabled","value":true}]}]`
+	resp, err := http.Post(srv.URL, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,188 @@
+// Package handlerpatch extends RFC 6902 JSON Patch handling for alert
+// handler documents with the "test" operation, schema validation of
+// `/actions/-` entries against a handler kind's schema, and an
+// all-or-nothing bulk-apply across several handlers in one transaction.
+package handlerpatch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// splitPointer splits an RFC 6901 JSON pointer ("/actions/0/options")
+// into its unescaped tokens.
+func splitPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid json pointer %q: must start with /", pointer)
+	}
+	parts := strings.Split(pointer[1:], "/")
+	for i, p := range parts {
+		p = strings.ReplaceAll(p, "~1", "/")
+		p = strings.ReplaceAll(p, "~0", "~")
+		parts[i] = p
+	}
+	return parts, nil
+}
+
+// get resolves pointer against doc.
+func get(doc interface{}, pointer string) (interface{}, error) {
+	tokens, err := splitPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	cur := doc
+	for _, tok := range tokens {
+		switch v := cur.(type) {
+		case map[string]interface{}:
+			next, ok := v[tok]
+			if !ok {
+				return nil, fmt.Errorf("path %q does not exist", pointer)
+			}
+			cur = next
+		case []interface{}:
+			idx, err := arrayIndex(tok, len(v))
+			if err != nil {
+				return nil, err
+			}
+			if idx >= len(v) {
+				return nil, fmt.Errorf("path %q does not exist", pointer)
+			}
+			cur = v[idx]
+		default:
+			return nil, fmt.Errorf("path %q does not exist", pointer)
+		}
+	}
+	return cur, nil
+}
+
+// set applies add/replace semantics for pointer within doc, returning the
+// (possibly new) root document.
+func set(doc interface{}, pointer string, value interface{}, replace bool) (interface{}, error) {
+	tokens, err := splitPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return setAt(doc, tokens, value, replace, pointer)
+}
+
+func setAt(doc interface{}, tokens []string, value interface{}, replace bool, pointer string) (interface{}, error) {
+	parent := tokens[:len(tokens)-1]
+	last := tokens[len(tokens)-1]
+
+	target := doc
+	if len(parent) > 0 {
+		v, err := get(doc, "/"+strings.Join(parent, "/"))
+		if err != nil {
+			return nil, err
+		}
+		target = v
+	}
+
+	switch t := target.(type) {
+	case map[string]interface{}:
+		if replace {
+			if _, ok := t[last]; !ok {
+				return nil, fmt.Errorf("path %q does not exist", pointer)
+			}
+		}
+		t[last] = value
+	case []interface{}:
+		if last == "-" {
+			if replace {
+				return nil, fmt.Errorf("path %q does not exist", pointer)
+			}
+			t = append(t, value)
+		} else {
+			idx, err := arrayIndex(last, len(t))
+			if err != nil {
+				return nil, err
+			}
+			if replace {
+				if idx >= len(t) {
+					return nil, fmt.Errorf("path %q does not exist", pointer)
+				}
+				t[idx] = value
+			} else {
+				if idx > len(t) {
+					return nil, fmt.Errorf("path %q is out of range", pointer)
+				}
+				t = append(t[:idx], append([]interface{}{value}, t[idx:]...)...)
+			}
+		}
+		if len(parent) == 0 {
+			return t, nil
+		}
+		if _, err := set(doc, "/"+strings.Join(parent, "/"), t, true); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("path %q does not exist", pointer)
+	}
+	return doc, nil
+}
+
+// remove deletes pointer from doc.
+func remove(doc interface{}, pointer string) (interface{}, error) {
+	tokens, err := splitPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("cannot remove the document root")
+	}
+
+	parent := tokens[:len(tokens)-1]
+	last := tokens[len(tokens)-1]
+	target := doc
+	if len(parent) > 0 {
+		v, err := get(doc, "/"+strings.Join(parent, "/"))
+		if err != nil {
+			return nil, err
+		}
+		target = v
+	}
+
+	switch t := target.(type) {
+	case map[string]interface{}:
+		if _, ok := t[last]; !ok {
+			return nil, fmt.Errorf("path %q does not exist", "/"+strings.Join(tokens, "/"))
+		}
+		delete(t, last)
+	case []interface{}:
+		idx, err := arrayIndex(last, len(t))
+		if err != nil {
+			return nil, err
+		}
+		if idx >= len(t) {
+			return nil, fmt.Errorf("path %q does not exist", "/"+strings.Join(tokens, "/"))
+		}
+		out := append(t[:idx], t[idx+1:]...)
+		if len(parent) == 0 {
+			return out, nil
+		}
+		if _, err := set(doc, "/"+strings.Join(parent, "/"), out, true); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("path %q does not exist", "/"+strings.Join(tokens, "/"))
+	}
+	return doc, nil
+}
+
+func arrayIndex(tok string, length int) (int, error) {
+	if tok == "-" {
+		return length, nil
+	}
+	idx, err := strconv.Atoi(tok)
+	if err != nil || idx < 0 {
+		return 0, fmt.Errorf("invalid array index %q", tok)
+	}
+	return idx, nil
+}
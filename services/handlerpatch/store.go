@@ -0,0 +1,69 @@
+package handlerpatch
+
+// Store is the subset of the handler storage layer this package needs:
+// enough to read a handler document by its link and persist a whole new
+// set of documents atomically.
+type Store interface {
+	// Get returns the current document stored at link.
+	Get(link string) (map[string]interface{}, error)
+	// CommitAll persists every (link, doc) pair in docs as a single
+	// transaction: either all writes land or none do.
+	CommitAll(docs map[string]map[string]interface{}) error
+}
+
+// LinkPatch is one element of a bulk-patch request: the handler's link
+// plus the RFC 6902 ops to apply to it.
+type LinkPatch struct {
+	Link  string    `json:"link"`
+	Patch []PatchOp `json:"patch"`
+}
+
+// BulkResult reports, per link, whether its patch applied and the
+// resulting document. On success Bulk returns one BulkResult per input
+// patch, in input order; on failure it returns no results.
+type BulkResult struct {
+	Link string                 `json:"link"`
+	Doc  map[string]interface{} `json:"options,omitempty"`
+}
+
+// Bulk computes every patch in patches against store, committing all of
+// them in a single Store transaction only if every one succeeds. On the
+// first failure, nothing is written and the error identifies which link
+// and pointer failed.
+func Bulk(store Store, patches []LinkPatch, validator SchemaValidator) ([]BulkResult, error) {
+	docs := make(map[string]map[string]interface{}, len(patches))
+	results := make([]BulkResult, 0, len(patches))
+
+	for _, lp := range patches {
+		doc, err := store.Get(lp.Link)
+		if err != nil {
+			return nil, &BulkError{Link: lp.Link, Err: err}
+		}
+		patched, err := Apply(doc, lp.Patch, validator)
+		if err != nil {
+			return nil, &BulkError{Link: lp.Link, Err: err}
+		}
+		docs[lp.Link] = patched
+		results = append(results, BulkResult{Link: lp.Link, Doc: patched})
+	}
+
+	if err := store.CommitAll(docs); err != nil {
+		return nil, &BulkError{Err: err}
+	}
+	return results, nil
+}
+
+// BulkError identifies which handler (if any) a bulk patch failed on.
+type BulkError struct {
+	Link string
+	Err  error
+}
+
+func (e *BulkError) Error() string {
+	if e.Link == "" {
+		return e.Err.Error()
+	}
+	return e.Link + ": " + e.Err.Error()
+}
+
+func (e *BulkError) Unwrap() error { return e.Err }
@@ -0,0 +1,22 @@
+package hintedhandoff
+
+import "time"
+
+type Config struct {
+	Enabled          bool          `toml:"enabled" override:"enabled"`
+	Dir              string        `toml:"dir" override:"dir"`
+	MaxSize          int64         `toml:"max-size" override:"max-size"`
+	MaxAge           time.Duration `toml:"max-age" override:"max-age"`
+	RetryInterval    time.Duration `toml:"retry-interval" override:"retry-interval"`
+	RetryMaxInterval time.Duration `toml:"retry-max-interval" override:"retry-max-interval"`
+}
+
+func NewConfig() Config {
+	return Config{
+		Enabled:          false,
+		MaxSize:          100 << 20,
+		MaxAge:           24 * time.Hour,
+		RetryInterval:    100 * time.Millisecond,
+		RetryMaxInterval: time.Minute,
+	}
+}
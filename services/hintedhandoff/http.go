@@ -0,0 +1,49 @@
+package hintedhandoff
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// Registry tracks every open Queue, keyed by "taskID/handler", for the
+// /kapacitor/v1/alerts/hh endpoint.
+type Registry struct {
+	mu     sync.RWMutex
+	queues map[string]*Queue
+}
+
+func NewRegistry() *Registry {
+	return &Registry{queues: make(map[string]*Queue)}
+}
+
+func (r *Registry) Register(key string, q *Queue) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queues[key] = q
+}
+
+func (r *Registry) Unregister(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.queues, key)
+}
+
+// ServeHTTP implements GET /kapacitor/v1/alerts/hh, reporting every
+// registered queue's depth.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.mu.RLock()
+	depths := make(map[string]int, len(r.queues))
+	for key, q := range r.queues {
+		depths[key] = q.Depth()
+	}
+	r.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"queues": depths})
+}
@@ -0,0 +1,226 @@
+// Package hintedhandoff durably queues alert events per handler when that
+// handler is temporarily unavailable (analogous to InfluxDB's hinted
+// handoff), delivering them in order once it recovers instead of losing
+// them silently.
+package hintedhandoff
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Entry is one queued alert event, numbered so redelivery never
+// duplicates or skips an entry across a process restart. Timestamp
+// records when the entry was enqueued, so a Queue with MaxAge set can
+// tell how long it has been waiting.
+type Entry struct {
+	Sequence  int64           `json:"seq"`
+	Timestamp time.Time       `json:"ts"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Queue is a durable, ordered, on-disk FIFO of Entries for a single
+// (taskID, handler) pair, backed by an append-only segment file and a
+// separate file recording how many entries at its head have been
+// acknowledged. If maxSize is set, Enqueue rejects new entries once the
+// segment reaches that size; if maxAge is set, entries older than it are
+// skipped (treated as acknowledged) rather than delivered, so a handler
+// that was down for a long time isn't flooded with stale alerts once it
+// recovers.
+type Queue struct {
+	dir     string
+	maxSize int64
+	maxAge  time.Duration
+
+	// Now, if set, is used in place of time.Now, for testing MaxAge
+	// expiry without a real clock.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	segment *os.File
+	nextSeq int64
+	acked   int64 // number of entries at the head of the segment already ACKed
+}
+
+const (
+	segmentName = "segment.log"
+	offsetName  = "offset"
+)
+
+// Open opens (creating if necessary) the queue stored in dir, enforcing
+// c's MaxSize and MaxAge going forward.
+func Open(dir string, c Config) (*Queue, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create hinted handoff dir %q: %s", dir, err)
+	}
+
+	q := &Queue{dir: dir, maxSize: c.MaxSize, maxAge: c.MaxAge, Now: time.Now}
+	if err := q.loadOffset(); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, segmentName), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open hinted handoff segment in %q: %s", dir, err)
+	}
+	q.segment = f
+
+	entries, err := q.readAll()
+	if err != nil {
+		return nil, err
+	}
+	q.nextSeq = int64(len(entries))
+
+	return q, nil
+}
+
+func (q *Queue) loadOffset() error {
+	data, err := ioutil.ReadFile(filepath.Join(q.dir, offsetName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read hinted handoff offset in %q: %s", q.dir, err)
+	}
+	n, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return fmt.Errorf("corrupt hinted handoff offset in %q: %s", q.dir, err)
+	}
+	q.acked = n
+	return nil
+}
+
+func (q *Queue) saveOffset() error {
+	tmp := filepath.Join(q.dir, offsetName+".tmp")
+	if err := ioutil.WriteFile(tmp, []byte(strconv.FormatInt(q.acked, 10)), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(q.dir, offsetName))
+}
+
+// Enqueue durably appends data as the next entry in the queue, rejecting
+// it if the segment is already at its MaxSize.
+func (q *Queue) Enqueue(data json.RawMessage) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.maxSize > 0 {
+		info, err := q.segment.Stat()
+		if err != nil {
+			return fmt.Errorf("failed to stat hinted handoff segment in %q: %s", q.dir, err)
+		}
+		if info.Size() >= q.maxSize {
+			return fmt.Errorf("hinted handoff segment in %q is at its %d byte limit", q.dir, q.maxSize)
+		}
+	}
+
+	e := Entry{Sequence: q.nextSeq, Timestamp: q.now(), Data: data}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := q.segment.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to append to hinted handoff segment in %q: %s", q.dir, err)
+	}
+	if err := q.segment.Sync(); err != nil {
+		return err
+	}
+	q.nextSeq++
+	return nil
+}
+
+// Pending returns every entry not yet acknowledged and not expired past
+// MaxAge, oldest first. Expired entries are skipped by advancing the ack
+// offset past them, the same as if they had been delivered, since
+// entries are appended in timestamp order and so never expire out of
+// order.
+func (q *Queue) Pending() ([]Entry, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	all, err := q.readAll()
+	if err != nil {
+		return nil, err
+	}
+	if q.acked >= int64(len(all)) {
+		return nil, nil
+	}
+	pending := all[q.acked:]
+
+	if q.maxAge > 0 {
+		cutoff := q.now().Add(-q.maxAge)
+		expired := 0
+		for expired < len(pending) && pending[expired].Timestamp.Before(cutoff) {
+			expired++
+		}
+		if expired > 0 {
+			q.acked += int64(expired)
+			if err := q.saveOffset(); err != nil {
+				return nil, err
+			}
+			pending = pending[expired:]
+		}
+	}
+
+	return pending, nil
+}
+
+func (q *Queue) now() time.Time {
+	if q.Now != nil {
+		return q.Now()
+	}
+	return time.Now()
+}
+
+func (q *Queue) readAll() ([]Entry, error) {
+	f, err := os.Open(filepath.Join(q.dir, segmentName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hinted handoff segment in %q: %s", q.dir, err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("corrupt hinted handoff entry in %q: %s", q.dir, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// Ack marks every entry up to and including seq as delivered. It is only
+// valid to Ack entries in order; acking out of order would let a crash
+// lose an entry that was actually still pending.
+func (q *Queue) Ack(seq int64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if seq+1 > q.acked {
+		q.acked = seq + 1
+	}
+	return q.saveOffset()
+}
+
+// Depth reports the number of entries not yet acknowledged.
+func (q *Queue) Depth() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return int(q.nextSeq - q.acked)
+}
+
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.segment.Close()
+}
@@ -0,0 +1,161 @@
+package hintedhandoff
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestQueue_EnqueuePendingAck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hintedhandoff-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	q, err := Open(filepath.Join(dir, "testTaskID", "log"), Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+
+	for i := 0; i < 3; i++ {
+		if err := q.Enqueue(json.RawMessage(`{"id":"alert"}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pending, err := q.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 3 {
+		t.Fatalf("unexpected pending count: %d", len(pending))
+	}
+
+	if err := q.Ack(pending[0].Sequence); err != nil {
+		t.Fatal(err)
+	}
+	pending, err = q.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("expected 2 entries still pending after ack, got %d", len(pending))
+	}
+	if q.Depth() != 2 {
+		t.Fatalf("unexpected depth: %d", q.Depth())
+	}
+}
+
+func TestQueue_SurvivesReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hintedhandoff-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	qdir := filepath.Join(dir, "testTaskID", "log")
+
+	q, err := Open(qdir, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		q.Enqueue(json.RawMessage(`{"id":"alert"}`))
+	}
+	pending, _ := q.Pending()
+	q.Ack(pending[0].Sequence)
+	q.Close()
+
+	reopened, err := Open(qdir, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+
+	if reopened.Depth() != 1 {
+		t.Fatalf("expected the un-acked entry to survive a restart, got depth %d", reopened.Depth())
+	}
+
+	if err := reopened.Enqueue(json.RawMessage(`{"id":"alert"}`)); err != nil {
+		t.Fatal(err)
+	}
+	pending, err = reopened.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("expected 2 pending entries after reopen+enqueue, got %d", len(pending))
+	}
+	if pending[1].Sequence != 2 {
+		t.Fatalf("expected sequence numbers to continue from before the restart, got %d", pending[1].Sequence)
+	}
+}
+
+func TestQueue_RejectsEnqueuePastMaxSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hintedhandoff-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	q, err := Open(filepath.Join(dir, "testTaskID", "log"), Config{MaxSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+
+	if err := q.Enqueue(json.RawMessage(`{"id":"alert"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue(json.RawMessage(`{"id":"alert"}`)); err == nil {
+		t.Fatal("expected enqueue to be rejected once the segment is at its MaxSize")
+	}
+	if q.Depth() != 1 {
+		t.Fatalf("expected the rejected entry to not be counted, got depth %d", q.Depth())
+	}
+}
+
+func TestQueue_Pending_SkipsEntriesOlderThanMaxAge(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hintedhandoff-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	q, err := Open(filepath.Join(dir, "testTaskID", "log"), Config{MaxAge: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+
+	now := time.Unix(0, 0)
+	q.Now = func() time.Time { return now }
+
+	if err := q.Enqueue(json.RawMessage(`{"id":"stale"}`)); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(2 * time.Minute)
+	if err := q.Enqueue(json.RawMessage(`{"id":"fresh"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := q.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 {
+		t.Fatalf("expected the entry older than MaxAge to be skipped, got %d pending", len(pending))
+	}
+	var v map[string]string
+	json.Unmarshal(pending[0].Data, &v)
+	if v["id"] != "fresh" {
+		t.Fatalf("expected the surviving entry to be the fresh one, got %v", v)
+	}
+	if q.Depth() != 1 {
+		t.Fatalf("expected the expired entry to no longer count toward depth, got %d", q.Depth())
+	}
+}
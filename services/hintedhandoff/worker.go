@@ -0,0 +1,91 @@
+package hintedhandoff
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Deliver attempts to deliver one entry's data to the handler it was
+// queued for, returning an error if the handler is still unavailable.
+type Deliver func(data []byte) error
+
+// Worker delivers a Queue's entries to a handler in order, retrying a
+// failed delivery with exponential backoff and jitter rather than
+// skipping ahead, so the handler never sees entries out of order or
+// duplicated.
+type Worker struct {
+	Queue   *Queue
+	Deliver Deliver
+
+	RetryInterval    time.Duration
+	RetryMaxInterval time.Duration
+	Sleep            func(time.Duration)
+
+	closing chan struct{}
+}
+
+func NewWorker(q *Queue, deliver Deliver, c Config) *Worker {
+	return &Worker{
+		Queue:            q,
+		Deliver:          deliver,
+		RetryInterval:    c.RetryInterval,
+		RetryMaxInterval: c.RetryMaxInterval,
+		Sleep:            time.Sleep,
+		closing:          make(chan struct{}),
+	}
+}
+
+// Run delivers every pending and subsequently enqueued entry in order
+// until Stop is called. It is meant to be run in its own goroutine.
+func (w *Worker) Run() {
+	for {
+		select {
+		case <-w.closing:
+			return
+		default:
+		}
+
+		entries, err := w.Queue.Pending()
+		if err != nil || len(entries) == 0 {
+			w.Sleep(w.RetryInterval)
+			continue
+		}
+
+		for _, e := range entries {
+			if !w.deliverWithBackoff(e) {
+				return
+			}
+		}
+	}
+}
+
+// deliverWithBackoff retries a single entry until it is delivered and
+// acknowledged, or the worker is stopped (in which case it returns false
+// without acking, so the entry is redelivered on restart).
+func (w *Worker) deliverWithBackoff(e Entry) bool {
+	backoff := w.RetryInterval
+	for {
+		select {
+		case <-w.closing:
+			return false
+		default:
+		}
+
+		if err := w.Deliver(e.Data); err == nil {
+			w.Queue.Ack(e.Sequence)
+			return true
+		}
+
+		sleep := backoff
+		if w.RetryMaxInterval > 0 && sleep > w.RetryMaxInterval {
+			sleep = w.RetryMaxInterval
+		}
+		jitter := time.Duration(rand.Int63n(int64(sleep)/2 + 1))
+		w.Sleep(sleep + jitter)
+		backoff *= 2
+	}
+}
+
+func (w *Worker) Stop() {
+	close(w.closing)
+}
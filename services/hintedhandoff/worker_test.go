@@ -0,0 +1,89 @@
+package hintedhandoff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWorker_DeliversInOrderAfterHandlerRecovers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hintedhandoff-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	q, err := Open(filepath.Join(dir, "testTaskID", "alert.log"), Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+
+	for i := 0; i < 5; i++ {
+		data, _ := json.Marshal(map[string]int{"id": i})
+		if err := q.Enqueue(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var mu sync.Mutex
+	var delivered []int
+	blackholed := true
+
+	deliver := func(data []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if blackholed {
+			return fmt.Errorf("handler unavailable")
+		}
+		var v map[string]int
+		json.Unmarshal(data, &v)
+		delivered = append(delivered, v["id"])
+		return nil
+	}
+
+	w := NewWorker(q, deliver, Config{RetryInterval: time.Millisecond, RetryMaxInterval: 5 * time.Millisecond})
+	w.Sleep = func(time.Duration) {}
+	go w.Run()
+	defer w.Stop()
+
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	if len(delivered) != 0 {
+		t.Fatal("expected nothing to be delivered while blackholed")
+	}
+	blackholed = false
+	mu.Unlock()
+
+	deadline := time.After(time.Second)
+	for {
+		mu.Lock()
+		n := len(delivered)
+		mu.Unlock()
+		if n == 5 {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for all entries to be delivered, got %d", n)
+		default:
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i, id := range delivered {
+		if id != i {
+			t.Fatalf("expected in-order delivery with no duplicates, got %v", delivered)
+		}
+	}
+	if q.Depth() != 0 {
+		t.Fatalf("expected queue to be drained, got depth %d", q.Depth())
+	}
+}
@@ -0,0 +1,91 @@
+// Package httpauth decorates outbound *http.Request values with bearer,
+// basic, or a custom header's worth of credentials, for handler kinds
+// that post alerts to an auth-protected webhook.
+package httpauth
+
+import (
+	"bytes"
+	"net/http"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// Type selects how Config authorizes a request.
+type Type string
+
+const (
+	// TypeBearer sets an "Authorization: Bearer <token>" header.
+	TypeBearer Type = "bearer"
+	// TypeBasic sets HTTP basic auth credentials.
+	TypeBasic Type = "basic"
+	// TypeHeader sets an arbitrary header to the result of executing
+	// HeaderValueTemplate as a text/template.
+	TypeHeader Type = "header"
+)
+
+// Config describes how to authorize a request to a single webhook.
+type Config struct {
+	Type     Type   `toml:"type" override:"type"`
+	Token    string `toml:"token" override:"token,redact"`
+	Username string `toml:"username" override:"username"`
+	Password string `toml:"password" override:"password,redact"`
+
+	HeaderName          string `toml:"header-name" override:"header-name"`
+	HeaderValueTemplate string `toml:"header-value-template" override:"header-value-template,redact"`
+}
+
+func (c Config) isZero() bool {
+	return c == Config{}
+}
+
+func (c Config) Validate() error {
+	if c.isZero() {
+		return nil
+	}
+	switch c.Type {
+	case TypeBearer:
+		if c.Token == "" {
+			return errors.New("must specify token in bearer auth mode")
+		}
+	case TypeBasic:
+		if c.Username == "" {
+			return errors.New("must specify username in basic auth mode")
+		}
+	case TypeHeader:
+		if c.HeaderName == "" || c.HeaderValueTemplate == "" {
+			return errors.New("must specify header-name and header-value-template in header auth mode")
+		}
+		if _, err := template.New("header-value").Parse(c.HeaderValueTemplate); err != nil {
+			return errors.Wrap(err, "invalid header-value-template")
+		}
+	default:
+		return errors.Errorf("unknown auth type %q, must be %q, %q or %q", c.Type, TypeBearer, TypeBasic, TypeHeader)
+	}
+	return nil
+}
+
+// Apply sets req's authorization according to c. For TypeHeader, data is
+// the value HeaderValueTemplate is executed against.
+func (c Config) Apply(req *http.Request, data interface{}) error {
+	if c.isZero() {
+		return nil
+	}
+	switch c.Type {
+	case TypeBearer:
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	case TypeBasic:
+		req.SetBasicAuth(c.Username, c.Password)
+	case TypeHeader:
+		tmpl, err := template.New("header-value").Parse(c.HeaderValueTemplate)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return err
+		}
+		req.Header.Set(c.HeaderName, buf.String())
+	}
+	return nil
+}
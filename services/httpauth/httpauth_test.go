@@ -0,0 +1,92 @@
+package httpauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConfig_Apply(t *testing.T) {
+	cases := []struct {
+		name string
+		c    Config
+		data interface{}
+		want func(t *testing.T, req *http.Request)
+	}{
+		{
+			name: "zero value applies nothing",
+			c:    Config{},
+			want: func(t *testing.T, req *http.Request) {
+				if req.Header.Get("Authorization") != "" {
+					t.Errorf("expected no Authorization header, got %q", req.Header.Get("Authorization"))
+				}
+			},
+		},
+		{
+			name: "bearer",
+			c:    Config{Type: TypeBearer, Token: "abc123"},
+			want: func(t *testing.T, req *http.Request) {
+				if got, exp := req.Header.Get("Authorization"), "Bearer abc123"; got != exp {
+					t.Errorf("got %q exp %q", got, exp)
+				}
+			},
+		},
+		{
+			name: "basic",
+			c:    Config{Type: TypeBasic, Username: "u", Password: "p"},
+			want: func(t *testing.T, req *http.Request) {
+				user, pass, ok := req.BasicAuth()
+				if !ok || user != "u" || pass != "p" {
+					t.Errorf("unexpected basic auth: %q %q %v", user, pass, ok)
+				}
+			},
+		},
+		{
+			name: "header",
+			c:    Config{Type: TypeHeader, HeaderName: "X-Api-Key", HeaderValueTemplate: "{{.Key}}"},
+			data: struct{ Key string }{Key: "xyz"},
+			want: func(t *testing.T, req *http.Request) {
+				if got, exp := req.Header.Get("X-Api-Key"), "xyz"; got != exp {
+					t.Errorf("got %q exp %q", got, exp)
+				}
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "http://example.com", nil)
+			if err := tc.c.Apply(req, tc.data); err != nil {
+				t.Fatal(err)
+			}
+			tc.want(t, req)
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	cases := []struct {
+		name    string
+		c       Config
+		wantErr bool
+	}{
+		{"zero value", Config{}, false},
+		{"bearer missing token", Config{Type: TypeBearer}, true},
+		{"bearer ok", Config{Type: TypeBearer, Token: "t"}, false},
+		{"basic missing username", Config{Type: TypeBasic}, true},
+		{"basic ok", Config{Type: TypeBasic, Username: "u", Password: "p"}, false},
+		{"header missing name", Config{Type: TypeHeader, HeaderValueTemplate: "{{.Key}}"}, true},
+		{"header invalid template", Config{Type: TypeHeader, HeaderName: "X", HeaderValueTemplate: "{{.Key"}, true},
+		{"header ok", Config{Type: TypeHeader, HeaderName: "X", HeaderValueTemplate: "{{.Key}}"}, false},
+		{"unknown type", Config{Type: "carrier-pigeon"}, true},
+	}
+	for _, tc := range cases {
+		err := tc.c.Validate()
+		if tc.wantErr && err == nil {
+			t.Errorf("%s: expected an error, got nil", tc.name)
+		}
+		if !tc.wantErr && err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+	}
+}
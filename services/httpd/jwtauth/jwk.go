@@ -0,0 +1,26 @@
+package jwtauth
+
+import (
+	"encoding/base64"
+	"math/big"
+)
+
+// base64URLBigInt decodes a base64url-encoded (no padding) big-endian
+// integer, as used for the "n" (modulus) field of an RSA JWK.
+func base64URLBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// base64URLInt decodes a base64url-encoded (no padding) big-endian integer
+// into an int, as used for the "e" (exponent) field of an RSA JWK.
+func base64URLInt(s string) (int, error) {
+	n, err := base64URLBigInt(s)
+	if err != nil {
+		return 0, err
+	}
+	return int(n.Int64()), nil
+}
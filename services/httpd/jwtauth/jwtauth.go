@@ -0,0 +1,255 @@
+// Package jwtauth verifies HTTP bearer tokens against a configurable set of
+// signing algorithms, including asymmetric algorithms backed by keys fetched
+// from a JWKS endpoint. It replaces a single shared HS512 secret with an
+// allowlist of algorithms, an issuer/audience check, and kid-based key
+// selection so that a compromised algorithm choice (e.g. "alg: none", or an
+// RS256 key reused as an HMAC secret) cannot be used to forge tokens.
+package jwtauth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+)
+
+// Config configures the verifier. It is designed to live under the
+// existing `[http]` config section alongside the legacy shared-secret mode.
+type Config struct {
+	// JWKSURL, when set, is polled on RefreshInterval for the current set of
+	// verification keys, selected per-token by the "kid" header.
+	JWKSURL string `toml:"jwks_url"`
+	// RefreshInterval is how often the JWKS is re-fetched. Defaults to 5m.
+	RefreshInterval time.Duration `toml:"jwks_refresh_interval"`
+	// SigningAlgorithms is the allowlist of "alg" header values accepted.
+	// Tokens signed with any other algorithm, including "none", are rejected.
+	SigningAlgorithms []string `toml:"signing_algorithms"`
+	// ExpectedIssuer, if set, must match the token's "iss" claim.
+	ExpectedIssuer string `toml:"expected_issuer"`
+	// ExpectedAudience, if set, must appear in the token's "aud" claim.
+	ExpectedAudience string `toml:"expected_audience"`
+}
+
+func (c Config) allowedAlgorithm(alg string) bool {
+	for _, a := range c.SigningAlgorithms {
+		if a == alg {
+			return true
+		}
+	}
+	return false
+}
+
+// JWK is a single JSON Web Key as returned by a JWKS endpoint. Only the
+// fields needed to reconstruct an RSA or EC public key are kept.
+type JWK struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwks struct {
+	Keys []JWK `json:"keys"`
+}
+
+// Verifier validates bearer tokens per Config, caching keys fetched from a
+// JWKS endpoint and refreshing them in the background.
+type Verifier struct {
+	c      Config
+	client *http.Client
+
+	mu   sync.RWMutex
+	keys map[string]crypto.PublicKey
+
+	stop chan struct{}
+}
+
+func NewVerifier(c Config) *Verifier {
+	if c.RefreshInterval <= 0 {
+		c.RefreshInterval = 5 * time.Minute
+	}
+	return &Verifier{
+		c:      c,
+		client: http.DefaultClient,
+		keys:   make(map[string]crypto.PublicKey),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Open fetches the initial JWKS, if configured, and starts the background
+// refresher.
+func (v *Verifier) Open() error {
+	if v.c.JWKSURL == "" {
+		return nil
+	}
+	if err := v.refresh(); err != nil {
+		return err
+	}
+	go v.refreshLoop()
+	return nil
+}
+
+func (v *Verifier) Close() error {
+	select {
+	case <-v.stop:
+	default:
+		close(v.stop)
+	}
+	return nil
+}
+
+func (v *Verifier) refreshLoop() {
+	ticker := time.NewTicker(v.c.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			v.refresh()
+		case <-v.stop:
+			return
+		}
+	}
+}
+
+func (v *Verifier) refresh() error {
+	resp, err := v.client.Get(v.c.JWKSURL)
+	if err != nil {
+		return errors.Wrap(err, "fetching jwks")
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "reading jwks response")
+	}
+
+	var set jwks
+	if err := json.Unmarshal(data, &set); err != nil {
+		return errors.Wrap(err, "decoding jwks")
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		var pub crypto.PublicKey
+		var err error
+		switch k.Kty {
+		case "RSA":
+			pub, err = rsaPublicKeyFromJWK(k)
+		case "EC":
+			pub, err = ecPublicKeyFromJWK(k)
+		default:
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.mu.Unlock()
+	return nil
+}
+
+func (v *Verifier) keyForKid(kid string) (crypto.PublicKey, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	k, ok := v.keys[kid]
+	return k, ok
+}
+
+// Verify parses and validates tokenString, returning its claims on success.
+// Verification checks, in order: the "alg" header is in the configured
+// allowlist, the signature is valid for the resolved key, and the
+// issuer/audience claims match if configured.
+func (v *Verifier) Verify(tokenString string, hmacSecret []byte) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+		alg := t.Method.Alg()
+		if len(v.c.SigningAlgorithms) > 0 && !v.c.allowedAlgorithm(alg) {
+			return nil, errors.Errorf("signing algorithm %q is not allowed", alg)
+		}
+
+		switch t.Method.(type) {
+		case *jwt.SigningMethodHMAC:
+			if hmacSecret == nil {
+				return nil, errors.New("no shared secret configured for HMAC tokens")
+			}
+			return hmacSecret, nil
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+			kid, _ := t.Header["kid"].(string)
+			key, ok := v.keyForKid(kid)
+			if !ok {
+				return nil, errors.Errorf("unknown key id %q", kid)
+			}
+			return key, nil
+		default:
+			return nil, errors.Errorf("unsupported signing method %q", alg)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	if v.c.ExpectedIssuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.c.ExpectedIssuer {
+			return nil, errors.Errorf("unexpected issuer %q", iss)
+		}
+	}
+	if v.c.ExpectedAudience != "" && !claims.VerifyAudience(v.c.ExpectedAudience, true) {
+		return nil, errors.Errorf("token audience does not include %q", v.c.ExpectedAudience)
+	}
+
+	return claims, nil
+}
+
+func rsaPublicKeyFromJWK(k JWK) (*rsa.PublicKey, error) {
+	n, err := base64URLBigInt(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %s", err)
+	}
+	e, err := base64URLInt(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %s", err)
+	}
+	return &rsa.PublicKey{N: n, E: e}, nil
+}
+
+// ecPublicKeyFromJWK reconstructs an EC public key from a JWK's crv/x/y
+// fields, as used by ES256 tokens. Only the P-256 curve is supported,
+// matching the ES256 algorithm this package advertises; other curves
+// are rejected rather than silently mishandled.
+func ecPublicKeyFromJWK(k JWK) (*ecdsa.PublicKey, error) {
+	if k.Crv != "P-256" {
+		return nil, fmt.Errorf("unsupported EC curve %q", k.Crv)
+	}
+	x, err := base64URLBigInt(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("invalid x coordinate: %s", err)
+	}
+	y, err := base64URLBigInt(k.Y)
+	if err != nil {
+		return nil, fmt.Errorf("invalid y coordinate: %s", err)
+	}
+	return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+}
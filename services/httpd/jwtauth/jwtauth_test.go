@@ -0,0 +1,193 @@
+package jwtauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func jwkFromKey(kid string, key *rsa.PrivateKey) JWK {
+	return JWK{
+		Kid: kid,
+		Kty: "RSA",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+	}
+}
+
+func newECKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func jwkFromECKey(kid string, key *ecdsa.PrivateKey) JWK {
+	return JWK{
+		Kid: kid,
+		Kty: "EC",
+		Alg: "ES256",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(key.PublicKey.X.Bytes()),
+		Y:   base64.RawURLEncoding.EncodeToString(key.PublicKey.Y.Bytes()),
+	}
+}
+
+func newJWKSServer(t *testing.T, keys ...JWK) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jwks{Keys: keys})
+	}))
+}
+
+func TestVerifier_RS256(t *testing.T) {
+	key := newRSAKey(t)
+	ts := newJWKSServer(t, jwkFromKey("key-1", key))
+	defer ts.Close()
+
+	v := NewVerifier(Config{
+		JWKSURL:           ts.URL,
+		SigningAlgorithms: []string{"RS256"},
+		ExpectedIssuer:    "https://issuer.example.com",
+	})
+	if err := v.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer v.Close()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss": "https://issuer.example.com",
+		"exp": time.Now().Add(time.Minute).Unix(),
+	})
+	token.Header["kid"] = "key-1"
+	tokenString, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.Verify(tokenString, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifier_ES256(t *testing.T) {
+	key := newECKey(t)
+	ts := newJWKSServer(t, jwkFromECKey("ec-key-1", key))
+	defer ts.Close()
+
+	v := NewVerifier(Config{
+		JWKSURL:           ts.URL,
+		SigningAlgorithms: []string{"ES256"},
+		ExpectedIssuer:    "https://issuer.example.com",
+	})
+	if err := v.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer v.Close()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": "https://issuer.example.com",
+		"exp": time.Now().Add(time.Minute).Unix(),
+	})
+	token.Header["kid"] = "ec-key-1"
+	tokenString, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.Verify(tokenString, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifier_RejectsDisallowedAlgorithm(t *testing.T) {
+	v := NewVerifier(Config{
+		SigningAlgorithms: []string{"RS256"},
+	})
+
+	secret := []byte("secret")
+	token := jwt.NewWithClaims(jwt.SigningMethodHS512, jwt.MapClaims{
+		"exp": time.Now().Add(time.Minute).Unix(),
+	})
+	tokenString, err := token.SignedString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.Verify(tokenString, secret); err == nil {
+		t.Fatal("expected error for disallowed algorithm")
+	}
+}
+
+func TestVerifier_JWKSRotation(t *testing.T) {
+	key1 := newRSAKey(t)
+	key2 := newRSAKey(t)
+	ts := newJWKSServer(t, jwkFromKey("key-1", key1))
+	defer ts.Close()
+
+	v := NewVerifier(Config{
+		JWKSURL:           ts.URL,
+		SigningAlgorithms: []string{"RS256"},
+		RefreshInterval:   time.Hour,
+	})
+	if err := v.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer v.Close()
+
+	// Rotate to a new key, as if the IdP rotated its signing key.
+	ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jwks{Keys: []JWK{jwkFromKey("key-2", key2)}})
+	})
+	if err := v.refresh(); err != nil {
+		t.Fatal(err)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"exp": time.Now().Add(time.Minute).Unix(),
+	})
+	token.Header["kid"] = "key-2"
+	tokenString, err := token.SignedString(key2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.Verify(tokenString, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// The old key should no longer validate tokens signed with it.
+	oldToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"exp": time.Now().Add(time.Minute).Unix(),
+	})
+	oldToken.Header["kid"] = "key-1"
+	oldTokenString, err := oldToken.SignedString(key1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Verify(oldTokenString, nil); err == nil {
+		t.Fatal("expected old key to be rejected after rotation")
+	}
+}
@@ -0,0 +1,186 @@
+// Package tlsreload builds *tls.Config values for outbound mTLS
+// connections from PEM files on disk, and keeps them current as those
+// files are rotated. Unlike the TLSConfig copies embedded directly in
+// packages such as grpcalert and kafka (which load their certificate and
+// CA pool once, at service startup), a Loader here re-reads its files
+// whenever they change, so an operator can roll a client certificate or
+// CA bundle without restarting Kapacitor.
+package tlsreload
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Config describes the TLS material for an outbound connection and is
+// embedded by any handler kind that accepts a `tls` option.
+type Config struct {
+	CAFile             string `toml:"ca-file" override:"ca-file"`
+	CertFile           string `toml:"cert-file" override:"cert-file"`
+	KeyFile            string `toml:"key-file" override:"key-file"`
+	ServerName         string `toml:"server-name" override:"server-name"`
+	InsecureSkipVerify bool   `toml:"insecure-skip-verify" override:"insecure-skip-verify"`
+}
+
+func (c Config) isZero() bool {
+	return c == Config{}
+}
+
+func (c Config) Validate() error {
+	if c.isZero() {
+		return nil
+	}
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return errors.New("cert-file and key-file must both be set or both be empty")
+	}
+	return nil
+}
+
+// Loader builds a *tls.Config from Config's PEM files and re-reads them
+// whenever their modification time changes, either because Get was
+// called after a file was rotated or because NotifyReload's signal
+// handler forced a refresh.
+type Loader struct {
+	c Config
+
+	mu       sync.Mutex
+	tlsConf  *tls.Config
+	modTimes map[string]time.Time
+
+	stopReload chan struct{}
+}
+
+// NewLoader returns a Loader for c. If c is the zero value, Get always
+// returns a nil *tls.Config, which tells callers to use a plaintext
+// connection.
+func NewLoader(c Config) *Loader {
+	return &Loader{c: c}
+}
+
+// Get returns the current *tls.Config, reloading it from disk first if
+// any of the configured files have a newer modification time than the
+// last load.
+func (l *Loader) Get() (*tls.Config, error) {
+	if l.c.isZero() {
+		return nil, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	changed, err := l.filesChanged()
+	if err != nil {
+		return nil, err
+	}
+	if l.tlsConf != nil && !changed {
+		return l.tlsConf, nil
+	}
+
+	tc, modTimes, err := l.load()
+	if err != nil {
+		return nil, err
+	}
+	l.tlsConf = tc
+	l.modTimes = modTimes
+	return tc, nil
+}
+
+func (l *Loader) filesChanged() (bool, error) {
+	if l.modTimes == nil {
+		return true, nil
+	}
+	for _, path := range l.files() {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return false, err
+		}
+		if !info.ModTime().Equal(l.modTimes[path]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (l *Loader) files() []string {
+	return []string{l.c.CAFile, l.c.CertFile, l.c.KeyFile}
+}
+
+func (l *Loader) load() (*tls.Config, map[string]time.Time, error) {
+	modTimes := make(map[string]time.Time)
+	for _, path := range l.files() {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		modTimes[path] = info.ModTime()
+	}
+
+	tc := &tls.Config{
+		ServerName:         l.c.ServerName,
+		InsecureSkipVerify: l.c.InsecureSkipVerify,
+	}
+	if l.c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(l.c.CertFile, l.c.KeyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+	if l.c.CAFile != "" {
+		pem, err := ioutil.ReadFile(l.c.CAFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, nil, errors.New("tlsreload: failed to parse ca-file")
+		}
+		tc.RootCAs = pool
+	}
+	return tc, modTimes, nil
+}
+
+// NotifyReload installs a SIGHUP handler that forces the next Get to
+// reload from disk, for operators who trigger rotation explicitly rather
+// than relying on the mtime poll in Get. Close stops listening.
+func (l *Loader) NotifyReload() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+	l.stopReload = make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-sigCh:
+				l.mu.Lock()
+				l.modTimes = nil
+				l.mu.Unlock()
+			case <-l.stopReload:
+				signal.Stop(sigCh)
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the SIGHUP handler installed by NotifyReload, if any.
+func (l *Loader) Close() error {
+	if l.stopReload != nil {
+		close(l.stopReload)
+	}
+	return nil
+}
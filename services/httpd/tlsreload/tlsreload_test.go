@@ -0,0 +1,117 @@
+package tlsreload
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTempCA(t *testing.T, dir, name, contents string) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// testCAPEM is a syntactically valid (if not cryptographically
+// meaningful) self-signed certificate, good enough to exercise the CA
+// pool loading path without a real key pair.
+const testCAPEM = `-----BEGIN CERTIFICATE-----
+MIIDBTCCAe2gAwIBAgIUbufwqew+dZL/bOlIWDi28YKv3cswDQYJKoZIhvcNAQEL
+BQAwEjEQMA4GA1UEAwwHdGVzdC1jYTAeFw0yNjA3MjYwODA0NDhaFw0zNjA3MjMw
+ODA0NDhaMBIxEDAOBgNVBAMMB3Rlc3QtY2EwggEiMA0GCSqGSIb3DQEBAQUAA4IB
+DwAwggEKAoIBAQCb89/jLqOpSsU9HhK3YfNe6C2yjKZvKfUGSal04zMK2yFITKkX
+u23MJEwWlLZGPMGUPnSLfxE+79fegLzj1TNmLknQTap6Hnd4ID5TtuJkOJkGdUnn
+GaoBRRlPK72IN7T4pJY4BqCg/Iew1X+5CTAR2YDYLTX3pKdU1Ow7VkUs8fVL+1C4
+L76lfho+ubwf+s46Ksb7utLkTR+1G2ip6UAteIxqX8nvImWUx20tibgkd1CauYgV
+ZJG8Mkj0g4rA3SbFhFky56hjZQ9Nop5YdrSm4Tg8mxCN++wBkOJRGqPf4V+mtP0V
+BRsxTlqgNGx//8qB1j1bt+VAFx7Uu0zi2kUBAgMBAAGjUzBRMB0GA1UdDgQWBBSZ
+f6yMgf9eKPehr6p047Ek44RiBTAfBgNVHSMEGDAWgBSZf6yMgf9eKPehr6p047Ek
+44RiBTAPBgNVHRMBAf8EBTADAQH/MA0GCSqGSIb3DQEBCwUAA4IBAQBqMtvlHVaq
+uCbgxwJNKdZKxXyTOPewUGreaBBgJ+inquad4awMAFG+Ug2xaJodXIhTPb79MxjN
+fnrDxcncUcu23mJsCfI0A8DKXf8k5AGvl5t96VLRzyyhSo6N4vgGpsrZO3bbd97r
+Hj/M9ITlF9ZnObWd3658Bn+6A9X11Ke+9EZjszxP8Qax+L0x7Bf1iApvptCUuczi
+gbWIyn1/sYKnCrQkqD3FhpyVJlNDoCRZZgorbglGET1yDmD4NSpfWIwJcpPWpYVz
+vCL54k7rCnd3YrEnnwptQm5mJ7wfcPfO7Ccn7xd/CN8TXQUY12SbJaI2A3+HwOEp
+1ruJhweBfDQ2
+-----END CERTIFICATE-----
+`
+
+func TestLoader_ZeroValueReturnsNilConfig(t *testing.T) {
+	l := NewLoader(Config{})
+	tlsConf, err := l.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tlsConf != nil {
+		t.Fatalf("expected a nil *tls.Config for an unconfigured Loader, got %+v", tlsConf)
+	}
+}
+
+func TestLoader_ReloadsWhenCAFileChanges(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tlsreload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	caPath := writeTempCA(t, dir, "ca.pem", testCAPEM)
+
+	l := NewLoader(Config{CAFile: caPath})
+	first, err := l.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.RootCAs == nil {
+		t.Fatal("expected a populated RootCAs pool")
+	}
+
+	second, err := l.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Fatal("expected Get to return the cached config when the file hasn't changed")
+	}
+
+	// Bump the file's mtime into the future so the poll is guaranteed to
+	// observe a change even on filesystems with coarse mtime resolution.
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(caPath, future, future); err != nil {
+		t.Fatal(err)
+	}
+
+	third, err := l.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == third {
+		t.Fatal("expected Get to reload and return a new config after the CA file changed")
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	cases := []struct {
+		name    string
+		c       Config
+		wantErr bool
+	}{
+		{"zero value", Config{}, false},
+		{"cert without key", Config{CertFile: "a.pem"}, true},
+		{"key without cert", Config{KeyFile: "a.pem"}, true},
+		{"cert and key", Config{CertFile: "a.pem", KeyFile: "a.key"}, false},
+		{"ca only", Config{CAFile: "ca.pem"}, false},
+	}
+	for _, tc := range cases {
+		err := tc.c.Validate()
+		if tc.wantErr && err == nil {
+			t.Errorf("%s: expected an error, got nil", tc.name)
+		}
+		if !tc.wantErr && err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+	}
+}
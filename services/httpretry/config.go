@@ -0,0 +1,78 @@
+// Package httpretry provides an http.RoundTripper that retries
+// transient failures with truncated exponential backoff and full
+// jitter, shared by the webhook-style alert handlers (discord, msteams,
+// mattermost) so each doesn't reimplement its own retry loop.
+package httpretry
+
+import (
+	"errors"
+
+	"github.com/influxdata/toml"
+)
+
+// Config configures retry behavior for one handler's outbound requests.
+type Config struct {
+	// MaxAttempts is the total number of attempts, including the first;
+	// 1 (the default) means no retries.
+	MaxAttempts int `toml:"max-attempts" override:"max-attempts"`
+	// InitialInterval is the base delay before the first retry.
+	InitialInterval toml.Duration `toml:"initial-interval" override:"initial-interval"`
+	// MaxInterval caps the computed backoff delay.
+	MaxInterval toml.Duration `toml:"max-interval" override:"max-interval"`
+	// Multiplier grows the delay between successive retries.
+	Multiplier float64 `toml:"multiplier" override:"multiplier"`
+	// Jitter, when true, picks a random delay in [0, computed] instead of
+	// sleeping for exactly the computed delay ("full jitter").
+	Jitter bool `toml:"jitter" override:"jitter"`
+	// RetryOn lists the conditions that trigger a retry: "5xx", "429",
+	// and/or "timeout" (a network-level timeout or connection error). An
+	// empty list means no condition is retryable even if MaxAttempts > 1.
+	RetryOn []string `toml:"retry-on" override:"retry-on"`
+	// DeadLetterTopic, if set, names a destination (interpreted by the
+	// caller, e.g. a downstream handler link) that the request body is
+	// forwarded to once every attempt has failed.
+	DeadLetterTopic string `toml:"dead-letter-topic" override:"dead-letter-topic"`
+}
+
+func NewConfig() Config {
+	return Config{
+		MaxAttempts:     1,
+		InitialInterval: toml.Duration(defaultInitialInterval),
+		MaxInterval:     toml.Duration(defaultMaxInterval),
+		Multiplier:      2,
+	}
+}
+
+func (c Config) isZero() bool {
+	return c.MaxAttempts == 0 && c.InitialInterval == 0 && c.MaxInterval == 0 &&
+		c.Multiplier == 0 && !c.Jitter && len(c.RetryOn) == 0 && c.DeadLetterTopic == ""
+}
+
+func (c Config) Validate() error {
+	if c.isZero() {
+		return nil
+	}
+	if c.MaxAttempts < 1 {
+		return errors.New("httpretry: max-attempts must be at least 1")
+	}
+	if c.Multiplier != 0 && c.Multiplier < 1 {
+		return errors.New("httpretry: multiplier must be at least 1")
+	}
+	for _, cond := range c.RetryOn {
+		switch cond {
+		case "5xx", "429", "timeout":
+		default:
+			return errors.New("httpretry: unknown retry-on condition " + cond)
+		}
+	}
+	return nil
+}
+
+func (c Config) retriesOn(cond string) bool {
+	for _, c := range c.RetryOn {
+		if c == cond {
+			return true
+		}
+	}
+	return false
+}
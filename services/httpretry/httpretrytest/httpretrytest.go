@@ -0,0 +1,52 @@
+// Package httpretrytest provides fixtures shared by the webhook-style
+// alert handler packages (discord, msteams, mattermost, ...) for
+// exercising their httpretry.Config wiring, so each package's own test
+// file doesn't have to redefine the same flaky/always-failing server
+// and retry config boilerplate.
+package httpretrytest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/kapacitor/services/httpretry"
+	"github.com/influxdata/toml"
+)
+
+// Config returns an httpretry.Config tuned for fast tests: up to
+// maxAttempts attempts on 5xx responses, with negligible backoff.
+func Config(maxAttempts int) httpretry.Config {
+	return httpretry.Config{
+		MaxAttempts:     maxAttempts,
+		InitialInterval: toml.Duration(time.Millisecond),
+		MaxInterval:     toml.Duration(time.Millisecond),
+		Multiplier:      2,
+		RetryOn:         []string{"5xx"},
+	}
+}
+
+// FlakyServer responds failStatus to the first failCount requests and
+// successStatus to every request after, so a caller's retry logic can
+// be exercised against it. The returned counter tracks the total
+// number of requests received.
+func FlakyServer(failCount int, failStatus, successStatus int) (*httptest.Server, *int32) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if int(atomic.AddInt32(&calls, 1)) <= failCount {
+			w.WriteHeader(failStatus)
+			return
+		}
+		w.WriteHeader(successStatus)
+	}))
+	return ts, &calls
+}
+
+// AlwaysFailingServer responds status to every request, for exercising
+// dead-letter behavior once retries are exhausted.
+func AlwaysFailingServer(status int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+}
@@ -0,0 +1,196 @@
+package httpretry
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultInitialInterval = 500 * time.Millisecond
+	defaultMaxInterval     = 30 * time.Second
+)
+
+// Transport wraps Next (http.DefaultTransport if nil) with retry
+// behavior configured by Config. On final failure, if DeadLetter is set,
+// it's called with the original request and body so the caller can
+// forward it to a dead-letter destination.
+type Transport struct {
+	Config Config
+	Next   http.RoundTripper
+
+	// Sleep and Rand are overridable for deterministic tests; they
+	// default to time.Sleep and math/rand's global source.
+	Sleep func(time.Duration)
+	Rand  func() float64
+
+	DeadLetter func(req *http.Request, body []byte, lastErr error) error
+}
+
+func (t *Transport) next() http.RoundTripper {
+	if t.Next != nil {
+		return t.Next
+	}
+	return http.DefaultTransport
+}
+
+func (t *Transport) sleep(d time.Duration) {
+	if t.Sleep != nil {
+		t.Sleep(d)
+		return
+	}
+	time.Sleep(d)
+}
+
+func (t *Transport) rand() float64 {
+	if t.Rand != nil {
+		return t.Rand()
+	}
+	return rand.Float64()
+}
+
+// backoff computes the truncated-exponential-with-full-jitter delay
+// before attempt (0-indexed: the delay before the 2nd attempt uses
+// attempt=0).
+func (t *Transport) backoff(attempt int) time.Duration {
+	initial := time.Duration(t.Config.InitialInterval)
+	max := time.Duration(t.Config.MaxInterval)
+	mult := t.Config.Multiplier
+	if mult == 0 {
+		mult = 2
+	}
+
+	computed := float64(initial) * math.Pow(mult, float64(attempt))
+	if computed > float64(max) {
+		computed = float64(max)
+	}
+	if !t.Config.Jitter {
+		return time.Duration(computed)
+	}
+	return time.Duration(t.rand() * computed)
+}
+
+// retryableStatus reports whether resp's status code matches a
+// configured retry-on condition.
+func (t *Transport) retryableStatus(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return t.Config.retriesOn("429")
+	}
+	if resp.StatusCode >= 500 {
+		return t.Config.retriesOn("5xx")
+	}
+	return false
+}
+
+func (t *Transport) retryableError(err error) bool {
+	if !t.Config.retriesOn("timeout") {
+		return false
+	}
+	_, ok := err.(net.Error)
+	return ok
+}
+
+// retryAfter parses resp's Retry-After header, returning (delay, true)
+// if present and valid. Retry-After as an HTTP-date is not supported;
+// only the delay-seconds form is.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	secs, err := strconv.Atoi(v)
+	if err != nil || secs < 0 {
+		return 0, false
+	}
+	return time.Duration(secs) * time.Second, true
+}
+
+// RoundTrip sends req, retrying per Config on a retryable failure. req's
+// body is buffered up front so it can be resent on each attempt.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	}
+
+	maxAttempts := t.Config.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var lastResp *http.Response
+	var lastErr error
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if attempt > 0 {
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+			req.ContentLength = int64(len(body))
+		}
+
+		resp, err := t.next().RoundTrip(req)
+		if err != nil {
+			lastErr = err
+			lastResp = nil
+			if attempt == maxAttempts-1 || !t.retryableError(err) {
+				break
+			}
+			t.sleep(t.backoff(attempt))
+			continue
+		}
+
+		if !t.retryableStatus(resp) {
+			return resp, nil
+		}
+
+		lastResp = resp
+		lastErr = nil
+		if attempt == maxAttempts-1 {
+			break
+		}
+
+		delay, ok := retryAfter(resp)
+		if !ok {
+			delay = t.backoff(attempt)
+		}
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		t.sleep(delay)
+	}
+
+	if t.DeadLetter != nil {
+		var dlErr error
+		if lastErr != nil {
+			dlErr = lastErr
+		} else if lastResp != nil {
+			dlErr = statusError(lastResp)
+		}
+		if dlErr != nil {
+			t.DeadLetter(req, body, dlErr)
+		}
+	}
+
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return lastResp, nil
+}
+
+type statusErr struct {
+	status string
+}
+
+func (e *statusErr) Error() string { return "httpretry: request failed with " + e.status }
+
+func statusError(resp *http.Response) error {
+	return &statusErr{status: resp.Status}
+}
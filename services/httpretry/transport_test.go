@@ -0,0 +1,190 @@
+package httpretry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/influxdata/toml"
+)
+
+func noSleep(t *Transport) {
+	t.Sleep = func(time.Duration) {}
+	t.Rand = func() float64 { return 1 }
+}
+
+func TestTransport_RetriesOnRetryableStatusThenSucceeds(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	rt := &Transport{Config: Config{
+		MaxAttempts:     3,
+		InitialInterval: toml.Duration(time.Millisecond),
+		MaxInterval:     toml.Duration(time.Millisecond),
+		Multiplier:      2,
+		RetryOn:         []string{"5xx"},
+	}}
+	noSleep(rt)
+
+	client := &http.Client{Transport: rt}
+	resp, err := client.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected eventual 200, got %d", resp.StatusCode)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 attempts (2 failures + 1 success), got %d", calls)
+	}
+}
+
+func TestTransport_GivesUpAfterMaxAttempts(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	rt := &Transport{Config: Config{
+		MaxAttempts:     3,
+		InitialInterval: toml.Duration(time.Millisecond),
+		MaxInterval:     toml.Duration(time.Millisecond),
+		Multiplier:      2,
+		RetryOn:         []string{"5xx"},
+	}}
+	noSleep(rt)
+
+	client := &http.Client{Transport: rt}
+	resp, err := client.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected the final failing status to be returned, got %d", resp.StatusCode)
+	}
+	if calls != 3 {
+		t.Fatalf("expected exactly 3 attempts, got %d", calls)
+	}
+}
+
+func TestTransport_HonorsRetryAfterHeader(t *testing.T) {
+	var calls int32
+	var slept time.Duration
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	rt := &Transport{Config: Config{
+		MaxAttempts:     2,
+		InitialInterval: toml.Duration(time.Millisecond),
+		MaxInterval:     toml.Duration(time.Millisecond),
+		Multiplier:      2,
+		RetryOn:         []string{"429"},
+	}}
+	rt.Sleep = func(d time.Duration) { slept = d }
+	rt.Rand = func() float64 { return 1 }
+
+	client := &http.Client{Transport: rt}
+	resp, err := client.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if slept != 2*time.Second {
+		t.Fatalf("expected Retry-After's 2s to be honored, slept %v", slept)
+	}
+}
+
+func TestTransport_NonRetryableStatusIsNotRetried(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	rt := &Transport{Config: Config{
+		MaxAttempts:     3,
+		InitialInterval: toml.Duration(time.Millisecond),
+		RetryOn:         []string{"5xx"},
+	}}
+	noSleep(rt)
+
+	client := &http.Client{Transport: rt}
+	resp, err := client.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if calls != 1 {
+		t.Fatalf("expected exactly 1 attempt for a non-retryable status, got %d", calls)
+	}
+}
+
+func TestTransport_DeadLetterCalledOnFinalFailure(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	var deadLettered []byte
+	rt := &Transport{Config: Config{
+		MaxAttempts:     2,
+		InitialInterval: toml.Duration(time.Millisecond),
+		MaxInterval:     toml.Duration(time.Millisecond),
+		Multiplier:      2,
+		RetryOn:         []string{"5xx"},
+	}}
+	noSleep(rt)
+	rt.DeadLetter = func(req *http.Request, body []byte, lastErr error) error {
+		deadLettered = body
+		return nil
+	}
+
+	client := &http.Client{Transport: rt}
+	req, _ := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader("payload"))
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if string(deadLettered) != "payload" {
+		t.Fatalf("expected the original body to be forwarded to the dead letter, got %q", deadLettered)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	c := NewConfig()
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	c.RetryOn = []string{"bogus"}
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected an error for an unknown retry-on condition")
+	}
+}
@@ -0,0 +1,105 @@
+package influxdbhealth
+
+import "time"
+
+// State is a circuit breaker's current position.
+type State string
+
+const (
+	Closed   State = "closed"
+	Open     State = "open"
+	HalfOpen State = "half-open"
+)
+
+// Breaker is a per-URL circuit breaker: it opens after FailureThreshold
+// consecutive failures, taking the URL out of rotation, and moves to
+// half-open once CoolDown has elapsed.
+type Breaker struct {
+	FailureThreshold int
+	CoolDown         time.Duration
+	Now              func() time.Time
+
+	state               State
+	consecutiveFailures int
+	openedAt            time.Time
+	lastErr             string
+	probing             bool
+}
+
+func NewBreaker(failureThreshold int, coolDown time.Duration) *Breaker {
+	return &Breaker{
+		FailureThreshold: failureThreshold,
+		CoolDown:         coolDown,
+		Now:              time.Now,
+		state:            Closed,
+	}
+}
+
+// Allow reports whether a request should currently be sent to the URL
+// this breaker guards. An open breaker transitions to half-open once
+// CoolDown has elapsed since it opened; a half-open breaker allows every
+// request until the next recorded result closes or reopens it.
+func (b *Breaker) Allow() bool {
+	switch b.state {
+	case Closed, HalfOpen:
+		return true
+	case Open:
+		if b.Now().Sub(b.openedAt) >= b.CoolDown {
+			b.state = HalfOpen
+			b.probing = true
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+// RecordSuccess marks a successful request/health check, closing the
+// breaker if it was open or half-open.
+func (b *Breaker) RecordSuccess() {
+	b.consecutiveFailures = 0
+	b.lastErr = ""
+	b.state = Closed
+	b.probing = false
+}
+
+// RecordFailure marks a failed request/health check, opening the breaker
+// once FailureThreshold consecutive failures have been observed (or
+// immediately, if the failure was the half-open probe).
+func (b *Breaker) RecordFailure(err error) {
+	if err != nil {
+		b.lastErr = err.Error()
+	}
+	if b.state == HalfOpen {
+		b.open()
+		return
+	}
+
+	b.consecutiveFailures++
+	if b.consecutiveFailures >= b.FailureThreshold {
+		b.open()
+	}
+}
+
+func (b *Breaker) open() {
+	b.state = Open
+	b.openedAt = b.Now()
+	b.probing = false
+}
+
+// Status is a point-in-time snapshot of a breaker's state.
+type Status struct {
+	State               State  `json:"state"`
+	Healthy             bool   `json:"healthy"`
+	ConsecutiveFailures int    `json:"consecutiveFailures"`
+	LastError           string `json:"lastError,omitempty"`
+}
+
+func (b *Breaker) Status() Status {
+	return Status{
+		State:               b.state,
+		Healthy:             b.state != Open,
+		ConsecutiveFailures: b.consecutiveFailures,
+		LastError:           b.lastErr,
+	}
+}
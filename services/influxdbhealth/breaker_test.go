@@ -0,0 +1,70 @@
+package influxdbhealth
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestBreaker_OpensAfterThreshold(t *testing.T) {
+	b := NewBreaker(3, time.Minute)
+	for i := 0; i < 2; i++ {
+		if !b.Allow() {
+			t.Fatal("expected breaker to allow requests before threshold")
+		}
+		b.RecordFailure(fmt.Errorf("fail"))
+	}
+	if b.Status().State != Closed {
+		t.Fatalf("expected breaker to still be closed, got %s", b.Status().State)
+	}
+
+	b.RecordFailure(fmt.Errorf("fail"))
+	if b.Status().State != Open {
+		t.Fatalf("expected breaker to open after threshold, got %s", b.Status().State)
+	}
+	if b.Allow() {
+		t.Fatal("expected breaker to block requests while open")
+	}
+}
+
+func TestBreaker_HalfOpenProbeAfterCoolDown(t *testing.T) {
+	now := time.Now()
+	b := NewBreaker(1, 10*time.Second)
+	b.Now = func() time.Time { return now }
+
+	b.RecordFailure(fmt.Errorf("fail"))
+	if b.Status().State != Open {
+		t.Fatal("expected breaker to open immediately at threshold 1")
+	}
+	if b.Allow() {
+		t.Fatal("expected breaker to block before cool-down elapses")
+	}
+
+	now = now.Add(11 * time.Second)
+	if !b.Allow() {
+		t.Fatal("expected breaker to allow a probe after cool-down")
+	}
+	if b.Status().State != HalfOpen {
+		t.Fatalf("expected breaker to be half-open, got %s", b.Status().State)
+	}
+
+	b.RecordSuccess()
+	if b.Status().State != Closed {
+		t.Fatalf("expected successful probe to close breaker, got %s", b.Status().State)
+	}
+}
+
+func TestBreaker_FailedProbeReopensImmediately(t *testing.T) {
+	now := time.Now()
+	b := NewBreaker(1, 10*time.Second)
+	b.Now = func() time.Time { return now }
+
+	b.RecordFailure(fmt.Errorf("fail"))
+	now = now.Add(11 * time.Second)
+	b.Allow()
+
+	b.RecordFailure(fmt.Errorf("still failing"))
+	if b.Status().State != Open {
+		t.Fatalf("expected failed probe to reopen the breaker, got %s", b.Status().State)
+	}
+}
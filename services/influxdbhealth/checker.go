@@ -0,0 +1,134 @@
+// Package influxdbhealth actively health-checks every URL in a multi-URL
+// InfluxDB cluster config and guards each one with a circuit breaker, so
+// batch task queries and stream subscription writes can skip a dead
+// backend automatically instead of waiting for an operator-triggered
+// ConfigUpdate.
+package influxdbhealth
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Pinger checks a single InfluxDB URL's liveness, typically by issuing a
+// GET to its /ping endpoint.
+type Pinger func(url string) error
+
+// Config controls one cluster's health checker.
+type Config struct {
+	HealthCheckInterval time.Duration
+	FailureThreshold    int
+	CoolDown            time.Duration
+}
+
+func NewConfig() Config {
+	return Config{
+		HealthCheckInterval: 10 * time.Second,
+		FailureThreshold:    3,
+		CoolDown:            30 * time.Second,
+	}
+}
+
+// Checker health-checks every URL in a cluster on an interval, maintaining
+// a Breaker per URL.
+type Checker struct {
+	c      Config
+	pinger Pinger
+	logger *log.Logger
+
+	mu       sync.RWMutex
+	breakers map[string]*Breaker
+
+	closing chan struct{}
+}
+
+func NewChecker(c Config, urls []string, pinger Pinger, l *log.Logger) *Checker {
+	breakers := make(map[string]*Breaker, len(urls))
+	for _, u := range urls {
+		breakers[u] = NewBreaker(c.FailureThreshold, c.CoolDown)
+	}
+	return &Checker{
+		c:        c,
+		pinger:   pinger,
+		logger:   l,
+		breakers: breakers,
+		closing:  make(chan struct{}),
+	}
+}
+
+func (c *Checker) Open() error {
+	go c.run()
+	return nil
+}
+
+func (c *Checker) Close() error {
+	close(c.closing)
+	return nil
+}
+
+func (c *Checker) run() {
+	ticker := time.NewTicker(c.c.HealthCheckInterval)
+	defer ticker.Stop()
+	c.checkAll()
+	for {
+		select {
+		case <-c.closing:
+			return
+		case <-ticker.C:
+			c.checkAll()
+		}
+	}
+}
+
+func (c *Checker) checkAll() {
+	c.mu.RLock()
+	urls := make([]string, 0, len(c.breakers))
+	for u := range c.breakers {
+		urls = append(urls, u)
+	}
+	c.mu.RUnlock()
+
+	for _, u := range urls {
+		c.check(u)
+	}
+}
+
+func (c *Checker) check(url string) {
+	c.mu.RLock()
+	b := c.breakers[url]
+	c.mu.RUnlock()
+
+	if !b.Allow() {
+		return
+	}
+
+	if err := c.pinger(url); err != nil {
+		b.RecordFailure(err)
+		c.logger.Println("E! influxdb health check failed for", url, ":", err)
+		return
+	}
+	b.RecordSuccess()
+}
+
+// Allow reports whether url is currently in rotation for queries/writes.
+func (c *Checker) Allow(url string) bool {
+	c.mu.RLock()
+	b, ok := c.breakers[url]
+	c.mu.RUnlock()
+	if !ok {
+		return true
+	}
+	return b.Allow()
+}
+
+// Statuses returns a snapshot of every URL's breaker state, keyed by URL.
+func (c *Checker) Statuses() map[string]Status {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make(map[string]Status, len(c.breakers))
+	for u, b := range c.breakers {
+		out[u] = b.Status()
+	}
+	return out
+}
@@ -0,0 +1,36 @@
+package influxdbhealth
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"testing"
+	"time"
+)
+
+func TestChecker_MarksURLUnhealthyAfterFailures(t *testing.T) {
+	failing := map[string]bool{"http://bad:8086": true}
+	pinger := func(url string) error {
+		if failing[url] {
+			return fmt.Errorf("connection refused")
+		}
+		return nil
+	}
+
+	c := NewChecker(Config{FailureThreshold: 2, CoolDown: time.Hour}, []string{"http://good:8086", "http://bad:8086"}, pinger, log.New(ioutil.Discard, "", 0))
+
+	c.checkAll()
+	c.checkAll()
+
+	if !c.Allow("http://good:8086") {
+		t.Fatal("expected good URL to remain in rotation")
+	}
+	if c.Allow("http://bad:8086") {
+		t.Fatal("expected bad URL to be taken out of rotation")
+	}
+
+	statuses := c.Statuses()
+	if statuses["http://bad:8086"].ConsecutiveFailures != 2 {
+		t.Fatalf("unexpected failure count: %+v", statuses["http://bad:8086"])
+	}
+}
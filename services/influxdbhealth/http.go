@@ -0,0 +1,40 @@
+package influxdbhealth
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Registry looks up a cluster's Checker by name, for mounting a single
+// handler across every configured InfluxDB cluster.
+type Registry interface {
+	Checker(cluster string) (*Checker, bool)
+}
+
+// Handler implements GET /kapacitor/v1/influxdb/{cluster}/health.
+type Handler struct {
+	Registry Registry
+}
+
+func NewHandler(r Registry) *Handler {
+	return &Handler{Registry: r}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request, cluster string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	checker, ok := h.Registry.Checker(cluster)
+	if !ok {
+		http.Error(w, "no such influxdb cluster: "+cluster, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"cluster": cluster,
+		"urls":    checker.Statuses(),
+	})
+}
@@ -0,0 +1,78 @@
+// Package jira implements the "jira" alert handler kind: it opens a
+// Jira issue on a CRITICAL alert transition, comments on the same issue
+// for subsequent re-triggers, and transitions it on the matching OK.
+package jira
+
+import "github.com/pkg/errors"
+
+// AuthType selects how the service authenticates against the Jira API.
+type AuthType string
+
+const (
+	// AuthTypeBasic authenticates with a username and password (or an
+	// API token used as the password, for Jira Cloud).
+	AuthTypeBasic AuthType = "basic"
+	// AuthTypePAT authenticates with a personal access token as a
+	// bearer credential, for Jira Server/Data Center.
+	AuthTypePAT AuthType = "pat"
+)
+
+// Config is the jira service section.
+type Config struct {
+	// Whether Jira integration is enabled.
+	Enabled bool `toml:"enabled" override:"enabled"`
+	// Whether this section is used as the default handler for all alerts.
+	Global bool `toml:"global" override:"global"`
+	// Only post a message when the alert state changes.
+	StateChangesOnly bool `toml:"state-changes-only" override:"state-changes-only"`
+
+	// URL is the base URL of the Jira instance, e.g.
+	// https://jira.example.com.
+	URL string `toml:"url" override:"url"`
+
+	// AuthType is either "basic" or "pat".
+	AuthType AuthType `toml:"auth-type" override:"auth-type"`
+	Username string   `toml:"username" override:"username"`
+	Password string   `toml:"password" override:"password,redact"`
+	Token    string   `toml:"token" override:"token,redact"`
+
+	// Project is the default project key issues are created under.
+	Project string `toml:"project" override:"project"`
+	// IssueType is the default issue type name, e.g. "Bug".
+	IssueType string `toml:"issue-type" override:"issue-type"`
+	// ResolveTransitionID is the Jira workflow transition ID applied to
+	// an open issue when its alert returns to OK.
+	ResolveTransitionID string `toml:"resolve-transition-id" override:"resolve-transition-id"`
+}
+
+func NewConfig() Config {
+	return Config{
+		AuthType:  AuthTypeBasic,
+		IssueType: "Bug",
+	}
+}
+
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.URL == "" {
+		return errors.New("must specify url")
+	}
+	if c.Project == "" {
+		return errors.New("must specify project")
+	}
+	switch c.AuthType {
+	case AuthTypeBasic:
+		if c.Username == "" || c.Password == "" {
+			return errors.New("must specify username and password in basic auth mode")
+		}
+	case AuthTypePAT:
+		if c.Token == "" {
+			return errors.New("must specify token in pat auth mode")
+		}
+	default:
+		return errors.Errorf("unknown auth-type %q, must be %q or %q", c.AuthType, AuthTypeBasic, AuthTypePAT)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Package jiratest provides an in-process fake Jira REST API for testing
+// the jira alert handler without a live Jira instance, mirroring how
+// kafkatest fakes a kafka producer.
+package jiratest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// Comment records one comment posted to an issue.
+type Comment struct {
+	IssueKey string
+	Body     string
+}
+
+// Transition records one workflow transition applied to an issue.
+type Transition struct {
+	IssueKey     string
+	TransitionID string
+}
+
+// Server is a fake Jira REST API. It records every issue created,
+// comment posted, and transition applied, and optionally fails every
+// request with Err, for exercising a handler's failure path.
+type Server struct {
+	URL string
+
+	mu          sync.Mutex
+	ts          *httptest.Server
+	nextID      int
+	issues      []json.RawMessage
+	comments    []Comment
+	transitions []Transition
+
+	// Err, if non-nil, is returned as a 500 response to every request.
+	Err error
+}
+
+// NewServer starts a Server. Callers must Close it when done.
+func NewServer() *Server {
+	s := &Server{}
+	s.ts = httptest.NewServer(http.HandlerFunc(s.handle))
+	s.URL = s.ts.URL
+	return s
+}
+
+func (s *Server) Close() {
+	s.ts.Close()
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.Err != nil {
+		http.Error(w, s.Err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/rest/api/2/issue":
+		body, _ := jsonBody(r)
+		s.nextID++
+		key := fmt.Sprintf("TICK-%d", s.nextID)
+		s.issues = append(s.issues, body)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"key": key})
+
+	case r.Method == http.MethodPost && isCommentPath(r.URL.Path):
+		key := issueKeyFromPath(r.URL.Path, "/comment")
+		var c struct {
+			Body string `json:"body"`
+		}
+		json.NewDecoder(r.Body).Decode(&c)
+		s.comments = append(s.comments, Comment{IssueKey: key, Body: c.Body})
+		w.WriteHeader(http.StatusCreated)
+
+	case r.Method == http.MethodPost && isTransitionsPath(r.URL.Path):
+		key := issueKeyFromPath(r.URL.Path, "/transitions")
+		var t struct {
+			Transition struct {
+				ID string `json:"id"`
+			} `json:"transition"`
+		}
+		json.NewDecoder(r.Body).Decode(&t)
+		s.transitions = append(s.transitions, Transition{IssueKey: key, TransitionID: t.Transition.ID})
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func jsonBody(r *http.Request) (json.RawMessage, error) {
+	var raw json.RawMessage
+	err := json.NewDecoder(r.Body).Decode(&raw)
+	return raw, err
+}
+
+func isCommentPath(path string) bool {
+	return len(path) > len("/comment") && path[len(path)-len("/comment"):] == "/comment"
+}
+
+func isTransitionsPath(path string) bool {
+	return len(path) > len("/transitions") && path[len(path)-len("/transitions"):] == "/transitions"
+}
+
+func issueKeyFromPath(path, suffix string) string {
+	trimmed := path[len("/rest/api/2/issue/") : len(path)-len(suffix)]
+	return trimmed
+}
+
+// IssueCount returns the number of issues created so far.
+func (s *Server) IssueCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.issues)
+}
+
+// Comments returns every comment posted so far.
+func (s *Server) Comments() []Comment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Comment, len(s.comments))
+	copy(out, s.comments)
+	return out
+}
+
+// Transitions returns every transition applied so far.
+func (s *Server) Transitions() []Transition {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Transition, len(s.transitions))
+	copy(out, s.transitions)
+	return out
+}
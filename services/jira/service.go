@@ -0,0 +1,345 @@
+package jira
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"text/template"
+
+	"github.com/boltdb/bolt"
+	"github.com/influxdata/kapacitor/alert"
+)
+
+type Service struct {
+	configValue atomic.Value
+	store       *store
+	logger      *log.Logger
+}
+
+func NewService(c Config, db *bolt.DB, l *log.Logger) (*Service, error) {
+	st, err := newStore(db)
+	if err != nil {
+		return nil, err
+	}
+	s := &Service{store: st, logger: l}
+	s.configValue.Store(c)
+	return s, nil
+}
+
+func (s *Service) Open() error  { return nil }
+func (s *Service) Close() error { return nil }
+
+func (s *Service) Update(newConfig []interface{}) error {
+	if l := len(newConfig); l != 1 {
+		return fmt.Errorf("expected only one new config object, got %d", l)
+	}
+	c, ok := newConfig[0].(Config)
+	if !ok {
+		return fmt.Errorf("expected config object to be of type %T, got %T", c, newConfig[0])
+	}
+	s.configValue.Store(c)
+	return nil
+}
+
+func (s *Service) config() Config {
+	return s.configValue.Load().(Config)
+}
+
+func (s *Service) authorize(c Config, req *http.Request) {
+	switch c.AuthType {
+	case AuthTypeBasic:
+		req.SetBasicAuth(c.Username, c.Password)
+	case AuthTypePAT:
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+}
+
+func (s *Service) do(c Config, req *http.Request) (*http.Response, error) {
+	req.Header.Set("Content-Type", "application/json")
+	s.authorize(c, req)
+	return http.DefaultClient.Do(req)
+}
+
+type issueTypeRef struct {
+	Name string `json:"name"`
+}
+
+type projectRef struct {
+	Key string `json:"key"`
+}
+
+type priorityRef struct {
+	Name string `json:"name"`
+}
+
+type issueFields struct {
+	Project     projectRef   `json:"project"`
+	Summary     string       `json:"summary"`
+	Description string       `json:"description,omitempty"`
+	IssueType   issueTypeRef `json:"issuetype"`
+	Priority    *priorityRef `json:"priority,omitempty"`
+	Labels      []string     `json:"labels,omitempty"`
+}
+
+type createIssueRequest struct {
+	Fields issueFields `json:"fields"`
+}
+
+type createIssueResponse struct {
+	Key string `json:"key"`
+}
+
+type commentRequest struct {
+	Body string `json:"body"`
+}
+
+type transitionTo struct {
+	ID string `json:"id"`
+}
+
+type transitionRequest struct {
+	Transition transitionTo `json:"transition"`
+}
+
+// CreateIssue opens a new Jira issue with fields and returns its key.
+func (s *Service) CreateIssue(fields issueFields) (string, error) {
+	c := s.config()
+	if !c.Enabled {
+		return "", fmt.Errorf("service is not enabled")
+	}
+
+	body, err := json.Marshal(createIssueRequest{Fields: fields})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.URL+"/rest/api/2/issue", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	resp, err := s.do(c, req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return "", fmt.Errorf("failed to create jira issue: %d %s", resp.StatusCode, string(respBody))
+	}
+
+	var created createIssueResponse
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", err
+	}
+	return created.Key, nil
+}
+
+// CommentIssue adds a comment to the issue identified by key.
+func (s *Service) CommentIssue(key, comment string) error {
+	c := s.config()
+	body, err := json.Marshal(commentRequest{Body: comment})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.URL+"/rest/api/2/issue/"+key+"/comment", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(c, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("failed to comment on jira issue %s: %d %s", key, resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// TransitionIssue applies transitionID to the issue identified by key.
+func (s *Service) TransitionIssue(key, transitionID string) error {
+	c := s.config()
+	body, err := json.Marshal(transitionRequest{Transition: transitionTo{ID: transitionID}})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.URL+"/rest/api/2/issue/"+key+"/transitions", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(c, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("failed to transition jira issue %s: %d %s", key, resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// HandlerConfig is the per-alert-handler configuration for the jira
+// node in a TICKscript.
+type HandlerConfig struct {
+	// Project overrides the service's default project key.
+	Project string `mapstructure:"project"`
+	// IssueType overrides the service's default issue type.
+	IssueType string `mapstructure:"issue-type"`
+	// Priority, if set, is applied to created issues.
+	Priority string `mapstructure:"priority"`
+	// SummaryTemplate and DescriptionTemplate are text/template strings
+	// rendered against the triggering event to build the issue's
+	// summary and description.
+	SummaryTemplate     string `mapstructure:"summary-template"`
+	DescriptionTemplate string `mapstructure:"description-template"`
+	// Labels are applied to every issue this handler creates.
+	Labels []string `mapstructure:"labels"`
+}
+
+type templateView struct {
+	Level   alert.Level
+	Message string
+	Tags    map[string]string
+}
+
+func render(tmplText, fallback string, v templateView) (string, error) {
+	if tmplText == "" {
+		return fallback, nil
+	}
+	tmpl, err := template.New("jira").Parse(tmplText)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, v); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// groupKey identifies the issue-tracking group an event belongs to: its
+// project plus the sorted values of its tags, so the same alert series
+// maps back to the same Jira issue across re-triggers.
+func groupKey(project string, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys)+1)
+	parts = append(parts, project)
+	for _, k := range keys {
+		parts = append(parts, k+"="+tags[k])
+	}
+	return strings.Join(parts, "\x1f")
+}
+
+type handler struct {
+	s      *Service
+	c      HandlerConfig
+	logger *log.Logger
+}
+
+func (s *Service) DefaultHandlerConfig() HandlerConfig {
+	return HandlerConfig{}
+}
+
+func (s *Service) Handler(c HandlerConfig, l *log.Logger) alert.Handler {
+	return &handler{s: s, c: c, logger: l}
+}
+
+func (h *handler) Handle(event alert.Event) {
+	c := h.s.config()
+
+	project := h.c.Project
+	if project == "" {
+		project = c.Project
+	}
+
+	var tags map[string]string
+	if event.Data.Result.Series != nil && len(event.Data.Result.Series) > 0 {
+		tags = event.Data.Result.Series[0].Tags
+	}
+
+	key := groupKey(project, tags)
+
+	switch event.State.Level {
+	case alert.OK:
+		if err := h.resolve(c, key); err != nil {
+			h.logger.Println("E! failed to resolve jira issue", err)
+		}
+	case alert.Critical:
+		view := templateView{Level: event.State.Level, Message: event.State.Message, Tags: tags}
+		if err := h.createOrComment(c, project, key, view); err != nil {
+			h.logger.Println("E! failed to create/update jira issue", err)
+		}
+	}
+}
+
+func (h *handler) createOrComment(c Config, project, key string, v templateView) error {
+	summary, err := render(h.c.SummaryTemplate, v.Message, v)
+	if err != nil {
+		return err
+	}
+
+	if issueKey, found, err := h.s.store.get(key); err != nil {
+		return err
+	} else if found {
+		return h.s.CommentIssue(issueKey, summary)
+	}
+
+	description, err := render(h.c.DescriptionTemplate, v.Message, v)
+	if err != nil {
+		return err
+	}
+
+	issueType := h.c.IssueType
+	if issueType == "" {
+		issueType = c.IssueType
+	}
+
+	fields := issueFields{
+		Project:     projectRef{Key: project},
+		Summary:     summary,
+		Description: description,
+		IssueType:   issueTypeRef{Name: issueType},
+		Labels:      h.c.Labels,
+	}
+	if h.c.Priority != "" {
+		fields.Priority = &priorityRef{Name: h.c.Priority}
+	}
+
+	issueKey, err := h.s.CreateIssue(fields)
+	if err != nil {
+		return err
+	}
+	return h.s.store.put(key, issueKey)
+}
+
+func (h *handler) resolve(c Config, key string) error {
+	issueKey, found, err := h.s.store.get(key)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return nil
+	}
+	if err := h.s.TransitionIssue(issueKey, c.ResolveTransitionID); err != nil {
+		return err
+	}
+	return h.s.store.delete(key)
+}
@@ -0,0 +1,142 @@
+package jira
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/kapacitor/services/jira/jiratest"
+)
+
+func openTestDB(t *testing.T) (*bolt.DB, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "jira-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := bolt.Open(filepath.Join(dir, "jira.db"), 0600, nil)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func testEvent(level alert.Level, message string) alert.Event {
+	var event alert.Event
+	event.State.Level = level
+	event.State.Message = message
+	return event
+}
+
+func newTestService(t *testing.T, url string) (*Service, func()) {
+	t.Helper()
+	db, closeDB := openTestDB(t)
+	c := NewConfig()
+	c.Enabled = true
+	c.URL = url
+	c.AuthType = AuthTypeBasic
+	c.Username = "kapacitor"
+	c.Password = "s3cr3t"
+	c.Project = "TICK"
+	c.ResolveTransitionID = "5"
+
+	s, err := NewService(c, db, log.New(ioutil.Discard, "", 0))
+	if err != nil {
+		closeDB()
+		t.Fatal(err)
+	}
+	return s, closeDB
+}
+
+func TestHandler_Handle_CreatesIssueOnCritical(t *testing.T) {
+	fake := jiratest.NewServer()
+	defer fake.Close()
+
+	s, closeDB := newTestService(t, fake.URL)
+	defer closeDB()
+
+	h := s.Handler(HandlerConfig{}, log.New(ioutil.Discard, "", 0))
+	h.Handle(testEvent(alert.Critical, "cpu usage above threshold"))
+
+	if got, exp := fake.IssueCount(), 1; got != exp {
+		t.Fatalf("expected %d issue created, got %d", exp, got)
+	}
+}
+
+func TestHandler_Handle_RetriggerCommentsInsteadOfDuplicating(t *testing.T) {
+	fake := jiratest.NewServer()
+	defer fake.Close()
+
+	s, closeDB := newTestService(t, fake.URL)
+	defer closeDB()
+
+	h := s.Handler(HandlerConfig{}, log.New(ioutil.Discard, "", 0))
+	event := testEvent(alert.Critical, "cpu usage above threshold")
+	h.Handle(event)
+	h.Handle(event)
+	h.Handle(event)
+
+	if got, exp := fake.IssueCount(), 1; got != exp {
+		t.Fatalf("expected a single issue across re-triggers, got %d", got)
+	}
+	if got, exp := len(fake.Comments()), 2; got != exp {
+		t.Fatalf("expected %d comments for the re-triggers, got %d", exp, got)
+	}
+}
+
+func TestHandler_Handle_ResolvesOnOK(t *testing.T) {
+	fake := jiratest.NewServer()
+	defer fake.Close()
+
+	s, closeDB := newTestService(t, fake.URL)
+	defer closeDB()
+
+	h := s.Handler(HandlerConfig{}, log.New(ioutil.Discard, "", 0))
+	h.Handle(testEvent(alert.Critical, "cpu usage above threshold"))
+	h.Handle(testEvent(alert.OK, "cpu usage back to normal"))
+
+	transitions := fake.Transitions()
+	if len(transitions) != 1 {
+		t.Fatalf("expected a single transition, got %+v", transitions)
+	}
+	if transitions[0].TransitionID != "5" {
+		t.Errorf("unexpected transition id got %s exp %s", transitions[0].TransitionID, "5")
+	}
+
+	// A subsequent OK with no tracked issue is a no-op, not an error.
+	h.Handle(testEvent(alert.OK, "cpu usage back to normal"))
+	if got, exp := len(fake.Transitions()), 1; got != exp {
+		t.Fatalf("expected no additional transition, got %d", got)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	c := Config{Enabled: true}
+	if err := c.Validate(); err == nil {
+		t.Error("expected error when no url or project are configured")
+	}
+
+	c = NewConfig()
+	c.Enabled = true
+	c.URL = "http://jira.example.com"
+	c.Project = "TICK"
+	c.Username = "kapacitor"
+	c.Password = "s3cr3t"
+	if err := c.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	c.AuthType = AuthTypePAT
+	c.Token = ""
+	if err := c.Validate(); err == nil {
+		t.Error("expected error when pat auth-type has no token")
+	}
+}
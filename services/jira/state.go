@@ -0,0 +1,51 @@
+package jira
+
+import (
+	"github.com/boltdb/bolt"
+)
+
+// issueBucket maps a group key (see groupKey) to the key of the Jira
+// issue currently open for it, so a re-trigger comments on the existing
+// issue instead of opening a duplicate.
+var issueBucket = []byte("jira_issues")
+
+type store struct {
+	db *bolt.DB
+}
+
+func newStore(db *bolt.DB) (*store, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(issueBucket)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &store{db: db}, nil
+}
+
+func (s *store) get(key string) (string, bool, error) {
+	var issueKey string
+	var found bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(issueBucket).Get([]byte(key))
+		if v != nil {
+			issueKey = string(v)
+			found = true
+		}
+		return nil
+	})
+	return issueKey, found, err
+}
+
+func (s *store) put(key, issueKey string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(issueBucket).Put([]byte(key), []byte(issueKey))
+	})
+}
+
+func (s *store) delete(key string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(issueBucket).Delete([]byte(key))
+	})
+}
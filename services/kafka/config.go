@@ -0,0 +1,124 @@
+// Package kafka implements the "kafka" alert handler kind: it produces a
+// JSON-encoded AlertData message per event onto a configured topic of a
+// named Kafka cluster.
+package kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+
+	"github.com/influxdata/toml"
+)
+
+// TLSConfig configures a TLS connection to a cluster's brokers. It's the
+// same shape as grpcalert.TLSConfig; each service that needs one defines
+// its own to keep the two packages independent.
+type TLSConfig struct {
+	CertFile           string `toml:"cert" override:"cert"`
+	KeyFile            string `toml:"key" override:"key"`
+	CAFile             string `toml:"ca" override:"ca"`
+	ServerName         string `toml:"server-name" override:"server-name"`
+	InsecureSkipVerify bool   `toml:"insecure-skip-verify" override:"insecure-skip-verify"`
+}
+
+func (c TLSConfig) isZero() bool {
+	return c == TLSConfig{}
+}
+
+func (c TLSConfig) tlsConfig() (*tls.Config, error) {
+	if c.isZero() {
+		return nil, nil
+	}
+	tc := &tls.Config{
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+	if c.CAFile != "" {
+		pem, err := ioutil.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("kafka: failed to parse ca file")
+		}
+		tc.RootCAs = pool
+	}
+	return tc, nil
+}
+
+// SASLConfig configures SASL authentication to a cluster's brokers.
+// Mechanism is empty (disabled), "PLAIN", or "SCRAM-SHA-256"/"SCRAM-SHA-512".
+type SASLConfig struct {
+	Mechanism string `toml:"mechanism" override:"mechanism"`
+	Username  string `toml:"username" override:"username"`
+	Password  string `toml:"password" override:"password,redact"`
+}
+
+func (c SASLConfig) enabled() bool {
+	return c.Mechanism != ""
+}
+
+// ClusterConfig is one named Kafka cluster's connection settings.
+type ClusterConfig struct {
+	Brokers []string   `toml:"brokers" override:"brokers"`
+	TLS     TLSConfig  `toml:"tls" override:"tls"`
+	SASL    SASLConfig `toml:"sasl" override:"sasl"`
+	// Partitioner selects how a message without an explicit partition is
+	// assigned one: "round-robin" (default), "hash", or "manual".
+	Partitioner string `toml:"partitioner" override:"partitioner"`
+}
+
+func (c ClusterConfig) validate(name string) error {
+	if len(c.Brokers) == 0 {
+		return errors.New("kafka: cluster " + name + " must specify at least one broker")
+	}
+	switch c.Partitioner {
+	case "", "round-robin", "hash", "manual":
+	default:
+		return errors.New("kafka: cluster " + name + " has unknown partitioner " + c.Partitioner)
+	}
+	return nil
+}
+
+// Config is the kafka service section: a set of named clusters handler
+// options reference by name (e.g. Options{"cluster":"prod"}).
+type Config struct {
+	Enabled bool `toml:"enabled" override:"enabled"`
+
+	// Timeout bounds how long producing a single message may take.
+	Timeout toml.Duration `toml:"timeout" override:"timeout"`
+
+	Clusters map[string]ClusterConfig `toml:"clusters" override:"clusters"`
+}
+
+func NewConfig() Config {
+	return Config{
+		Timeout:  toml.Duration(defaultTimeout),
+		Clusters: make(map[string]ClusterConfig),
+	}
+}
+
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if len(c.Clusters) == 0 {
+		return errors.New("kafka: must configure at least one cluster")
+	}
+	for name, cc := range c.Clusters {
+		if err := cc.validate(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
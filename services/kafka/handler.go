@@ -0,0 +1,64 @@
+package kafka
+
+import (
+	"bytes"
+	"log"
+	"text/template"
+
+	"github.com/influxdata/kapacitor/alert"
+)
+
+// HandlerConfig is the per-handler configuration for a "kafka" handler
+// action, i.e. HandlerAction.Options.
+type HandlerConfig struct {
+	// Cluster names the kafka service Config.Clusters entry to produce
+	// onto.
+	Cluster string `mapstructure:"cluster"`
+	Topic   string `mapstructure:"topic"`
+	// KeyTemplate is executed against the event's AlertData to produce
+	// the Kafka message key, e.g. "{{.ID}}". Empty means no key, letting
+	// the cluster's partitioner choose.
+	KeyTemplate string `mapstructure:"key-template"`
+}
+
+func (s *Service) DefaultHandlerConfig() HandlerConfig {
+	return HandlerConfig{}
+}
+
+func (s *Service) Handler(c HandlerConfig, l *log.Logger) alert.Handler {
+	var tmpl *template.Template
+	if c.KeyTemplate != "" {
+		t, err := template.New("key").Parse(c.KeyTemplate)
+		if err != nil {
+			l.Println("E! invalid kafka key-template, messages will be produced without a key", err)
+		} else {
+			tmpl = t
+		}
+	}
+	return &handler{s: s, c: c, keyTemplate: tmpl, logger: l}
+}
+
+type handler struct {
+	s           *Service
+	c           HandlerConfig
+	keyTemplate *template.Template
+	logger      *log.Logger
+}
+
+func (h *handler) Handle(event alert.Event) {
+	data := newAlertData(event)
+
+	key := ""
+	if h.keyTemplate != nil {
+		var buf bytes.Buffer
+		if err := h.keyTemplate.Execute(&buf, data); err != nil {
+			h.logger.Println("E! failed to render kafka key template", err)
+		} else {
+			key = buf.String()
+		}
+	}
+
+	if err := h.s.Produce(h.c.Cluster, h.c.Topic, key, data); err != nil {
+		h.logger.Println("E! failed to produce alert to kafka", err)
+	}
+}
@@ -0,0 +1,33 @@
+//go:build integration
+// +build integration
+
+// This file exercises the kafka service against a real broker. It's
+// gated behind the "integration" build tag (run with
+// `go test -tags integration ./services/kafka/...`) so `go test ./...`
+// doesn't require a running Kafka cluster.
+package kafka
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"testing"
+)
+
+func TestService_Produce_AgainstRealBroker(t *testing.T) {
+	brokers := os.Getenv("KAFKA_BROKERS")
+	if brokers == "" {
+		t.Skip("set KAFKA_BROKERS to run this test against a live cluster")
+	}
+
+	c := NewConfig()
+	c.Enabled = true
+	c.Clusters["prod"] = ClusterConfig{Brokers: []string{brokers}}
+
+	s := NewService(c, log.New(ioutil.Discard, "", 0))
+	defer s.Close()
+
+	if err := s.Produce("prod", "kapacitor-integration-test", "", AlertData{Message: "integration test"}); err != nil {
+		t.Fatal(err)
+	}
+}
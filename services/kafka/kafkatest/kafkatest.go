@@ -0,0 +1,60 @@
+// Package kafkatest provides an in-process fake Producer for testing the
+// kafka alert handler without a live broker, mirroring how
+// grpcalerttest fakes a gRPC server.
+package kafkatest
+
+import (
+	"sync"
+
+	"github.com/Shopify/sarama"
+)
+
+// Message records one message sent to Server.
+type Message struct {
+	Topic string
+	Key   string
+	Value []byte
+}
+
+// Server is a fake kafka.Producer that records every message sent to it
+// instead of producing onto a real cluster.
+type Server struct {
+	mu       sync.Mutex
+	messages []Message
+	err      error
+}
+
+// NewServer builds a Server. If err is non-nil, SendMessage always
+// returns it, for exercising a handler's failure path.
+func NewServer(err error) *Server {
+	return &Server{err: err}
+}
+
+func (s *Server) SendMessage(msg *sarama.ProducerMessage) (partition int32, offset int64, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return 0, 0, s.err
+	}
+
+	var key string
+	if msg.Key != nil {
+		b, _ := msg.Key.Encode()
+		key = string(b)
+	}
+	value, _ := msg.Value.Encode()
+
+	s.messages = append(s.messages, Message{Topic: msg.Topic, Key: key, Value: value})
+	return 0, int64(len(s.messages) - 1), nil
+}
+
+func (s *Server) Close() error { return nil }
+
+// Messages returns every message sent to the server so far.
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Message, len(s.messages))
+	copy(out, s.messages)
+	return out
+}
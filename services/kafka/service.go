@@ -0,0 +1,198 @@
+package kafka
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/influxdata/kapacitor/alert"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// AlertData is the message body produced onto Kafka for each alert
+// event. ID is a stable digest of the event's message and tags (this
+// repo has no externally-defined alert ID to reuse), so a key-template
+// like "{{.ID}}" dedupes/partitions consistently for repeats of the same
+// alert.
+type AlertData struct {
+	ID      string            `json:"id"`
+	Message string            `json:"message"`
+	Level   string            `json:"level"`
+	Time    time.Time         `json:"time"`
+	Tags    map[string]string `json:"tags,omitempty"`
+}
+
+func newAlertData(event alert.Event) AlertData {
+	var tags map[string]string
+	if event.Data.Result.Series != nil && len(event.Data.Result.Series) > 0 {
+		tags = event.Data.Result.Series[0].Tags
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%v", event.State.Message, tags)))
+	return AlertData{
+		ID:      hex.EncodeToString(sum[:8]),
+		Message: event.State.Message,
+		Level:   string(event.State.Level),
+		Time:    time.Now(),
+		Tags:    tags,
+	}
+}
+
+// Producer is the subset of sarama.SyncProducer the service depends on,
+// so tests can substitute a fake without a live broker.
+type Producer interface {
+	SendMessage(msg *sarama.ProducerMessage) (partition int32, offset int64, err error)
+	Close() error
+}
+
+// NewProducer builds the default Producer for cc: a sarama
+// SyncProducer connected to cc.Brokers. Tests override Service.NewProducer
+// to avoid dialing a real cluster.
+func NewProducer(cc ClusterConfig, timeout time.Duration) (Producer, error) {
+	conf := sarama.NewConfig()
+	conf.Producer.Return.Successes = true
+	conf.Producer.Timeout = timeout
+	conf.Net.DialTimeout = timeout
+
+	switch cc.Partitioner {
+	case "hash":
+		conf.Producer.Partitioner = sarama.NewHashPartitioner
+	case "manual":
+		conf.Producer.Partitioner = sarama.NewManualPartitioner
+	default:
+		conf.Producer.Partitioner = sarama.NewRoundRobinPartitioner
+	}
+
+	if !cc.TLS.isZero() {
+		tc, err := cc.TLS.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		conf.Net.TLS.Enable = true
+		conf.Net.TLS.Config = tc
+	}
+	if cc.SASL.enabled() {
+		conf.Net.SASL.Enable = true
+		conf.Net.SASL.Mechanism = sarama.SASLMechanism(cc.SASL.Mechanism)
+		conf.Net.SASL.User = cc.SASL.Username
+		conf.Net.SASL.Password = cc.SASL.Password
+	}
+
+	return sarama.NewSyncProducer(cc.Brokers, conf)
+}
+
+// Service produces alert events onto Kafka topics across the configured
+// named clusters.
+type Service struct {
+	configValue atomic.Value // Config
+
+	// NewProducer builds the Producer for a cluster; overridden in tests.
+	NewProducer func(cc ClusterConfig, timeout time.Duration) (Producer, error)
+
+	mu        sync.Mutex
+	producers map[string]Producer
+
+	logger *log.Logger
+}
+
+func NewService(c Config, l *log.Logger) *Service {
+	s := &Service{
+		NewProducer: NewProducer,
+		producers:   make(map[string]Producer),
+		logger:      l,
+	}
+	s.configValue.Store(c)
+	return s
+}
+
+func (s *Service) config() Config {
+	return s.configValue.Load().(Config)
+}
+
+func (s *Service) Open() error {
+	return nil
+}
+
+func (s *Service) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, p := range s.producers {
+		if err := p.Close(); err != nil {
+			s.logger.Println("E! error closing kafka producer for cluster", name, err)
+		}
+	}
+	s.producers = make(map[string]Producer)
+	return nil
+}
+
+func (s *Service) Update(newConfig []interface{}) error {
+	if len(newConfig) != 1 {
+		return fmt.Errorf("kafka: expected 1 config object, got %d", len(newConfig))
+	}
+	c, ok := newConfig[0].(Config)
+	if !ok {
+		return fmt.Errorf("kafka: unexpected config object type %T", newConfig[0])
+	}
+	s.configValue.Store(c)
+	s.Close()
+	return nil
+}
+
+func (s *Service) producer(cluster string) (Producer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p, ok := s.producers[cluster]; ok {
+		return p, nil
+	}
+
+	c := s.config()
+	cc, ok := c.Clusters[cluster]
+	if !ok {
+		return nil, fmt.Errorf("kafka: unknown cluster %q", cluster)
+	}
+	p, err := s.NewProducer(cc, time.Duration(c.Timeout))
+	if err != nil {
+		return nil, err
+	}
+	s.producers[cluster] = p
+	return p, nil
+}
+
+// Produce JSON-encodes data and sends it to topic on cluster, with key as
+// the message key (the handler renders it from HandlerConfig.KeyTemplate);
+// an empty key sends the message without one.
+func (s *Service) Produce(cluster, topic, key string, data AlertData) error {
+	c := s.config()
+	if !c.Enabled {
+		return errors.New("kafka: service is not enabled")
+	}
+
+	p, err := s.producer(cluster)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic: topic,
+		Value: sarama.ByteEncoder(body),
+	}
+	if key != "" {
+		msg.Key = sarama.StringEncoder(key)
+	}
+
+	_, _, err = p.SendMessage(msg)
+	return err
+}
@@ -0,0 +1,97 @@
+package kafka
+
+import (
+	"io/ioutil"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/kapacitor/services/kafka/kafkatest"
+)
+
+func newTestService(t *testing.T, fake *kafkatest.Server) *Service {
+	t.Helper()
+	c := NewConfig()
+	c.Enabled = true
+	c.Clusters["prod"] = ClusterConfig{Brokers: []string{"kafka-1:9092"}}
+
+	s := NewService(c, log.New(ioutil.Discard, "", 0))
+	s.NewProducer = func(cc ClusterConfig, timeout time.Duration) (Producer, error) {
+		return fake, nil
+	}
+	return s
+}
+
+func testEvent(message string, level alert.Level) alert.Event {
+	var event alert.Event
+	event.State.Message = message
+	event.State.Level = level
+	return event
+}
+
+func TestService_Produce_SendsJSONEncodedAlertData(t *testing.T) {
+	fake := kafkatest.NewServer(nil)
+	s := newTestService(t, fake)
+
+	h := s.Handler(HandlerConfig{Cluster: "prod", Topic: "alerts"}, log.New(ioutil.Discard, "", 0))
+	h.Handle(testEvent("cpu high", alert.Critical))
+
+	msgs := fake.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+	if msgs[0].Topic != "alerts" {
+		t.Fatalf("unexpected topic %q", msgs[0].Topic)
+	}
+	if msgs[0].Key != "" {
+		t.Fatalf("expected no key without a key-template, got %q", msgs[0].Key)
+	}
+}
+
+func TestService_Produce_RendersKeyTemplate(t *testing.T) {
+	fake := kafkatest.NewServer(nil)
+	s := newTestService(t, fake)
+
+	h := s.Handler(HandlerConfig{Cluster: "prod", Topic: "alerts", KeyTemplate: "{{.ID}}"}, log.New(ioutil.Discard, "", 0))
+	h.Handle(testEvent("cpu high", alert.Critical))
+	h.Handle(testEvent("cpu high", alert.Critical))
+
+	msgs := fake.Messages()
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(msgs))
+	}
+	if msgs[0].Key == "" {
+		t.Fatal("expected a non-empty key rendered from the template")
+	}
+	if msgs[0].Key != msgs[1].Key {
+		t.Fatalf("expected the same alert to derive the same key, got %q and %q", msgs[0].Key, msgs[1].Key)
+	}
+}
+
+func TestService_Produce_UnknownClusterErrors(t *testing.T) {
+	fake := kafkatest.NewServer(nil)
+	s := newTestService(t, fake)
+
+	if err := s.Produce("staging", "alerts", "", AlertData{}); err == nil {
+		t.Fatal("expected an error for an unconfigured cluster")
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	c := NewConfig()
+	c.Enabled = true
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected an error when no clusters are configured with brokers")
+	}
+
+	c.Clusters["prod"] = ClusterConfig{Brokers: []string{"kafka-1:9092"}}
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Clusters["prod"] = ClusterConfig{Brokers: []string{"kafka-1:9092"}, Partitioner: "bogus"}
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected an error for an unknown partitioner")
+	}
+}
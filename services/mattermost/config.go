@@ -0,0 +1,85 @@
+package mattermost
+
+import (
+	"github.com/influxdata/kapacitor/services/httpretry"
+	"github.com/pkg/errors"
+)
+
+// Mode selects how the service authenticates against Mattermost.
+type Mode string
+
+const (
+	// ModeWebhook posts to an incoming webhook URL, same as Slack.
+	ModeWebhook Mode = "webhook"
+	// ModeAPI posts through Mattermost's native API using a login
+	// (username/password) or a personal access token.
+	ModeAPI Mode = "api"
+)
+
+// Config is the mattermost service section.
+type Config struct {
+	// Whether Mattermost integration is enabled.
+	Enabled bool `toml:"enabled" override:"enabled"`
+	// Whether this section is used as the default handler for all alerts.
+	Global bool `toml:"global" override:"global"`
+	// Only post a message when the alert state changes.
+	StateChangesOnly bool `toml:"state-changes-only" override:"state-changes-only"`
+
+	// Mode is either "webhook" or "api".
+	Mode Mode `toml:"mode" override:"mode"`
+
+	// Webhook mode.
+	URL string `toml:"url" override:"url,redact"`
+
+	// API mode: ServerURL plus either a login/password pair or a
+	// personal access token.
+	ServerURL   string `toml:"server-url" override:"server-url"`
+	LoginID     string `toml:"login-id" override:"login-id,redact"`
+	Password    string `toml:"password" override:"password,redact"`
+	AccessToken string `toml:"access-token" override:"access-token,redact"`
+
+	// Default channel/team, overridable per handler.
+	Channel   string `toml:"channel" override:"channel"`
+	Team      string `toml:"team" override:"team"`
+	Username  string `toml:"username" override:"username"`
+	IconURL   string `toml:"icon-url" override:"icon-url"`
+	IconEmoji string `toml:"icon-emoji" override:"icon-emoji"`
+
+	// Retry configures backoff and dead-letter forwarding for transient
+	// delivery failures. The zero value disables retries.
+	Retry httpretry.Config `toml:"retry" override:"retry"`
+}
+
+func NewConfig() Config {
+	return Config{
+		Mode:     ModeWebhook,
+		Username: "kapacitor",
+	}
+}
+
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	switch c.Mode {
+	case ModeWebhook:
+		if c.URL == "" {
+			return errors.New("must specify url in webhook mode")
+		}
+	case ModeAPI:
+		if c.ServerURL == "" {
+			return errors.New("must specify server-url in api mode")
+		}
+		hasLogin := c.LoginID != "" && c.Password != ""
+		hasToken := c.AccessToken != ""
+		if !hasLogin && !hasToken {
+			return errors.New("must specify either login-id/password or access-token in api mode")
+		}
+		if hasLogin && hasToken {
+			return errors.New("login-id/password and access-token are mutually exclusive in api mode")
+		}
+	default:
+		return errors.Errorf("unknown mode %q, must be %q or %q", c.Mode, ModeWebhook, ModeAPI)
+	}
+	return c.Retry.Validate()
+}
@@ -0,0 +1,402 @@
+package mattermost
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"text/template"
+
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/kapacitor/services/alerttemplate"
+	"github.com/influxdata/kapacitor/services/httpretry"
+)
+
+type Service struct {
+	configValue atomic.Value
+	logger      *log.Logger
+
+	// DeadLetter, if set, is called with an alert's outbound payload once
+	// every retry attempt for it has failed.
+	DeadLetter func(body []byte, lastErr error) error
+
+	mu         sync.Mutex
+	apiToken   string
+	channelIDs map[string]string // "team/channel" -> channel id, api mode only
+}
+
+func NewService(c Config, l *log.Logger) *Service {
+	s := &Service{
+		logger:     l,
+		channelIDs: make(map[string]string),
+	}
+	s.configValue.Store(c)
+	return s
+}
+
+// httpClient builds an *http.Client applying the configured Retry
+// policy; with retries not configured it makes a single attempt.
+func (s *Service) httpClient() *http.Client {
+	c := s.config()
+	t := &httpretry.Transport{Config: c.Retry}
+	if s.DeadLetter != nil {
+		t.DeadLetter = func(req *http.Request, body []byte, lastErr error) error {
+			return s.DeadLetter(body, lastErr)
+		}
+	}
+	return &http.Client{Transport: t}
+}
+
+func (s *Service) Open() error  { return nil }
+func (s *Service) Close() error { return nil }
+
+func (s *Service) Update(newConfig []interface{}) error {
+	if l := len(newConfig); l != 1 {
+		return fmt.Errorf("expected only one new config object, got %d", l)
+	}
+	c, ok := newConfig[0].(Config)
+	if !ok {
+		return fmt.Errorf("expected config object to be of type %T, got %T", c, newConfig[0])
+	}
+	s.configValue.Store(c)
+
+	s.mu.Lock()
+	s.apiToken = ""
+	s.channelIDs = make(map[string]string)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Service) config() Config {
+	return s.configValue.Load().(Config)
+}
+
+type webhookPayload struct {
+	Text      string `json:"text"`
+	Channel   string `json:"channel,omitempty"`
+	Username  string `json:"username,omitempty"`
+	IconURL   string `json:"icon_url,omitempty"`
+	IconEmoji string `json:"icon_emoji,omitempty"`
+}
+
+// AlertData holds the per-message parameters a handler can override.
+type AlertData struct {
+	Channel   string
+	Team      string
+	Message   string
+	Level     alert.Level
+	Username  string
+	IconURL   string
+	IconEmoji string
+}
+
+func (s *Service) Alert(ad AlertData) error {
+	c := s.config()
+	if !c.Enabled {
+		return fmt.Errorf("service is not enabled")
+	}
+
+	switch c.Mode {
+	case ModeAPI:
+		return s.alertAPI(c, ad)
+	default:
+		return s.alertWebhook(c, ad)
+	}
+}
+
+func (s *Service) alertWebhook(c Config, ad AlertData) error {
+	channel := ad.Channel
+	if channel == "" {
+		channel = c.Channel
+	}
+	username := ad.Username
+	if username == "" {
+		username = c.Username
+	}
+	iconURL := ad.IconURL
+	if iconURL == "" {
+		iconURL = c.IconURL
+	}
+	iconEmoji := ad.IconEmoji
+	if iconEmoji == "" {
+		iconEmoji = c.IconEmoji
+	}
+
+	payload := webhookPayload{
+		Text:      ad.Message,
+		Channel:   channel,
+		Username:  username,
+		IconURL:   iconURL,
+		IconEmoji: iconEmoji,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.httpClient().Post(c.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("failed to post mattermost webhook message: %d %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+func (s *Service) alertAPI(c Config, ad AlertData) error {
+	team := ad.Team
+	if team == "" {
+		team = c.Team
+	}
+	channel := ad.Channel
+	if channel == "" {
+		channel = c.Channel
+	}
+
+	channelID, err := s.channelID(c, team, channel)
+	if err != nil {
+		return err
+	}
+
+	post := map[string]string{
+		"channel_id": channelID,
+		"message":    ad.Message,
+	}
+	body, err := json.Marshal(post)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.ServerURL+"/api/v4/posts", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := s.authorize(c, req); err != nil {
+		return err
+	}
+
+	resp, err := s.httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("failed to create mattermost post: %d %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// authorize sets the Authorization header for an API mode request,
+// logging in against LoginID/Password on first use and caching the
+// resulting session token, or using AccessToken directly when set.
+func (s *Service) authorize(c Config, req *http.Request) error {
+	if c.AccessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+		return nil
+	}
+
+	s.mu.Lock()
+	token := s.apiToken
+	s.mu.Unlock()
+	if token == "" {
+		var err error
+		token, err = s.login(c)
+		if err != nil {
+			return err
+		}
+		s.mu.Lock()
+		s.apiToken = token
+		s.mu.Unlock()
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+func (s *Service) login(c Config) (string, error) {
+	creds := map[string]string{
+		"login_id": c.LoginID,
+		"password": c.Password,
+	}
+	body, err := json.Marshal(creds)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.Post(c.ServerURL+"/api/v4/users/login", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return "", fmt.Errorf("failed to log in to mattermost: %d %s", resp.StatusCode, string(respBody))
+	}
+
+	token := resp.Header.Get("Token")
+	if token == "" {
+		return "", fmt.Errorf("mattermost login response did not include a session token")
+	}
+	return token, nil
+}
+
+// channelID resolves team/channel to a channel ID, caching the result
+// for the lifetime of the current API session since channel IDs don't
+// change once created.
+func (s *Service) channelID(c Config, team, channel string) (string, error) {
+	key := team + "/" + channel
+
+	s.mu.Lock()
+	id, ok := s.channelIDs[key]
+	s.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	teamID, err := s.lookup(c, "/api/v4/teams/name/"+team)
+	if err != nil {
+		return "", fmt.Errorf("looking up mattermost team %q: %w", team, err)
+	}
+	channelObj, err := s.lookup(c, "/api/v4/teams/"+teamID+"/channels/name/"+channel)
+	if err != nil {
+		return "", fmt.Errorf("looking up mattermost channel %q in team %q: %w", channel, team, err)
+	}
+
+	s.mu.Lock()
+	s.channelIDs[key] = channelObj
+	s.mu.Unlock()
+	return channelObj, nil
+}
+
+// lookup fetches a Mattermost object by path and returns its "id" field.
+func (s *Service) lookup(c Config, path string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, c.ServerURL+path, nil)
+	if err != nil {
+		return "", err
+	}
+	if err := s.authorize(c, req); err != nil {
+		return "", err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return "", fmt.Errorf("%d %s", resp.StatusCode, string(respBody))
+	}
+
+	var obj struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&obj); err != nil {
+		return "", err
+	}
+	return obj.ID, nil
+}
+
+type testOptions struct {
+	Channel string      `json:"channel"`
+	Team    string      `json:"team"`
+	Message string      `json:"message"`
+	Level   alert.Level `json:"level"`
+}
+
+func (s *Service) TestOptions() interface{} {
+	c := s.config()
+	return &testOptions{
+		Channel: c.Channel,
+		Team:    c.Team,
+		Message: "test mattermost message",
+		Level:   alert.Warning,
+	}
+}
+
+func (s *Service) Test(options interface{}) error {
+	o, ok := options.(*testOptions)
+	if !ok {
+		return fmt.Errorf("unexpected options type %t", options)
+	}
+	return s.Alert(AlertData{
+		Channel: o.Channel,
+		Team:    o.Team,
+		Message: o.Message,
+		Level:   o.Level,
+	})
+}
+
+// HandlerConfig is the per-alert-handler configuration for the
+// mattermost node in a TICKscript.
+type HandlerConfig struct {
+	// Channel overrides the service's default channel. Channel may be a
+	// text/template expression evaluated against the triggering event
+	// (see alerttemplate.Data), e.g. "{{ index .Tags \"env\" }}" to
+	// route by tag.
+	Channel string `mapstructure:"channel"`
+	// Team overrides the service's default team, only meaningful in api
+	// mode.
+	Team string `mapstructure:"team"`
+	// Username overrides the webhook's default bot name, only meaningful
+	// in webhook mode.
+	Username string `mapstructure:"username"`
+	// IconURL overrides the webhook's default avatar, only meaningful in
+	// webhook mode.
+	IconURL string `mapstructure:"icon-url"`
+	// IconEmoji overrides the webhook's default avatar with an emoji,
+	// only meaningful in webhook mode.
+	IconEmoji string `mapstructure:"icon-emoji"`
+}
+
+type handler struct {
+	s               *Service
+	c               HandlerConfig
+	channelTemplate *template.Template
+	logger          *log.Logger
+}
+
+func (s *Service) DefaultHandlerConfig() HandlerConfig {
+	return HandlerConfig{}
+}
+
+func (s *Service) Handler(c HandlerConfig, l *log.Logger) alert.Handler {
+	channelTemplate, err := alerttemplate.Parse("channel", c.Channel)
+	if err != nil {
+		l.Println("E! invalid mattermost channel template", err)
+	}
+	return &handler{s: s, c: c, channelTemplate: channelTemplate, logger: l}
+}
+
+func (h *handler) Handle(event alert.Event) {
+	channel, err := alerttemplate.Render(h.channelTemplate, h.c.Channel, alerttemplate.NewData(event))
+	if err != nil {
+		h.logger.Println("E! failed to render mattermost channel template", err)
+		return
+	}
+
+	ad := AlertData{
+		Channel:   channel,
+		Team:      h.c.Team,
+		Message:   event.State.Message,
+		Level:     event.State.Level,
+		Username:  h.c.Username,
+		IconURL:   h.c.IconURL,
+		IconEmoji: h.c.IconEmoji,
+	}
+	if err := h.s.Alert(ad); err != nil {
+		h.logger.Println("E! failed to send event to Mattermost", err)
+	}
+}
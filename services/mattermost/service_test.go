@@ -0,0 +1,230 @@
+package mattermost
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/kapacitor/services/httpretry/httpretrytest"
+)
+
+func NewTestService(c Config) *Service {
+	return NewService(c, log.New(ioutil.Discard, "", 0))
+}
+
+func TestService_Alert_Webhook(t *testing.T) {
+	var received webhookPayload
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	c := Config{Enabled: true, Mode: ModeWebhook, URL: ts.URL, Username: "kapacitor"}
+	s := NewTestService(c)
+
+	if err := s.Alert(AlertData{Channel: "#ops", Message: "disk full", Level: alert.Critical}); err != nil {
+		t.Fatal(err)
+	}
+	if received.Channel != "#ops" || received.Text != "disk full" || received.Username != "kapacitor" {
+		t.Fatalf("unexpected webhook payload: %+v", received)
+	}
+}
+
+func TestService_Alert_Webhook_PerHandlerUsernameAndIconOverrideServiceDefaults(t *testing.T) {
+	var received webhookPayload
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	c := Config{Enabled: true, Mode: ModeWebhook, URL: ts.URL, Username: "kapacitor", IconEmoji: ":robot:"}
+	s := NewTestService(c)
+
+	ad := AlertData{
+		Channel:   "#ops",
+		Message:   "disk full",
+		Level:     alert.Critical,
+		Username:  "prod-alerts",
+		IconEmoji: ":fire:",
+	}
+	if err := s.Alert(ad); err != nil {
+		t.Fatal(err)
+	}
+	if received.Username != "prod-alerts" || received.IconEmoji != ":fire:" {
+		t.Fatalf("expected per-handler username/icon overrides to win, got %+v", received)
+	}
+}
+
+// newFakeAPI starts a minimal fake of the Mattermost v4 API covering
+// login, team/channel lookup, and posting, enough to exercise the
+// client's api-mode flow end to end.
+func newFakeAPI(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/users/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Token", "session-token")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/api/v4/teams/name/engineering", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"team-1"}`))
+	})
+	mux.HandleFunc("/api/v4/teams/team-1/channels/name/ops", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"channel-1"}`))
+	})
+	mux.HandleFunc("/api/v4/posts", func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "Bearer ") {
+			t.Fatalf("expected a bearer token on the post request, got %q", auth)
+		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["channel_id"] != "channel-1" {
+			t.Fatalf("expected post to resolved channel-1, got %+v", body)
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestService_Alert_APILoginAndPost(t *testing.T) {
+	ts := newFakeAPI(t)
+	defer ts.Close()
+
+	c := Config{
+		Enabled:   true,
+		Mode:      ModeAPI,
+		ServerURL: ts.URL,
+		LoginID:   "bot@example.com",
+		Password:  "secret",
+		Team:      "engineering",
+		Channel:   "ops",
+	}
+	s := NewTestService(c)
+
+	if err := s.Alert(AlertData{Message: "disk full", Level: alert.Critical}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestService_Alert_APIAccessTokenSkipsLogin(t *testing.T) {
+	ts := newFakeAPI(t)
+	defer ts.Close()
+
+	c := Config{
+		Enabled:     true,
+		Mode:        ModeAPI,
+		ServerURL:   ts.URL,
+		AccessToken: "pat-token",
+		Team:        "engineering",
+		Channel:     "ops",
+	}
+	s := NewTestService(c)
+
+	if err := s.Alert(AlertData{Message: "disk full", Level: alert.Warning}); err != nil {
+		t.Fatal(err)
+	}
+	if s.apiToken != "" {
+		t.Fatal("expected no login session when an access token is configured")
+	}
+}
+
+func TestService_Alert_RetriesTransientFailureThenSucceeds(t *testing.T) {
+	ts, calls := httpretrytest.FlakyServer(2, http.StatusServiceUnavailable, http.StatusOK)
+	defer ts.Close()
+
+	c := Config{
+		Enabled: true,
+		Mode:    ModeWebhook,
+		URL:     ts.URL,
+		Retry:   httpretrytest.Config(3),
+	}
+	s := NewTestService(c)
+
+	if err := s.Alert(AlertData{Message: "disk full", Level: alert.Critical}); err != nil {
+		t.Fatal(err)
+	}
+	if *calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", *calls)
+	}
+}
+
+func TestService_Alert_DeadLettersOnFinalFailure(t *testing.T) {
+	ts := httpretrytest.AlwaysFailingServer(http.StatusServiceUnavailable)
+	defer ts.Close()
+
+	c := Config{
+		Enabled: true,
+		Mode:    ModeWebhook,
+		URL:     ts.URL,
+		Retry:   httpretrytest.Config(2),
+	}
+	s := NewTestService(c)
+
+	var deadLettered bool
+	s.DeadLetter = func(body []byte, lastErr error) error {
+		deadLettered = true
+		return nil
+	}
+
+	if err := s.Alert(AlertData{Message: "disk full", Level: alert.Critical}); err == nil {
+		t.Fatal("expected the final failure to be returned as an error")
+	}
+	if !deadLettered {
+		t.Fatal("expected DeadLetter to be called after retries were exhausted")
+	}
+}
+
+func TestHandler_Handle_ChannelTemplateRoutesByLevel(t *testing.T) {
+	var received webhookPayload
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	c := Config{Enabled: true, Mode: ModeWebhook, URL: ts.URL}
+	s := NewTestService(c)
+
+	h := s.Handler(HandlerConfig{Channel: "#alerts-{{ .Level | lower }}"}, log.New(ioutil.Discard, "", 0))
+	var event alert.Event
+	event.State.Level = alert.Critical
+	event.State.Message = "disk full"
+	h.Handle(event)
+
+	if exp := "#alerts-" + strings.ToLower(string(alert.Critical)); received.Channel != exp {
+		t.Fatalf("unexpected rendered channel got %s exp %s", received.Channel, exp)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	cases := []struct {
+		name    string
+		c       Config
+		wantErr bool
+	}{
+		{"webhook missing url", Config{Enabled: true, Mode: ModeWebhook}, true},
+		{"webhook ok", Config{Enabled: true, Mode: ModeWebhook, URL: "http://example.com/hook"}, false},
+		{"api missing server", Config{Enabled: true, Mode: ModeAPI}, true},
+		{"api missing creds", Config{Enabled: true, Mode: ModeAPI, ServerURL: "http://example.com"}, true},
+		{"api both creds", Config{Enabled: true, Mode: ModeAPI, ServerURL: "http://example.com", LoginID: "a", Password: "b", AccessToken: "t"}, true},
+		{"api login ok", Config{Enabled: true, Mode: ModeAPI, ServerURL: "http://example.com", LoginID: "a", Password: "b"}, false},
+		{"api token ok", Config{Enabled: true, Mode: ModeAPI, ServerURL: "http://example.com", AccessToken: "t"}, false},
+		{"unknown mode", Config{Enabled: true, Mode: "carrier-pigeon"}, true},
+	}
+	for _, tc := range cases {
+		err := tc.c.Validate()
+		if tc.wantErr && err == nil {
+			t.Errorf("%s: expected an error, got nil", tc.name)
+		}
+		if !tc.wantErr && err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package msteams
+
+import (
+	"github.com/influxdata/kapacitor/services/httpretry"
+	"github.com/pkg/errors"
+)
+
+// Config is the msteams service section. URL is the default incoming
+// webhook; Channels maps a named channel (as referenced by a handler's
+// Channel option) to an additional webhook URL, since a Microsoft Teams
+// incoming webhook is bound to a single channel at creation time rather
+// than accepting a channel parameter per message like Slack's.
+type Config struct {
+	// Whether Microsoft Teams integration is enabled.
+	Enabled bool `toml:"enabled" override:"enabled"`
+	// Whether this section is used as the default handler for all alerts.
+	Global bool `toml:"global" override:"global"`
+	// Only post a message when the alert state changes.
+	StateChangesOnly bool `toml:"state-changes-only" override:"state-changes-only"`
+	// Default incoming webhook URL.
+	URL string `toml:"url" override:"url,redact"`
+	// Additional named webhook URLs, keyed by channel name.
+	Channels map[string]string `toml:"channels" override:"channels,redact"`
+	// Retry configures backoff and dead-letter forwarding for transient
+	// webhook delivery failures. The zero value disables retries.
+	Retry httpretry.Config `toml:"retry" override:"retry"`
+}
+
+func NewConfig() Config {
+	return Config{}
+}
+
+func (c Config) Validate() error {
+	if c.Enabled && c.URL == "" && len(c.Channels) == 0 {
+		return errors.New("must specify url or at least one channel webhook")
+	}
+	if err := c.Retry.Validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// webhookFor returns the webhook URL for channel, falling back to the
+// default URL when channel is empty or not found among Channels.
+func (c Config) webhookFor(channel string) string {
+	if channel != "" {
+		if u, ok := c.Channels[channel]; ok {
+			return u
+		}
+	}
+	return c.URL
+}
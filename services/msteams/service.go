@@ -0,0 +1,240 @@
+package msteams
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"text/template"
+
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/kapacitor/services/alerttemplate"
+	"github.com/influxdata/kapacitor/services/httpretry"
+)
+
+// themeColor returns the MessageCard accent color (a hex string without
+// the leading '#') Microsoft Teams renders down the left edge of the
+// card, matching the alert level's severity.
+func themeColor(level alert.Level) string {
+	switch level {
+	case alert.OK:
+		return "2DC72D"
+	case alert.Info:
+		return "439FE0"
+	case alert.Warning:
+		return "FFA500"
+	case alert.Critical:
+		return "FF0000"
+	}
+	return "808080"
+}
+
+// fact is a single name/value row rendered in a MessageCard section.
+type fact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type section struct {
+	ActivityTitle string `json:"activityTitle,omitempty"`
+	Text          string `json:"text,omitempty"`
+	Facts         []fact `json:"facts,omitempty"`
+}
+
+// messageCard is the Office 365 Connector Card payload Teams expects
+// from an incoming webhook.
+// https://learn.microsoft.com/en-us/outlook/actionable-messages/message-card-reference
+type messageCard struct {
+	Type       string    `json:"@type"`
+	Context    string    `json:"@context"`
+	Summary    string    `json:"summary"`
+	ThemeColor string    `json:"themeColor"`
+	Title      string    `json:"title,omitempty"`
+	Sections   []section `json:"sections,omitempty"`
+}
+
+type Service struct {
+	configValue atomic.Value
+
+	// DeadLetter, if set, is called with an alert's webhook payload once
+	// every retry attempt for it has failed.
+	DeadLetter func(body []byte, lastErr error) error
+
+	logger *log.Logger
+}
+
+func NewService(c Config, l *log.Logger) *Service {
+	s := &Service{logger: l}
+	s.configValue.Store(c)
+	return s
+}
+
+// httpClient builds an *http.Client applying the configured Retry
+// policy; with retries not configured it makes a single attempt.
+func (s *Service) httpClient() *http.Client {
+	c := s.config()
+	t := &httpretry.Transport{Config: c.Retry}
+	if s.DeadLetter != nil {
+		t.DeadLetter = func(req *http.Request, body []byte, lastErr error) error {
+			return s.DeadLetter(body, lastErr)
+		}
+	}
+	return &http.Client{Transport: t}
+}
+
+func (s *Service) Open() error  { return nil }
+func (s *Service) Close() error { return nil }
+
+func (s *Service) Update(newConfig []interface{}) error {
+	if l := len(newConfig); l != 1 {
+		return fmt.Errorf("expected only one new config object, got %d", l)
+	}
+	c, ok := newConfig[0].(Config)
+	if !ok {
+		return fmt.Errorf("expected config object to be of type %T, got %T", c, newConfig[0])
+	}
+	s.configValue.Store(c)
+	return nil
+}
+
+func (s *Service) config() Config {
+	return s.configValue.Load().(Config)
+}
+
+type testOptions struct {
+	Channel string      `json:"channel"`
+	Title   string      `json:"title"`
+	Message string      `json:"message"`
+	Level   alert.Level `json:"level"`
+}
+
+func (s *Service) TestOptions() interface{} {
+	return &testOptions{
+		Message: "test msteams message",
+		Level:   alert.Warning,
+	}
+}
+
+func (s *Service) Test(options interface{}) error {
+	o, ok := options.(*testOptions)
+	if !ok {
+		return fmt.Errorf("unexpected options type %t", options)
+	}
+	return s.Alert(o.Channel, o.Title, o.Message, o.Level, nil)
+}
+
+// facts builds the MessageCard facts list from an alert's tags and
+// fields, so an operator sees the data that triggered the alert without
+// opening Kapacitor.
+func facts(tags map[string]string, fields map[string]interface{}) []fact {
+	facts := make([]fact, 0, len(tags)+len(fields))
+	for k, v := range tags {
+		facts = append(facts, fact{Name: k, Value: v})
+	}
+	for k, v := range fields {
+		facts = append(facts, fact{Name: k, Value: fmt.Sprintf("%v", v)})
+	}
+	return facts
+}
+
+// Alert posts a MessageCard to channel's webhook (or the default
+// webhook when channel is empty).
+func (s *Service) Alert(channel, title, message string, level alert.Level, tags map[string]string) error {
+	c := s.config()
+	if !c.Enabled {
+		return fmt.Errorf("service is not enabled")
+	}
+
+	webhook := c.webhookFor(channel)
+	if webhook == "" {
+		return fmt.Errorf("no msteams webhook configured for channel %q", channel)
+	}
+
+	card := messageCard{
+		Type:       "MessageCard",
+		Context:    "http://schema.org/extensions",
+		Summary:    message,
+		ThemeColor: themeColor(level),
+		Title:      title,
+		Sections: []section{{
+			ActivityTitle: title,
+			Text:          message,
+			Facts:         facts(tags, nil),
+		}},
+	}
+
+	body, err := json.Marshal(card)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.httpClient().Post(webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("failed to post msteams message: %d %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// HandlerConfig is the per-alert-handler configuration for the msteams
+// node in a TICKscript.
+type HandlerConfig struct {
+	// Channel selects a named webhook from the service's Channels map,
+	// falling back to the default webhook when empty. Channel may be a
+	// text/template expression evaluated against the triggering event
+	// (see alerttemplate.Data), e.g. "{{ index .Tags \"env\" }}" to
+	// route by tag.
+	Channel string `mapstructure:"channel"`
+	// Title overrides the MessageCard title, otherwise the alert's
+	// message is used for both the title and the card body.
+	Title string `mapstructure:"title"`
+}
+
+type handler struct {
+	s               *Service
+	c               HandlerConfig
+	channelTemplate *template.Template
+	logger          *log.Logger
+}
+
+func (s *Service) DefaultHandlerConfig() HandlerConfig {
+	return HandlerConfig{}
+}
+
+func (s *Service) Handler(c HandlerConfig, l *log.Logger) alert.Handler {
+	channelTemplate, err := alerttemplate.Parse("channel", c.Channel)
+	if err != nil {
+		l.Println("E! invalid msteams channel template", err)
+	}
+	return &handler{s: s, c: c, channelTemplate: channelTemplate, logger: l}
+}
+
+func (h *handler) Handle(event alert.Event) {
+	title := h.c.Title
+	if title == "" {
+		title = event.State.Message
+	}
+
+	var tags map[string]string
+	if event.Data.Result.Series != nil && len(event.Data.Result.Series) > 0 {
+		tags = event.Data.Result.Series[0].Tags
+	}
+
+	channel, err := alerttemplate.Render(h.channelTemplate, h.c.Channel, alerttemplate.NewData(event))
+	if err != nil {
+		h.logger.Println("E! failed to render msteams channel template", err)
+		return
+	}
+
+	if err := h.s.Alert(channel, title, event.State.Message, event.State.Level, tags); err != nil {
+		h.logger.Println("E! failed to send event to Microsoft Teams", err)
+	}
+}
@@ -0,0 +1,166 @@
+package msteams
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/kapacitor/services/httpretry/httpretrytest"
+)
+
+func NewTestService(c Config) *Service {
+	return NewService(c, log.New(ioutil.Discard, "", 0))
+}
+
+func TestService_Alert_PostsMessageCard(t *testing.T) {
+	var received messageCard
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Fatal(err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	c := Config{Enabled: true, URL: ts.URL}
+	s := NewTestService(c)
+
+	if err := s.Alert("", "CPU high", "cpu usage above threshold", alert.Critical, map[string]string{"host": "serverA"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, exp := received.Type, "MessageCard"; got != exp {
+		t.Errorf("unexpected @type got %s exp %s", got, exp)
+	}
+	if got, exp := received.ThemeColor, "FF0000"; got != exp {
+		t.Errorf("unexpected themeColor got %s exp %s", got, exp)
+	}
+	if got, exp := received.Title, "CPU high"; got != exp {
+		t.Errorf("unexpected title got %s exp %s", got, exp)
+	}
+	if len(received.Sections) != 1 || len(received.Sections[0].Facts) != 1 || received.Sections[0].Facts[0].Name != "host" {
+		t.Fatalf("expected a tag-derived fact for host, got %+v", received.Sections)
+	}
+}
+
+func TestService_Alert_ChannelOverrideUsesNamedWebhook(t *testing.T) {
+	var hitDefault, hitOps bool
+	defaultTs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hitDefault = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer defaultTs.Close()
+	opsTs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hitOps = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer opsTs.Close()
+
+	c := Config{
+		Enabled:  true,
+		URL:      defaultTs.URL,
+		Channels: map[string]string{"ops": opsTs.URL},
+	}
+	s := NewTestService(c)
+
+	if err := s.Alert("ops", "title", "message", alert.Warning, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !hitOps || hitDefault {
+		t.Fatalf("expected the ops channel webhook to be used, hitDefault=%v hitOps=%v", hitDefault, hitOps)
+	}
+}
+
+func TestService_Alert_NotEnabled(t *testing.T) {
+	s := NewTestService(Config{})
+	if err := s.Alert("", "t", "m", alert.Info, nil); err == nil {
+		t.Error("expected error when service is not enabled")
+	}
+}
+
+func TestService_Alert_RetriesTransientFailureThenSucceeds(t *testing.T) {
+	ts, calls := httpretrytest.FlakyServer(2, http.StatusServiceUnavailable, http.StatusOK)
+	defer ts.Close()
+
+	c := Config{
+		Enabled: true,
+		URL:     ts.URL,
+		Retry:   httpretrytest.Config(3),
+	}
+	s := NewTestService(c)
+
+	if err := s.Alert("", "t", "m", alert.Critical, nil); err != nil {
+		t.Fatal(err)
+	}
+	if *calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", *calls)
+	}
+}
+
+func TestService_Alert_DeadLettersOnFinalFailure(t *testing.T) {
+	ts := httpretrytest.AlwaysFailingServer(http.StatusServiceUnavailable)
+	defer ts.Close()
+
+	c := Config{
+		Enabled: true,
+		URL:     ts.URL,
+		Retry:   httpretrytest.Config(2),
+	}
+	s := NewTestService(c)
+
+	var deadLettered bool
+	s.DeadLetter = func(body []byte, lastErr error) error {
+		deadLettered = true
+		return nil
+	}
+
+	if err := s.Alert("", "t", "m", alert.Critical, nil); err == nil {
+		t.Fatal("expected the final failure to be returned as an error")
+	}
+	if !deadLettered {
+		t.Fatal("expected DeadLetter to be called after retries were exhausted")
+	}
+}
+
+func TestHandler_Handle_ChannelTemplateRoutesByLevel(t *testing.T) {
+	var hit bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	channelKey := strings.ToLower(string(alert.Critical))
+	c := Config{
+		Enabled:  true,
+		Channels: map[string]string{channelKey: ts.URL},
+	}
+	s := NewTestService(c)
+
+	h := s.Handler(HandlerConfig{Channel: "{{ .Level | lower }}"}, log.New(ioutil.Discard, "", 0))
+	var event alert.Event
+	event.State.Level = alert.Critical
+	event.State.Message = "cpu usage above threshold"
+	h.Handle(event)
+
+	if !hit {
+		t.Fatal("expected the rendered channel's webhook to be hit")
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	c := Config{Enabled: true}
+	if err := c.Validate(); err == nil {
+		t.Error("expected error when no url or channels are configured")
+	}
+
+	c = Config{Enabled: true, URL: "http://example.com/webhook"}
+	if err := c.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
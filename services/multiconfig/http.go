@@ -0,0 +1,83 @@
+package multiconfig
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// ElementView is the JSON representation of one config element,
+// addressed at /kapacitor/v1/config/<section>/<name> (name omitted for
+// the default element), mirroring the shape the config API already
+// returns for a section's single element.
+type ElementView struct {
+	Name   string      `json:"name,omitempty"`
+	Link   string      `json:"link"`
+	Config interface{} `json:"options"`
+}
+
+// Handler serves /kapacitor/v1/config/<section>[/<name>] against a
+// Store, listing every named element of a section or returning a single
+// one.
+type Handler struct {
+	Store *Store
+	// BasePath is the URL prefix before the section name, e.g.
+	// "/kapacitor/v1/config/".
+	BasePath string
+}
+
+func NewHandler(store *Store, basePath string) *Handler {
+	return &Handler{Store: store, BasePath: basePath}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	trimmed := strings.TrimPrefix(req.URL.Path, h.BasePath)
+	trimmed = strings.Trim(trimmed, "/")
+	if trimmed == "" {
+		h.serveSections(w)
+		return
+	}
+
+	parts := strings.SplitN(trimmed, "/", 2)
+	section := h.Store.Section(parts[0])
+
+	if len(parts) == 1 {
+		h.serveSection(w, parts[0], section)
+		return
+	}
+
+	name := parts[1]
+	cfg, ok := section.Get(name)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	writeJSON(w, ElementView{Name: name, Link: h.BasePath + parts[0] + "/" + name, Config: cfg})
+}
+
+func (h *Handler) serveSections(w http.ResponseWriter) {
+	writeJSON(w, map[string]interface{}{"sections": h.Store.Sections()})
+}
+
+func (h *Handler) serveSection(w http.ResponseWriter, name string, section *Section) {
+	elements := section.List()
+	views := make([]ElementView, len(elements))
+	for i, e := range elements {
+		link := h.BasePath + name + "/"
+		if e.Name != DefaultName {
+			link += e.Name
+		}
+		views[i] = ElementView{Name: e.Name, Link: link, Config: e.Config}
+	}
+	writeJSON(w, map[string]interface{}{"elements": views})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,56 @@
+package multiconfig
+
+import "github.com/pkg/errors"
+
+// Registry holds one live handler instance per named element of a
+// section, e.g. a notification service keeping a configured webhook client
+// per workspace so a TICKscript node's `.workspace('devops')` resolves
+// to the right one. It shares Section's storage shape since "named
+// instance of a thing" is the same problem whether the thing is a
+// config struct or a running client.
+type Registry struct {
+	section *Section
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{section: NewSection()}
+}
+
+// Set installs instance under name, replacing any previous instance
+// there — called whenever Update applies a new or changed config
+// element so handlers are rebuilt against current settings.
+func (r *Registry) Set(name string, instance interface{}) {
+	// Set never fails: Section.Set always returns nil.
+	_ = r.section.Set(name, instance)
+}
+
+// Remove drops name's instance.
+func (r *Registry) Remove(name string) error {
+	return r.section.Remove(name)
+}
+
+// Instance resolves name to its registered instance, returning an error
+// naming the missing element so a TICKscript referencing an unknown
+// workspace/service fails with an actionable message at start-up.
+func (r *Registry) Instance(name string) (interface{}, error) {
+	instance, ok := r.section.Get(name)
+	if !ok {
+		if name == DefaultName {
+			return nil, errors.New("multiconfig: no default instance is configured")
+		}
+		return nil, errors.Errorf("multiconfig: no instance named %q is configured", name)
+	}
+	return instance, nil
+}
+
+// Names returns every name currently registered, the default instance
+// (if any) first.
+func (r *Registry) Names() []string {
+	elements := r.section.List()
+	names := make([]string, len(elements))
+	for i, e := range elements {
+		names[i] = e.Name
+	}
+	return names
+}
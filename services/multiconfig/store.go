@@ -0,0 +1,138 @@
+// Package multiconfig lets a config section hold more than one named
+// element, e.g. a "devops" and a "customer-alerts" Slack workspace both
+// configured under the "slack" section, instead of the single anonymous
+// element every section was previously limited to.
+package multiconfig
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultName is the key used for a section's anonymous element, the
+// one addressed by the bare `/kapacitor/v1/config/<section>/` URL that
+// every section already supported before named elements existed.
+const DefaultName = ""
+
+// Element is one named instance of a config section's settings.
+type Element struct {
+	Name   string
+	Config interface{}
+}
+
+// Section holds every named element for a single config section.
+type Section struct {
+	mu       sync.RWMutex
+	elements map[string]Element
+}
+
+// NewSection returns an empty Section.
+func NewSection() *Section {
+	return &Section{elements: make(map[string]Element)}
+}
+
+// Add creates a new element named name, failing if one already exists
+// under that name — use Set to update an existing element.
+func (s *Section) Add(name string, cfg interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.elements[name]; ok {
+		if name == DefaultName {
+			return errors.New("multiconfig: the default element already exists, use Set to update it")
+		}
+		return errors.Errorf("multiconfig: an element named %q already exists", name)
+	}
+	s.elements[name] = Element{Name: name, Config: cfg}
+	return nil
+}
+
+// Set replaces name's config, creating the element if it doesn't exist
+// yet — matching a section's historical behavior of always having a
+// default element, even before it was ever explicitly added.
+func (s *Section) Set(name string, cfg interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.elements[name] = Element{Name: name, Config: cfg}
+	return nil
+}
+
+// Remove deletes name's element, failing if it doesn't exist.
+func (s *Section) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.elements[name]; !ok {
+		return errors.Errorf("multiconfig: no element named %q", name)
+	}
+	delete(s.elements, name)
+	return nil
+}
+
+// Get returns name's config and whether it exists.
+func (s *Section) Get(name string) (interface{}, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.elements[name]
+	return e.Config, ok
+}
+
+// List returns every element in the section, ordered by name with the
+// default element (empty name) first.
+func (s *Section) List() []Element {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]Element, 0, len(s.elements))
+	for _, e := range s.elements {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name == DefaultName {
+			return true
+		}
+		if out[j].Name == DefaultName {
+			return false
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// Store is the registry of every config section's named elements.
+type Store struct {
+	mu       sync.Mutex
+	sections map[string]*Section
+}
+
+// NewStore returns an empty Store.
+func NewStore() *Store {
+	return &Store{sections: make(map[string]*Section)}
+}
+
+// Section returns name's Section, creating it if this is the first
+// reference to it.
+func (st *Store) Section(name string) *Section {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	s, ok := st.sections[name]
+	if !ok {
+		s = NewSection()
+		st.sections[name] = s
+	}
+	return s
+}
+
+// Sections returns the names of every section that has been referenced
+// so far, sorted.
+func (st *Store) Sections() []string {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	names := make([]string, 0, len(st.sections))
+	for name := range st.sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
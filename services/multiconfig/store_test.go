@@ -0,0 +1,135 @@
+package multiconfig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSection_AddSetRemove(t *testing.T) {
+	s := NewSection()
+
+	if err := s.Add("devops", map[string]string{"workspace": "devops"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add("devops", map[string]string{"workspace": "again"}); err == nil {
+		t.Fatal("expected Add to fail for a name that already exists")
+	}
+
+	if err := s.Set("devops", map[string]string{"workspace": "devops-updated"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, ok := s.Get("devops")
+	if !ok || cfg.(map[string]string)["workspace"] != "devops-updated" {
+		t.Fatalf("unexpected config after Set: %+v", cfg)
+	}
+
+	if err := s.Remove("devops"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Get("devops"); ok {
+		t.Fatal("expected devops to be gone after Remove")
+	}
+	if err := s.Remove("devops"); err == nil {
+		t.Fatal("expected Remove to fail for a name that no longer exists")
+	}
+}
+
+func TestSection_SetCreatesDefaultElementImplicitly(t *testing.T) {
+	s := NewSection()
+	if err := s.Set(DefaultName, "anonymous config"); err != nil {
+		t.Fatal(err)
+	}
+	cfg, ok := s.Get(DefaultName)
+	if !ok || cfg != "anonymous config" {
+		t.Fatalf("unexpected default element: %+v", cfg)
+	}
+}
+
+func TestSection_ListOrdersDefaultFirstThenSorted(t *testing.T) {
+	s := NewSection()
+	s.Set(DefaultName, "default")
+	s.Add("zebra", "z")
+	s.Add("alpha", "a")
+
+	got := s.List()
+	want := []string{DefaultName, "alpha", "zebra"}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected element count: %+v", got)
+	}
+	for i, name := range want {
+		if got[i].Name != name {
+			t.Fatalf("element %d: got name %q, want %q", i, got[i].Name, name)
+		}
+	}
+}
+
+func TestRegistry_InstanceErrorsNameTheMissingElement(t *testing.T) {
+	r := NewRegistry()
+	r.Set("devops", "devops-client")
+
+	if _, err := r.Instance("customer"); err == nil {
+		t.Fatal("expected an error for an unknown instance name")
+	}
+	instance, err := r.Instance("devops")
+	if err != nil || instance != "devops-client" {
+		t.Fatalf("unexpected result: %v %v", instance, err)
+	}
+}
+
+func TestHandler_ServesSectionsSectionAndElement(t *testing.T) {
+	store := NewStore()
+	slack := store.Section("slack")
+	slack.Set(DefaultName, map[string]string{"channel": "#general"})
+	slack.Add("devops", map[string]string{"channel": "#devops"})
+
+	h := NewHandler(store, "/kapacitor/v1/config/")
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/kapacitor/v1/config/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sections map[string][]string
+	json.NewDecoder(resp.Body).Decode(&sections)
+	resp.Body.Close()
+	if len(sections["sections"]) != 1 || sections["sections"][0] != "slack" {
+		t.Fatalf("unexpected sections listing: %+v", sections)
+	}
+
+	resp, err = http.Get(srv.URL + "/kapacitor/v1/config/slack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var section map[string][]ElementView
+	json.NewDecoder(resp.Body).Decode(&section)
+	resp.Body.Close()
+	if len(section["elements"]) != 2 {
+		t.Fatalf("expected both the default and devops elements, got %+v", section)
+	}
+
+	resp, err = http.Get(srv.URL + "/kapacitor/v1/config/slack/devops")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for a known named element, got %d", resp.StatusCode)
+	}
+	var element ElementView
+	json.NewDecoder(resp.Body).Decode(&element)
+	resp.Body.Close()
+	if element.Name != "devops" {
+		t.Fatalf("unexpected element: %+v", element)
+	}
+
+	resp, err = http.Get(srv.URL + "/kapacitor/v1/config/slack/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown named element, got %d", resp.StatusCode)
+	}
+	resp.Body.Close()
+}
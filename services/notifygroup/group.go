@@ -0,0 +1,149 @@
+// Package notifygroup fans a single alert out to a set of already
+// configured downstream handlers, ordered by priority, in one of two
+// dispatch modes: broadcast (send to every member) or failover (try
+// members lowest-priority-first, falling through to the next member
+// only if the previous one returns an error).
+//
+// alert.Handler.Handle is fire-and-forget, which is exactly the problem
+// a failover group needs to see past: it has to know whether a member
+// succeeded before deciding whether to try the next one. Rather than
+// changing that interface, which every existing handler in this tree
+// implements, Group reuses handlerchain.Action, the same error-returning
+// adapter handlerchain invented for the same reason, as the shape of a
+// group member's downstream call.
+package notifygroup
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/kapacitor/services/handlerchain"
+)
+
+// Mode selects how a Group dispatches to its Members.
+type Mode string
+
+const (
+	// ModeBroadcast sends the event to every Member, regardless of
+	// whether earlier members succeeded.
+	ModeBroadcast Mode = "broadcast"
+	// ModeFailover tries Members in ascending Priority order, stopping
+	// at the first one that succeeds.
+	ModeFailover Mode = "failover"
+)
+
+func (m Mode) valid() bool {
+	return m == ModeBroadcast || m == ModeFailover
+}
+
+// Member is one downstream destination in a Group, wrapping an
+// already-configured handler's error-returning Action.
+type Member struct {
+	// Service names the destination for logging, e.g. "pushover" or
+	// "smtp". It has no effect on dispatch.
+	Service string
+	// Priority orders Members for ModeFailover, lowest first. Ties keep
+	// the order Members were given in. ModeBroadcast ignores Priority.
+	Priority int
+	// Action delivers the event to this member, returning an error if
+	// delivery failed.
+	Action handlerchain.Action
+}
+
+// Group implements alert.Handler, fanning out to Members according to
+// Mode.
+type Group struct {
+	Mode    Mode
+	Members []Member
+	logger  *log.Logger
+}
+
+// NewGroup returns a Group dispatching to members in mode. members is
+// copied and, for ModeFailover, sorted by ascending Priority.
+func NewGroup(mode Mode, members []Member, l *log.Logger) (*Group, error) {
+	if !mode.valid() {
+		return nil, fmt.Errorf("unknown notifygroup mode %q", mode)
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("notifygroup must have at least one member")
+	}
+
+	ordered := make([]Member, len(members))
+	copy(ordered, members)
+	if mode == ModeFailover {
+		sortByPriority(ordered)
+	}
+
+	return &Group{
+		Mode:    mode,
+		Members: ordered,
+		logger:  l,
+	}, nil
+}
+
+// sortByPriority stable-sorts members by ascending Priority, preserving
+// the given order of ties.
+func sortByPriority(members []Member) {
+	for i := 1; i < len(members); i++ {
+		for j := i; j > 0 && members[j].Priority < members[j-1].Priority; j-- {
+			members[j], members[j-1] = members[j-1], members[j]
+		}
+	}
+}
+
+// Handle implements alert.Handler, translating event into a
+// handlerchain.State and dispatching it to Members.
+func (g *Group) Handle(event alert.Event) {
+	var tags map[string]string
+	if len(event.Data.Result.Series) > 0 {
+		tags = event.Data.Result.Series[0].Tags
+	}
+	s := handlerchain.State{
+		Level:   event.State.Level,
+		Message: event.State.Message,
+		Tags:    tags,
+	}
+	if err := g.Dispatch(s); err != nil {
+		g.logger.Println("E! notifygroup failed to deliver event", err)
+	}
+}
+
+// Dispatch sends s to Members according to Mode. For ModeBroadcast it
+// returns the first error encountered, after every member has been
+// tried. For ModeFailover it returns nil on the first member to
+// succeed, or the last member's error if all of them fail.
+func (g *Group) Dispatch(s handlerchain.State) error {
+	switch g.Mode {
+	case ModeFailover:
+		return g.dispatchFailover(s)
+	default:
+		return g.dispatchBroadcast(s)
+	}
+}
+
+func (g *Group) dispatchBroadcast(s handlerchain.State) error {
+	var firstErr error
+	for _, m := range g.Members {
+		if err := m.Action(s); err != nil {
+			g.logger.Printf("E! notifygroup member %s failed: %s", m.Service, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+func (g *Group) dispatchFailover(s handlerchain.State) error {
+	var lastErr error
+	for _, m := range g.Members {
+		if err := m.Action(s); err != nil {
+			g.logger.Printf("E! notifygroup member %s failed, trying next: %s", m.Service, err)
+			lastErr = err
+			continue
+		}
+		return nil
+	}
+	return lastErr
+}
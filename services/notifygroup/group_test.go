@@ -0,0 +1,84 @@
+package notifygroup
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"testing"
+
+	"github.com/influxdata/kapacitor/services/handlerchain"
+)
+
+func TestGroup_Broadcast_SendsToEveryMember(t *testing.T) {
+	var pushoverRan, smtpRan bool
+	members := []Member{
+		{Service: "pushover", Priority: 10, Action: func(s handlerchain.State) error { pushoverRan = true; return fmt.Errorf("down") }},
+		{Service: "smtp", Priority: 30, Action: func(s handlerchain.State) error { smtpRan = true; return nil }},
+	}
+	g, err := NewGroup(ModeBroadcast, members, log.New(ioutil.Discard, "", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := g.Dispatch(handlerchain.State{Message: "disk full"}); err == nil {
+		t.Fatal("expected the first member's error to be returned")
+	}
+	if !pushoverRan || !smtpRan {
+		t.Fatalf("expected both members to run, got pushoverRan=%v smtpRan=%v", pushoverRan, smtpRan)
+	}
+}
+
+func TestGroup_Failover_StopsAtFirstSuccess(t *testing.T) {
+	var pushoverRan, slackRan, smtpRan bool
+	members := []Member{
+		{Service: "smtp", Priority: 30, Action: func(s handlerchain.State) error { smtpRan = true; return nil }},
+		{Service: "pushover", Priority: 10, Action: func(s handlerchain.State) error { pushoverRan = true; return fmt.Errorf("down") }},
+		{Service: "slack", Priority: 20, Action: func(s handlerchain.State) error { slackRan = true; return nil }},
+	}
+	g, err := NewGroup(ModeFailover, members, log.New(ioutil.Discard, "", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := g.Dispatch(handlerchain.State{Message: "disk full"}); err != nil {
+		t.Fatal(err)
+	}
+	if !pushoverRan {
+		t.Fatal("expected the lowest-priority member to be tried first")
+	}
+	if !slackRan {
+		t.Fatal("expected the next member to be tried after pushover failed")
+	}
+	if smtpRan {
+		t.Fatal("did not expect smtp to run once slack succeeded")
+	}
+}
+
+func TestGroup_Failover_ReturnsLastErrorWhenAllMembersFail(t *testing.T) {
+	members := []Member{
+		{Service: "pushover", Priority: 10, Action: func(s handlerchain.State) error { return fmt.Errorf("pushover down") }},
+		{Service: "smtp", Priority: 20, Action: func(s handlerchain.State) error { return fmt.Errorf("smtp down") }},
+	}
+	g, err := NewGroup(ModeFailover, members, log.New(ioutil.Discard, "", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = g.Dispatch(handlerchain.State{})
+	if err == nil || err.Error() != "smtp down" {
+		t.Fatalf("expected the last member's error, got %v", err)
+	}
+}
+
+func TestNewGroup_RejectsUnknownMode(t *testing.T) {
+	members := []Member{{Service: "pushover", Action: func(s handlerchain.State) error { return nil }}}
+	if _, err := NewGroup(Mode("carrier-pigeon"), members, nil); err == nil {
+		t.Fatal("expected an error for an unknown mode")
+	}
+}
+
+func TestNewGroup_RejectsEmptyMembers(t *testing.T) {
+	if _, err := NewGroup(ModeBroadcast, nil, nil); err == nil {
+		t.Fatal("expected an error when no members are given")
+	}
+}
@@ -0,0 +1,165 @@
+package oidcauth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Role is the Kapacitor role derived from a verified token's RoleClaim.
+type Role string
+
+const (
+	RoleAdmin Role = "admin"
+	RoleRead  Role = "read"
+	RoleWrite Role = "write"
+)
+
+// Identity is the result of successfully authenticating a bearer token.
+type Identity struct {
+	Subject string
+	Role    Role
+	Claims  Claims
+}
+
+// Authenticator verifies bearer tokens against Config's issuer using
+// Keys, and derives a Role from the configured RoleClaim/RoleMapping.
+type Authenticator struct {
+	Config Config
+	Keys   *KeyCache
+	Now    func() time.Time
+}
+
+// NewAuthenticator builds an Authenticator for c, fetching keys from
+// c.IssuerURL + "/.well-known/jwks.json" unless keys is supplied
+// (e.g. in tests, pointing at a fake JWKS endpoint).
+func NewAuthenticator(c Config, keys *KeyCache) *Authenticator {
+	if keys == nil {
+		keys = NewKeyCache(c.IssuerURL+"/.well-known/jwks.json", c.JWKSRefreshInterval)
+	}
+	return &Authenticator{Config: c, Keys: keys, Now: time.Now}
+}
+
+// Authenticate verifies raw as a bearer token and returns the derived
+// Identity, or an error if the signature, standard claims, or any
+// RequiredClaims don't check out.
+func (a *Authenticator) Authenticate(raw string) (*Identity, error) {
+	if !a.Config.Enabled {
+		return nil, errors.New("oidcauth: OIDC auth is not enabled")
+	}
+
+	tok, err := parseToken(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	pub, err := a.Keys.Get(tok.kid)
+	if err != nil {
+		return nil, fmt.Errorf("oidcauth: fetching signing key: %w", err)
+	}
+	if err := tok.verify(pub); err != nil {
+		return nil, fmt.Errorf("oidcauth: signature verification failed: %w", err)
+	}
+
+	if err := a.checkStandardClaims(tok.claims); err != nil {
+		return nil, err
+	}
+	if err := a.checkRequiredClaims(tok.claims); err != nil {
+		return nil, err
+	}
+
+	role, err := a.deriveRole(tok.claims)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, _ := tok.claims["sub"].(string)
+	return &Identity{Subject: sub, Role: role, Claims: tok.claims}, nil
+}
+
+func (a *Authenticator) checkStandardClaims(claims Claims) error {
+	now := a.Now()
+
+	if iss, _ := claims["iss"].(string); iss != a.Config.IssuerURL {
+		return fmt.Errorf("oidcauth: unexpected issuer %q", iss)
+	}
+
+	if !audMatches(claims["aud"], a.Config.ClientID) {
+		return errors.New("oidcauth: token audience does not include configured client-id")
+	}
+
+	if exp, ok := numericClaim(claims, "exp"); ok {
+		if now.After(time.Unix(exp, 0)) {
+			return errors.New("oidcauth: token has expired")
+		}
+	} else {
+		return errors.New("oidcauth: token is missing exp claim")
+	}
+
+	if nbf, ok := numericClaim(claims, "nbf"); ok {
+		if now.Before(time.Unix(nbf, 0)) {
+			return errors.New("oidcauth: token not yet valid (nbf)")
+		}
+	}
+	return nil
+}
+
+func (a *Authenticator) checkRequiredClaims(claims Claims) error {
+	for name, want := range a.Config.RequiredClaims {
+		got, ok := claims[name].(string)
+		if !ok || got != want {
+			return fmt.Errorf("oidcauth: required claim %q not satisfied", name)
+		}
+	}
+	return nil
+}
+
+func (a *Authenticator) deriveRole(claims Claims) (Role, error) {
+	if a.Config.RoleClaim == "" {
+		return RoleRead, nil
+	}
+
+	values := claimValues(claims[a.Config.RoleClaim])
+	for _, v := range values {
+		if mapped, ok := a.Config.RoleMapping[v]; ok {
+			return Role(mapped), nil
+		}
+	}
+	return "", fmt.Errorf("oidcauth: no role-mapping entry matched claim %q", a.Config.RoleClaim)
+}
+
+func claimValues(v interface{}) []string {
+	switch t := v.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		out := make([]string, 0, len(t))
+		for _, e := range t {
+			if s, ok := e.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+func audMatches(aud interface{}, clientID string) bool {
+	for _, v := range claimValues(aud) {
+		if v == clientID {
+			return true
+		}
+	}
+	return false
+}
+
+// numericClaim reads a JSON number claim, which json.Unmarshal decodes
+// into a float64.
+func numericClaim(claims Claims, name string) (int64, bool) {
+	v, ok := claims[name].(float64)
+	if !ok {
+		return 0, false
+	}
+	return int64(v), true
+}
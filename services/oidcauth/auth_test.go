@@ -0,0 +1,213 @@
+package oidcauth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// encodeExponent converts an RSA public exponent (a small int, almost
+// always 65537) to its minimal big-endian byte representation, as JWKS
+// expects for the "e" field.
+func encodeExponent(e int) []byte {
+	if e == 0 {
+		return []byte{0}
+	}
+	var b []byte
+	for e > 0 {
+		b = append([]byte{byte(e & 0xff)}, b...)
+		e >>= 8
+	}
+	return b
+}
+
+func base64URLEncode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// signRS256 builds a complete JWT string for claims, signed with key and
+// tagged with kid, so tests don't need a real OIDC provider.
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+
+	header := map[string]interface{}{"alg": "RS256", "typ": "JWT", "kid": kid}
+	headerJSON, _ := json.Marshal(header)
+	claimsJSON, _ := json.Marshal(claims)
+
+	signingInput := base64URLEncode(headerJSON) + "." + base64URLEncode(claimsJSON)
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signingInput + "." + base64URLEncode(sig)
+}
+
+func jwksServer(t *testing.T, key *rsa.PrivateKey, kid string) *httptest.Server {
+	t.Helper()
+
+	eBytes := encodeExponent(key.PublicKey.E)
+	set := jwkSet{
+		Keys: []jwk{{
+			Kty: "RSA",
+			Kid: kid,
+			Alg: "RS256",
+			N:   base64URLEncode(key.PublicKey.N.Bytes()),
+			E:   base64URLEncode(eBytes),
+		}},
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(set)
+	}))
+}
+
+func testAuthenticator(t *testing.T, key *rsa.PrivateKey, kid string, configure func(*Config)) (*Authenticator, func()) {
+	t.Helper()
+
+	srv := jwksServer(t, key, kid)
+
+	c := NewConfig()
+	c.Enabled = true
+	c.IssuerURL = "https://issuer.example.com"
+	c.ClientID = "kapacitor"
+	if configure != nil {
+		configure(&c)
+	}
+
+	keys := NewKeyCache(srv.URL, time.Minute)
+	a := NewAuthenticator(c, keys)
+	return a, srv.Close
+}
+
+func baseClaims(a *Authenticator, now time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"iss": a.Config.IssuerURL,
+		"aud": a.Config.ClientID,
+		"sub": "user-1",
+		"exp": now.Add(time.Hour).Unix(),
+		"nbf": now.Add(-time.Minute).Unix(),
+	}
+}
+
+func TestAuthenticator_AcceptsValidToken(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	a, closeSrv := testAuthenticator(t, key, "key-1", nil)
+	defer closeSrv()
+
+	now := time.Now()
+	a.Now = func() time.Time { return now }
+
+	token := signRS256(t, key, "key-1", baseClaims(a, now))
+	id, err := a.Authenticate(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.Subject != "user-1" {
+		t.Fatalf("unexpected subject: %s", id.Subject)
+	}
+}
+
+func TestAuthenticator_RejectsExpiredToken(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	a, closeSrv := testAuthenticator(t, key, "key-1", nil)
+	defer closeSrv()
+
+	now := time.Now()
+	a.Now = func() time.Time { return now }
+
+	claims := baseClaims(a, now)
+	claims["exp"] = now.Add(-time.Minute).Unix()
+	token := signRS256(t, key, "key-1", claims)
+
+	if _, err := a.Authenticate(token); err == nil {
+		t.Fatal("expected an expired token to be rejected")
+	}
+}
+
+func TestAuthenticator_RejectsWrongIssuer(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	a, closeSrv := testAuthenticator(t, key, "key-1", nil)
+	defer closeSrv()
+
+	now := time.Now()
+	a.Now = func() time.Time { return now }
+
+	claims := baseClaims(a, now)
+	claims["iss"] = "https://evil.example.com"
+	token := signRS256(t, key, "key-1", claims)
+
+	if _, err := a.Authenticate(token); err == nil {
+		t.Fatal("expected a mismatched issuer to be rejected")
+	}
+}
+
+func TestAuthenticator_RequiredClaimsMustMatch(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	a, closeSrv := testAuthenticator(t, key, "key-1", func(c *Config) {
+		c.RequiredClaims = map[string]string{"tenant": "acme"}
+	})
+	defer closeSrv()
+
+	now := time.Now()
+	a.Now = func() time.Time { return now }
+
+	claims := baseClaims(a, now)
+	token := signRS256(t, key, "key-1", claims)
+	if _, err := a.Authenticate(token); err == nil {
+		t.Fatal("expected missing required claim to be rejected")
+	}
+
+	claims["tenant"] = "acme"
+	token = signRS256(t, key, "key-1", claims)
+	if _, err := a.Authenticate(token); err != nil {
+		t.Fatalf("expected matching required claim to be accepted, got %v", err)
+	}
+}
+
+func TestAuthenticator_DerivesRoleFromRoleMapping(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	a, closeSrv := testAuthenticator(t, key, "key-1", func(c *Config) {
+		c.RoleClaim = "groups"
+		c.RoleMapping = map[string]string{"kapacitor-admins": "admin"}
+	})
+	defer closeSrv()
+
+	now := time.Now()
+	a.Now = func() time.Time { return now }
+
+	claims := baseClaims(a, now)
+	claims["groups"] = []interface{}{"everyone", "kapacitor-admins"}
+	token := signRS256(t, key, "key-1", claims)
+
+	id, err := a.Authenticate(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.Role != RoleAdmin {
+		t.Fatalf("expected role admin, got %s", id.Role)
+	}
+}
+
+func TestAuthenticator_RejectsBadSignature(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	a, closeSrv := testAuthenticator(t, key, "key-1", nil)
+	defer closeSrv()
+
+	now := time.Now()
+	a.Now = func() time.Time { return now }
+
+	// Signed with a different key than the one published under "key-1".
+	token := signRS256(t, otherKey, "key-1", baseClaims(a, now))
+	if _, err := a.Authenticate(token); err == nil {
+		t.Fatal("expected a token signed by an unrecognized key to be rejected")
+	}
+}
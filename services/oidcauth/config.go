@@ -0,0 +1,65 @@
+// Package oidcauth implements an OIDC/Keycloak-style bearer auth
+// provider for the Kapacitor HTTP API: it verifies RS256/ES256 tokens
+// against a provider's published JWKS and maps claims to Kapacitor
+// roles.
+package oidcauth
+
+import (
+	"errors"
+	"time"
+)
+
+// Config is the `/kapacitor/v1/config/auth/oidc` dynamic config section.
+type Config struct {
+	Enabled bool `toml:"enabled" override:"enabled"`
+
+	IssuerURL           string        `toml:"issuer-url" override:"issuer-url"`
+	ClientID            string        `toml:"client-id" override:"client-id"`
+	ClientSecret        string        `toml:"client-secret" override:"client-secret,redact"`
+	JWKSRefreshInterval time.Duration `toml:"jwks-refresh-interval" override:"jwks-refresh-interval"`
+
+	// RequiredClaims must all be present in a token's claims, with
+	// matching values, for the token to be accepted.
+	RequiredClaims map[string]string `toml:"required-claims" override:"required-claims"`
+
+	// RoleClaim names the claim whose value (or, for a list claim, any
+	// of its values) is looked up in RoleMapping to derive the
+	// Kapacitor role for a request.
+	RoleClaim   string            `toml:"role-claim" override:"role-claim"`
+	RoleMapping map[string]string `toml:"role-mapping" override:"role-mapping"`
+
+	SessionTokenLifetime time.Duration `toml:"session.token-lifetime" override:"session.token-lifetime"`
+	PKCE                 bool          `toml:"pkce" override:"pkce"`
+}
+
+func NewConfig() Config {
+	return Config{
+		JWKSRefreshInterval:  5 * time.Minute,
+		SessionTokenLifetime: time.Hour,
+		RoleMapping:          make(map[string]string),
+		RequiredClaims:       make(map[string]string),
+	}
+}
+
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.IssuerURL == "" {
+		return errors.New("oidcauth: issuer-url is required when enabled")
+	}
+	if c.ClientID == "" {
+		return errors.New("oidcauth: client-id is required when enabled")
+	}
+	if c.RoleClaim != "" && len(c.RoleMapping) == 0 {
+		return errors.New("oidcauth: role-mapping must not be empty when role-claim is set")
+	}
+	for _, role := range c.RoleMapping {
+		switch role {
+		case "admin", "read", "write":
+		default:
+			return errors.New("oidcauth: role-mapping values must be one of admin, read, write, got " + role)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package oidcauth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// Claims is a parsed JWT payload.
+type Claims map[string]interface{}
+
+// token is a parsed-but-not-yet-verified JWT.
+type token struct {
+	header       map[string]interface{}
+	claims       Claims
+	signingInput []byte
+	signature    []byte
+	alg          string
+	kid          string
+}
+
+func parseToken(raw string) (*token, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("oidcauth: malformed token, expected 3 dot-separated parts")
+	}
+
+	headerJSON, err := base64URLDecode(parts[0])
+	if err != nil {
+		return nil, errors.New("oidcauth: invalid header encoding: " + err.Error())
+	}
+	var header map[string]interface{}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, errors.New("oidcauth: invalid header JSON: " + err.Error())
+	}
+
+	claimsJSON, err := base64URLDecode(parts[1])
+	if err != nil {
+		return nil, errors.New("oidcauth: invalid claims encoding: " + err.Error())
+	}
+	var claims Claims
+	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+		return nil, errors.New("oidcauth: invalid claims JSON: " + err.Error())
+	}
+
+	sig, err := base64URLDecode(parts[2])
+	if err != nil {
+		return nil, errors.New("oidcauth: invalid signature encoding: " + err.Error())
+	}
+
+	alg, _ := header["alg"].(string)
+	kid, _ := header["kid"].(string)
+
+	return &token{
+		header:       header,
+		claims:       claims,
+		signingInput: []byte(parts[0] + "." + parts[1]),
+		signature:    sig,
+		alg:          alg,
+		kid:          kid,
+	}, nil
+}
+
+func base64URLDecode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
+// verify checks t's signature against pub using t's alg, which must be
+// RS256 or ES256 — the two algorithms this provider supports.
+func (t *token) verify(pub crypto.PublicKey) error {
+	digest := sha256.Sum256(t.signingInput)
+
+	switch t.alg {
+	case "RS256":
+		rsaPub, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("oidcauth: key is not an RSA public key for RS256 token")
+		}
+		return rsa.VerifyPKCS1v15(rsaPub, crypto.SHA256, digest[:], t.signature)
+	case "ES256":
+		ecPub, ok := pub.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.New("oidcauth: key is not an EC public key for ES256 token")
+		}
+		if len(t.signature) != 64 {
+			return errors.New("oidcauth: ES256 signature must be 64 bytes (r||s)")
+		}
+		r := new(big.Int).SetBytes(t.signature[:32])
+		s := new(big.Int).SetBytes(t.signature[32:])
+		if !ecdsa.Verify(ecPub, digest[:], r, s) {
+			return errors.New("oidcauth: ES256 signature verification failed")
+		}
+		return nil
+	default:
+		return errors.New("oidcauth: unsupported signing algorithm " + t.alg)
+	}
+}
@@ -0,0 +1,53 @@
+package oidcauth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type contextKey int
+
+const identityContextKey contextKey = 0
+
+// IdentityFromContext returns the Identity a prior call to Middleware
+// stored on req's context, if any.
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	id, ok := ctx.Value(identityContextKey).(*Identity)
+	return id, ok
+}
+
+// Middleware enforces bearer-token auth using a, passing through
+// unauthenticated when OIDC auth isn't enabled so it can be wired in
+// unconditionally alongside Kapacitor's other auth methods.
+func Middleware(a *Authenticator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.Config.Enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token := bearerToken(r.Header.Get("Authorization"))
+		if token == "" {
+			http.Error(w, "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		identity, err := a.Authenticate(token)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), identityContextKey, identity)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func bearerToken(header string) string {
+	const prefix = "Bearer "
+	if !strings.HasPrefix(header, prefix) {
+		return ""
+	}
+	return strings.TrimPrefix(header, prefix)
+}
@@ -0,0 +1,102 @@
+// Package progressstream publishes recording/replay progress over an
+// internal pub/sub bus and exposes it to clients as Server-Sent Events, so
+// callers can watch a recording or replay run to completion instead of
+// sleep-polling its status.
+package progressstream
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType distinguishes the three kinds of event a subscriber receives.
+type EventType string
+
+const (
+	EventProgress EventType = "progress"
+	EventError    EventType = "error"
+	EventFinished EventType = "finished"
+)
+
+// Event is a single point-in-time update for one recording or replay.
+type Event struct {
+	Type            EventType     `json:"type"`
+	PointsProcessed int64         `json:"pointsProcessed,omitempty"`
+	BytesWritten    int64         `json:"bytesWritten,omitempty"`
+	CurrentTime     time.Time     `json:"currentTime,omitempty"`
+	ETA             time.Duration `json:"eta,omitempty"`
+	Error           string        `json:"error,omitempty"`
+}
+
+const subscriberBuffer = 16
+
+// Bus fans out Events published for an id (a recording or replay ID) to
+// every current subscriber.
+type Bus struct {
+	mu   sync.Mutex
+	subs map[string][]chan Event
+}
+
+func NewBus() *Bus {
+	return &Bus{subs: make(map[string][]chan Event)}
+}
+
+// Subscribe returns a channel that receives every Event published for id
+// from this point on, and an unsubscribe func the caller must call when
+// done reading.
+func (b *Bus) Subscribe(id string) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	b.subs[id] = append(b.subs[id], ch)
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		subs := b.subs[id]
+		for i, c := range subs {
+			if c == ch {
+				b.subs[id] = append(subs[:i], subs[i+1:]...)
+				break
+			}
+		}
+	}
+	return ch, unsubscribe
+}
+
+// Publish delivers ev to every current subscriber of id. A slow
+// subscriber whose buffer is full drops the event rather than blocking
+// the publisher.
+func (b *Bus) Publish(id string, ev Event) {
+	b.mu.Lock()
+	subs := append([]chan Event(nil), b.subs[id]...)
+	b.mu.Unlock()
+
+	for _, ch := range subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// Finish publishes a terminal "finished" or "error" event for id and
+// closes every current subscriber's channel, since no further events will
+// ever be published for it.
+func (b *Bus) Finish(id string, err error) {
+	ev := Event{Type: EventFinished}
+	if err != nil {
+		ev = Event{Type: EventError, Error: err.Error()}
+	}
+	b.Publish(id, ev)
+
+	b.mu.Lock()
+	subs := b.subs[id]
+	delete(b.subs, id)
+	b.mu.Unlock()
+
+	for _, ch := range subs {
+		close(ch)
+	}
+}
@@ -0,0 +1,80 @@
+package progressstream
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestBus_PublishDeliversToSubscriber(t *testing.T) {
+	b := NewBus()
+	events, unsubscribe := b.Subscribe("testRecordingID")
+	defer unsubscribe()
+
+	b.Publish("testRecordingID", Event{Type: EventProgress, PointsProcessed: 10})
+	b.Publish("testRecordingID", Event{Type: EventProgress, PointsProcessed: 20})
+
+	for _, exp := range []int64{10, 20} {
+		select {
+		case ev := <-events:
+			if ev.PointsProcessed != exp {
+				t.Fatalf("unexpected points processed: got %d exp %d", ev.PointsProcessed, exp)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for event")
+		}
+	}
+}
+
+func TestBus_FinishClosesSubscribers(t *testing.T) {
+	b := NewBus()
+	events, unsubscribe := b.Subscribe("testRecordingID")
+	defer unsubscribe()
+
+	b.Finish("testRecordingID", nil)
+
+	select {
+	case ev, ok := <-events:
+		if !ok {
+			t.Fatal("expected a finished event before the channel closes")
+		}
+		if ev.Type != EventFinished {
+			t.Fatalf("unexpected event type: %s", ev.Type)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for finished event")
+	}
+
+	if _, ok := <-events; ok {
+		t.Fatal("expected channel to be closed after Finish")
+	}
+}
+
+func TestBus_FinishWithErrorPublishesErrorEvent(t *testing.T) {
+	b := NewBus()
+	events, unsubscribe := b.Subscribe("testReplayID")
+	defer unsubscribe()
+
+	b.Finish("testReplayID", fmt.Errorf("downstream handler unavailable"))
+
+	ev := <-events
+	if ev.Type != EventError || ev.Error == "" {
+		t.Fatalf("unexpected event: %+v", ev)
+	}
+}
+
+func TestBus_UnsubscribeStopsDelivery(t *testing.T) {
+	b := NewBus()
+	events, unsubscribe := b.Subscribe("testRecordingID")
+	unsubscribe()
+
+	b.Publish("testRecordingID", Event{Type: EventProgress})
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatal("expected no further events after unsubscribe")
+		}
+	default:
+	}
+}
@@ -0,0 +1,62 @@
+package progressstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Handler implements GET /kapacitor/v1/recordings/{id}/events and
+// /kapacitor/v1/replays/{id}/events, streaming a Bus's events for id as
+// Server-Sent Events until the event stream finishes or the client
+// disconnects.
+type Handler struct {
+	Bus *Bus
+}
+
+func NewHandler(bus *Bus) *Handler {
+	return &Handler{Bus: bus}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request, id string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	events, unsubscribe := h.Bus.Subscribe(id)
+	defer unsubscribe()
+
+	ctx := r.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeEvent(w, ev); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeEvent formats ev using the SSE wire format (an "event:" line naming
+// the event type, a "data:" line carrying its JSON body, and a blank line
+// terminator).
+func writeEvent(w http.ResponseWriter, ev Event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data)
+	return err
+}
@@ -0,0 +1,40 @@
+// Package pubsub implements the "pubsub" alert handler kind: it
+// publishes a JSON-encoded AlertData message to a Google Cloud Pub/Sub
+// topic, with id/level/user-templated tags carried as message
+// attributes.
+package pubsub
+
+import (
+	"errors"
+
+	"github.com/influxdata/toml"
+)
+
+const defaultEndpoint = "pubsub.googleapis.com:443"
+
+// Config is the pubsub service section.
+type Config struct {
+	Enabled bool `toml:"enabled" override:"enabled"`
+	// Project is the GCP project ID topics are addressed within.
+	Project string `toml:"project" override:"project"`
+	// Endpoint overrides the default Pub/Sub API host:port, for pointing
+	// at a local emulator or test fake.
+	Endpoint string `toml:"endpoint" override:"endpoint"`
+	// Insecure dials Endpoint without TLS, for the emulator/test fake.
+	Insecure bool          `toml:"insecure" override:"insecure"`
+	Timeout  toml.Duration `toml:"timeout" override:"timeout"`
+}
+
+func NewConfig() Config {
+	return Config{
+		Endpoint: defaultEndpoint,
+		Timeout:  toml.Duration(defaultTimeout),
+	}
+}
+
+func (c Config) Validate() error {
+	if c.Enabled && c.Project == "" {
+		return errors.New("pubsub: must specify project")
+	}
+	return nil
+}
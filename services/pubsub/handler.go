@@ -0,0 +1,65 @@
+package pubsub
+
+import (
+	"bytes"
+	"log"
+	"text/template"
+
+	"github.com/influxdata/kapacitor/alert"
+)
+
+// HandlerConfig is the per-handler configuration for a "pubsub" handler
+// action.
+type HandlerConfig struct {
+	Topic string `mapstructure:"topic"`
+	// AttributeTemplates maps a Pub/Sub message attribute name to a
+	// template executed against the event's AlertData, letting users
+	// carry arbitrary derived tag values alongside the built-in id/level
+	// attributes.
+	AttributeTemplates map[string]string `mapstructure:"attribute-templates"`
+}
+
+func (s *Service) DefaultHandlerConfig() HandlerConfig {
+	return HandlerConfig{}
+}
+
+func (s *Service) Handler(c HandlerConfig, l *log.Logger) alert.Handler {
+	templates := make(map[string]*template.Template, len(c.AttributeTemplates))
+	for name, text := range c.AttributeTemplates {
+		t, err := template.New(name).Parse(text)
+		if err != nil {
+			l.Println("E! invalid pubsub attribute-template for", name, err)
+			continue
+		}
+		templates[name] = t
+	}
+	return &handler{s: s, c: c, attributeTemplates: templates, logger: l}
+}
+
+type handler struct {
+	s                  *Service
+	c                  HandlerConfig
+	attributeTemplates map[string]*template.Template
+	logger             *log.Logger
+}
+
+func (h *handler) Handle(event alert.Event) {
+	data := newAlertData(event)
+
+	attrs := map[string]string{
+		"id":    data.ID,
+		"level": data.Level,
+	}
+	for name, tmpl := range h.attributeTemplates {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			h.logger.Println("E! failed to render pubsub attribute-template for", name, err)
+			continue
+		}
+		attrs[name] = buf.String()
+	}
+
+	if err := h.s.Publish(h.c.Topic, data, attrs); err != nil {
+		h.logger.Println("E! failed to publish alert to pubsub", err)
+	}
+}
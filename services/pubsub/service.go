@@ -0,0 +1,162 @@
+package pubsub
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pubsubpb "google.golang.org/genproto/googleapis/pubsub/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/influxdata/kapacitor/alert"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// AlertData is the message body published to Pub/Sub for each alert
+// event, mirroring kafka.AlertData.
+type AlertData struct {
+	ID      string            `json:"id"`
+	Message string            `json:"message"`
+	Level   string            `json:"level"`
+	Time    time.Time         `json:"time"`
+	Tags    map[string]string `json:"tags,omitempty"`
+}
+
+func newAlertData(event alert.Event) AlertData {
+	var tags map[string]string
+	if event.Data.Result.Series != nil && len(event.Data.Result.Series) > 0 {
+		tags = event.Data.Result.Series[0].Tags
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%v", event.State.Message, tags)))
+	return AlertData{
+		ID:      hex.EncodeToString(sum[:8]),
+		Message: event.State.Message,
+		Level:   string(event.State.Level),
+		Time:    time.Now(),
+		Tags:    tags,
+	}
+}
+
+// Service publishes alert events to Google Cloud Pub/Sub, sharing a
+// single gRPC connection the same way grpcalert.Service does.
+type Service struct {
+	configValue atomic.Value // Config
+
+	mu       sync.Mutex
+	conn     *grpc.ClientConn
+	client   pubsubpb.PublisherClient
+	dialOpts []grpc.DialOption // test-injectable
+
+	logger *log.Logger
+}
+
+func NewService(c Config, l *log.Logger) *Service {
+	s := &Service{logger: l}
+	s.configValue.Store(c)
+	return s
+}
+
+func (s *Service) config() Config {
+	return s.configValue.Load().(Config)
+}
+
+func (s *Service) Open() error {
+	return nil
+}
+
+func (s *Service) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closeLocked()
+}
+
+func (s *Service) closeLocked() error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	s.client = nil
+	return err
+}
+
+func (s *Service) Update(newConfig []interface{}) error {
+	if len(newConfig) != 1 {
+		return fmt.Errorf("pubsub: expected 1 config object, got %d", len(newConfig))
+	}
+	c, ok := newConfig[0].(Config)
+	if !ok {
+		return fmt.Errorf("pubsub: unexpected config object type %T", newConfig[0])
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.configValue.Store(c)
+	return s.closeLocked()
+}
+
+func (s *Service) dialLocked() error {
+	if s.conn != nil {
+		return nil
+	}
+	c := s.config()
+
+	opts := append([]grpc.DialOption{}, s.dialOpts...)
+	if len(opts) == 0 {
+		if c.Insecure {
+			opts = append(opts, grpc.WithInsecure())
+		} else {
+			opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(nil)))
+		}
+	}
+
+	conn, err := grpc.Dial(c.Endpoint, opts...)
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+	s.client = pubsubpb.NewPublisherClient(conn)
+	return nil
+}
+
+// Publish JSON-encodes data and publishes it to topic with attrs as the
+// Pub/Sub message's attributes.
+func (s *Service) Publish(topic string, data AlertData, attrs map[string]string) error {
+	c := s.config()
+	if !c.Enabled {
+		return errors.New("pubsub: service is not enabled")
+	}
+
+	s.mu.Lock()
+	if err := s.dialLocked(); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	client := s.client
+	s.mu.Unlock()
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.Timeout))
+	defer cancel()
+
+	_, err = client.Publish(ctx, &pubsubpb.PublishRequest{
+		Topic: fmt.Sprintf("projects/%s/topics/%s", c.Project, topic),
+		Messages: []*pubsubpb.PubsubMessage{{
+			Data:       body,
+			Attributes: attrs,
+		}},
+	})
+	return err
+}
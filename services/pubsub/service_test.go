@@ -0,0 +1,86 @@
+package pubsub
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"testing"
+
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/kapacitor/services/pubsub/pubsubtest"
+	"google.golang.org/grpc"
+)
+
+func newTestService(t *testing.T, addr string) *Service {
+	t.Helper()
+	c := NewConfig()
+	c.Enabled = true
+	c.Project = "kapacitor-test"
+	c.Endpoint = addr
+
+	s := NewService(c, log.New(ioutil.Discard, "", 0))
+	s.dialOpts = []grpc.DialOption{grpc.WithInsecure()}
+	return s
+}
+
+func testEvent(message string, level alert.Level, tags map[string]string) alert.Event {
+	var event alert.Event
+	event.State.Message = message
+	event.State.Level = level
+	return event
+}
+
+func TestService_Publish_SendsJSONEncodedAlertDataWithAttributes(t *testing.T) {
+	fake, err := pubsubtest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fake.Close()
+
+	s := newTestService(t, fake.Addr)
+	h := s.Handler(HandlerConfig{Topic: "alerts", AttributeTemplates: map[string]string{"host": "{{.Tags.host}}"}}, log.New(ioutil.Discard, "", 0))
+
+	h.Handle(testEvent("cpu high", alert.Critical, nil))
+
+	received := fake.Received()
+	if len(received) != 1 {
+		t.Fatalf("expected 1 published message, got %d", len(received))
+	}
+	msg := received[0]
+	if msg.Topic != "projects/kapacitor-test/topics/alerts" {
+		t.Fatalf("unexpected topic %q", msg.Topic)
+	}
+	if msg.Attributes["level"] == "" {
+		t.Fatalf("expected a level attribute, got %+v", msg.Attributes)
+	}
+	if msg.Attributes["id"] == "" {
+		t.Fatal("expected an id attribute")
+	}
+
+	var data AlertData
+	if err := json.Unmarshal(msg.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Message != "cpu high" {
+		t.Fatalf("unexpected message body %+v", data)
+	}
+}
+
+func TestService_Publish_NotEnabledErrors(t *testing.T) {
+	s := NewService(NewConfig(), log.New(ioutil.Discard, "", 0))
+	if err := s.Publish("alerts", AlertData{}, nil); err == nil {
+		t.Fatal("expected an error when the service is not enabled")
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	c := NewConfig()
+	c.Enabled = true
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected an error without a project configured")
+	}
+	c.Project = "kapacitor-test"
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,34 @@
+package pushbullet
+
+import "github.com/pkg/errors"
+
+const defaultPushbulletURL = "https://api.pushbullet.com/v2/pushes"
+
+type Config struct {
+	// Whether Pushbullet integration is enabled.
+	Enabled bool `toml:"enabled" override:"enabled"`
+	// Pushbullet url
+	URL string `toml:"-"`
+	// Pushbullet access token, generated from a user's account settings.
+	Token string `toml:"token" override:"token,redact"`
+	// DeviceIden is the default device iden pushes are targeted at, when
+	// neither a handler's Device nor Channel is set. Leave empty to push
+	// to all of the account's devices.
+	DeviceIden string `toml:"device-iden" override:"device-iden"`
+	// ChannelTag is the default channel tag pushes are broadcast to, when
+	// a handler doesn't set its own.
+	ChannelTag string `toml:"channel-tag" override:"channel-tag"`
+}
+
+func NewConfig() Config {
+	return Config{
+		URL: defaultPushbulletURL,
+	}
+}
+
+func (c Config) Validate() error {
+	if c.Enabled && c.Token == "" {
+		return errors.New("must specify token")
+	}
+	return nil
+}
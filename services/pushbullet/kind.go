@@ -0,0 +1,28 @@
+package pushbullet
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/kapacitor/services/handlerkind"
+	"github.com/mitchellh/mapstructure"
+)
+
+func init() {
+	handlerkind.Register("pushbullet", buildHandler)
+}
+
+// buildHandler adapts Service.Handler to handlerkind.Builder, decoding
+// options into this package's own HandlerConfig.
+func buildHandler(svc interface{}, options map[string]interface{}, l *log.Logger) (alert.Handler, error) {
+	s, ok := svc.(*Service)
+	if !ok {
+		return nil, fmt.Errorf("pushbullet: expected a *pushbullet.Service, got %T", svc)
+	}
+	var c HandlerConfig
+	if err := mapstructure.Decode(options, &c); err != nil {
+		return nil, fmt.Errorf("pushbullet: decoding handler options: %s", err)
+	}
+	return s.Handler(c, l), nil
+}
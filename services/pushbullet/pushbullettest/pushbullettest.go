@@ -0,0 +1,74 @@
+package pushbullettest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// Server is a fake Pushbullet pushes API that records the pushes it
+// receives, so handler/service tests can assert against them instead of
+// talking to the real api.pushbullet.com.
+type Server struct {
+	mu       sync.Mutex
+	ts       *httptest.Server
+	URL      string
+	requests []Request
+	closed   bool
+}
+
+// Request is a single push recorded by the fake server.
+type Request struct {
+	AccessToken string
+	PostData    PostData
+}
+
+// PostData mirrors the JSON body Pushbullet's pushes API expects.
+type PostData struct {
+	Type       string `json:"type"`
+	Title      string `json:"title"`
+	Body       string `json:"body"`
+	DeviceIden string `json:"device_iden"`
+	ChannelTag string `json:"channel_tag"`
+}
+
+func NewServer() *Server {
+	s := new(Server)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/pushes", func(w http.ResponseWriter, r *http.Request) {
+		var pd PostData
+		json.NewDecoder(r.Body).Decode(&pd)
+
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{
+			AccessToken: r.Header.Get("Access-Token"),
+			PostData:    pd,
+		})
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"active": true,
+			"iden":   "push-id",
+		})
+	})
+	ts := httptest.NewServer(mux)
+	s.ts = ts
+	s.URL = ts.URL
+	return s
+}
+
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func (s *Server) Close() {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.ts.Close()
+}
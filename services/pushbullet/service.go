@@ -0,0 +1,219 @@
+package pushbullet
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/influxdata/kapacitor/alert"
+)
+
+type Service struct {
+	configValue atomic.Value
+	logger      *log.Logger
+}
+
+func NewService(c Config, l *log.Logger) *Service {
+	s := &Service{
+		logger: l,
+	}
+	s.configValue.Store(c)
+	return s
+}
+
+func (s *Service) Open() error {
+	return nil
+}
+
+func (s *Service) Close() error {
+	return nil
+}
+
+func (s *Service) Update(newConfig []interface{}) error {
+	if l := len(newConfig); l != 1 {
+		return fmt.Errorf("expected only one new config object, got %d", l)
+	}
+	if c, ok := newConfig[0].(Config); !ok {
+		return fmt.Errorf("expected config object to be of type %T, got %T", c, newConfig[0])
+	} else {
+		s.configValue.Store(c)
+	}
+	return nil
+}
+
+func (s *Service) config() Config {
+	return s.configValue.Load().(Config)
+}
+
+type testOptions struct {
+	Message    string      `json:"message"`
+	Level      alert.Level `json:"level"`
+	DeviceIden string      `json:"device-iden"`
+	ChannelTag string      `json:"channel-tag"`
+}
+
+func (s *Service) TestOptions() interface{} {
+	c := s.config()
+	return &testOptions{
+		Message:    "test pushbullet message",
+		Level:      alert.Critical,
+		DeviceIden: c.DeviceIden,
+		ChannelTag: c.ChannelTag,
+	}
+}
+
+func (s *Service) Test(options interface{}) error {
+	o, ok := options.(*testOptions)
+	if !ok {
+		return fmt.Errorf("unexpected options type %t", options)
+	}
+
+	return s.Alert(AlertData{
+		Message:    o.Message,
+		Level:      o.Level,
+		DeviceIden: o.DeviceIden,
+		ChannelTag: o.ChannelTag,
+	})
+}
+
+// AlertData holds the parameters needed to send a single Pushbullet push.
+type AlertData struct {
+	Message    string
+	Level      alert.Level
+	DeviceIden string
+	ChannelTag string
+}
+
+// levelPrefix returns a short severity tag prepended to the push title,
+// since Pushbullet itself has no concept of notification priority.
+func levelPrefix(level alert.Level) string {
+	switch level {
+	case alert.OK:
+		return "[OK] "
+	case alert.Info:
+		return "[Info] "
+	case alert.Warning:
+		return "[Warning] "
+	case alert.Critical:
+		return "[Critical] "
+	}
+	return ""
+}
+
+type pushRequest struct {
+	Type       string `json:"type"`
+	Title      string `json:"title"`
+	Body       string `json:"body"`
+	DeviceIden string `json:"device_iden,omitempty"`
+	ChannelTag string `json:"channel_tag,omitempty"`
+}
+
+type pushResponse struct {
+	Active bool   `json:"active"`
+	Iden   string `json:"iden"`
+	Error  *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func (s *Service) Alert(ad AlertData) error {
+	c := s.config()
+	if !c.Enabled {
+		return errors.New("service is not enabled")
+	}
+
+	deviceIden := ad.DeviceIden
+	if deviceIden == "" && ad.ChannelTag == "" {
+		deviceIden = c.DeviceIden
+	}
+	channelTag := ad.ChannelTag
+	if channelTag == "" {
+		channelTag = c.ChannelTag
+	}
+
+	req := pushRequest{
+		Type:       "note",
+		Title:      levelPrefix(ad.Level) + "kapacitor",
+		Body:       ad.Message,
+		DeviceIden: deviceIden,
+		ChannelTag: channelTag,
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Access-Token", c.Token)
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var r pushResponse
+		if err := json.Unmarshal(respBody, &r); err == nil && r.Error != nil {
+			return errors.New(r.Error.Message)
+		}
+		return fmt.Errorf("failed to understand Pushbullet response. code: %d content: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// HandlerConfig is the per-alert-handler configuration for the
+// pushbullet node in a TICKscript.
+type HandlerConfig struct {
+	// DeviceIden targets a single device, overriding the service's
+	// default. Leave empty, along with Channel, to push to all devices.
+	DeviceIden string `mapstructure:"device-iden"`
+	// ChannelTag broadcasts the push to a Pushbullet channel, overriding
+	// the service's default. When both ChannelTag and DeviceIden are set,
+	// both are sent; the service does not reject the combination.
+	ChannelTag string `mapstructure:"channel-tag"`
+}
+
+type handler struct {
+	s      *Service
+	c      HandlerConfig
+	logger *log.Logger
+}
+
+func (s *Service) DefaultHandlerConfig() HandlerConfig {
+	return HandlerConfig{}
+}
+
+func (s *Service) Handler(c HandlerConfig, l *log.Logger) alert.Handler {
+	return &handler{
+		s:      s,
+		c:      c,
+		logger: l,
+	}
+}
+
+func (h *handler) Handle(event alert.Event) {
+	ad := AlertData{
+		Message:    event.State.Message,
+		Level:      event.State.Level,
+		DeviceIden: h.c.DeviceIden,
+		ChannelTag: h.c.ChannelTag,
+	}
+	if err := h.s.Alert(ad); err != nil {
+		h.logger.Println("E! failed to send event to Pushbullet", err)
+	}
+}
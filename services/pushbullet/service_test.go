@@ -0,0 +1,151 @@
+package pushbullet
+
+import (
+	"io/ioutil"
+	"log"
+	"testing"
+
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/kapacitor/services/pushbullet/pushbullettest"
+)
+
+func NewTestService(c Config) *Service {
+	return NewService(c, log.New(ioutil.Discard, "", 0))
+}
+
+func TestService_Alert_SendsNoteWithLevelPrefix(t *testing.T) {
+	ts := pushbullettest.NewServer()
+	defer ts.Close()
+
+	c := Config{Enabled: true, Token: "token", URL: ts.URL + "/v2/pushes"}
+	s := NewTestService(c)
+
+	if err := s.Alert(AlertData{Message: "disk full", Level: alert.Critical}); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := ts.Requests()
+	if got, exp := len(requests), 1; got != exp {
+		t.Fatalf("unexpected number of requests got %d exp %d", got, exp)
+	}
+	req := requests[0]
+	if req.AccessToken != "token" {
+		t.Errorf("unexpected access token got %s exp %s", req.AccessToken, "token")
+	}
+	if req.PostData.Type != "note" {
+		t.Errorf("unexpected push type got %s exp note", req.PostData.Type)
+	}
+	if req.PostData.Body != "disk full" {
+		t.Errorf("unexpected body got %s exp %s", req.PostData.Body, "disk full")
+	}
+	if exp := "[Critical] kapacitor"; req.PostData.Title != exp {
+		t.Errorf("unexpected title got %s exp %s", req.PostData.Title, exp)
+	}
+}
+
+func TestService_Alert_DeviceAndChannelOverrideServiceDefaults(t *testing.T) {
+	ts := pushbullettest.NewServer()
+	defer ts.Close()
+
+	c := Config{
+		Enabled:    true,
+		Token:      "token",
+		URL:        ts.URL + "/v2/pushes",
+		DeviceIden: "default-device",
+		ChannelTag: "default-channel",
+	}
+	s := NewTestService(c)
+
+	if err := s.Alert(AlertData{
+		Message:    "disk full",
+		DeviceIden: "device-1",
+		ChannelTag: "channel-1",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	pd := ts.Requests()[0].PostData
+	if pd.DeviceIden != "device-1" {
+		t.Errorf("unexpected device iden got %s exp %s", pd.DeviceIden, "device-1")
+	}
+	if pd.ChannelTag != "channel-1" {
+		t.Errorf("unexpected channel tag got %s exp %s", pd.ChannelTag, "channel-1")
+	}
+}
+
+func TestService_Alert_FallsBackToServiceDefaults(t *testing.T) {
+	ts := pushbullettest.NewServer()
+	defer ts.Close()
+
+	c := Config{
+		Enabled:    true,
+		Token:      "token",
+		URL:        ts.URL + "/v2/pushes",
+		DeviceIden: "default-device",
+		ChannelTag: "default-channel",
+	}
+	s := NewTestService(c)
+
+	if err := s.Alert(AlertData{Message: "disk full"}); err != nil {
+		t.Fatal(err)
+	}
+
+	pd := ts.Requests()[0].PostData
+	if pd.DeviceIden != "default-device" {
+		t.Errorf("unexpected device iden got %s exp %s", pd.DeviceIden, "default-device")
+	}
+	if pd.ChannelTag != "default-channel" {
+		t.Errorf("unexpected channel tag got %s exp %s", pd.ChannelTag, "default-channel")
+	}
+}
+
+func TestService_Alert_NotEnabled(t *testing.T) {
+	s := NewTestService(Config{Enabled: false})
+	if err := s.Alert(AlertData{Message: "disk full"}); err == nil {
+		t.Fatal("expected error when service is not enabled")
+	}
+}
+
+func TestHandler_Handle_UsesHandlerConfigOverrides(t *testing.T) {
+	ts := pushbullettest.NewServer()
+	defer ts.Close()
+
+	c := Config{Enabled: true, Token: "token", URL: ts.URL + "/v2/pushes"}
+	s := NewTestService(c)
+
+	h := s.Handler(HandlerConfig{DeviceIden: "device-1", ChannelTag: "channel-1"}, log.New(ioutil.Discard, "", 0))
+
+	var event alert.Event
+	event.State.Level = alert.Warning
+	event.State.Message = "disk filling up"
+	h.Handle(event)
+
+	pd := ts.Requests()[0].PostData
+	if pd.DeviceIden != "device-1" || pd.ChannelTag != "channel-1" {
+		t.Fatalf("unexpected handler overrides in post data: %+v", pd)
+	}
+	if exp := "[Warning] kapacitor"; pd.Title != exp {
+		t.Errorf("unexpected title got %s exp %s", pd.Title, exp)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	cases := []struct {
+		name    string
+		c       Config
+		wantErr bool
+	}{
+		{"disabled is always valid", Config{Enabled: false}, false},
+		{"enabled without token", Config{Enabled: true}, true},
+		{"enabled with token", Config{Enabled: true, Token: "token"}, false},
+	}
+	for _, tc := range cases {
+		err := tc.c.Validate()
+		if tc.wantErr && err == nil {
+			t.Errorf("%s: expected an error, got nil", tc.name)
+		}
+		if !tc.wantErr && err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+	}
+}
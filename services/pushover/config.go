@@ -0,0 +1,69 @@
+package pushover
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const defaultPushoverURL = "https://api.pushover.net/1/messages.json"
+
+// RetryConfig controls the bounded exponential backoff used when delivering
+// a message fails with a transient (5xx or network) error. A MaxRetries of
+// zero disables retries entirely, matching the client's original behavior.
+type RetryConfig struct {
+	// Maximum number of retry attempts after the initial request.
+	MaxRetries int `toml:"max_retries" override:"max_retries"`
+	// Backoff duration before the first retry.
+	InitialBackoff time.Duration `toml:"initial_backoff" override:"initial_backoff"`
+	// Upper bound on the backoff duration between retries.
+	MaxBackoff time.Duration `toml:"max_backoff" override:"max_backoff"`
+	// Maximum random jitter added to each backoff.
+	Jitter time.Duration `toml:"jitter" override:"jitter"`
+}
+
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxRetries:     0,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Jitter:         100 * time.Millisecond,
+	}
+}
+
+type Config struct {
+	// Whether Pushover integration is enabled.
+	Enabled bool `toml:"enabled" override:"enabled"`
+	// Pushover url
+	URL string `toml:"-"`
+	// Pushover application token, this is kapacitor's token
+	Token string `toml:"token" override:"token,redact"`
+	// Pushover User key
+	User string `toml:"user-key" override:"user-key"`
+	// Retry is the backoff policy used for transient delivery failures.
+	Retry RetryConfig `toml:"retry" override:"retry"`
+}
+
+func NewConfig() Config {
+	return Config{
+		URL:   defaultPushoverURL,
+		Retry: DefaultRetryConfig(),
+	}
+}
+
+func (c Config) Validate() error {
+	if c.Enabled && c.Token == "" {
+		return errors.New("must specify token")
+	}
+	if c.Enabled && c.User == "" {
+		return errors.New("must specify user")
+	}
+	if _, err := url.Parse(c.URL); err != nil {
+		return errors.Errorf("invalid url: %s", err)
+	}
+	if c.Retry.MaxRetries < 0 {
+		return errors.New("retry.max_retries must be >= 0")
+	}
+	return nil
+}
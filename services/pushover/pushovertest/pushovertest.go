@@ -1,33 +1,109 @@
 package pushovertest
 
 import (
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strconv"
+	"strings"
 	"sync"
 )
 
+// maxAttachmentMemory bounds how much of a multipart attachment is buffered
+// in memory before http.Request.ParseMultipartForm spills to a temp file.
+const maxAttachmentMemory = 10 << 20 // 10MB
+
+// receiptCounter generates unique, predictable receipt tokens for emergency
+// priority messages so tests can assert against them.
+var receiptCounter uint64
+
 type Server struct {
-	mu       sync.Mutex
-	ts       *httptest.Server
-	URL      string
-	requests []Request
-	closed   bool
+	mu         sync.Mutex
+	ts         *httptest.Server
+	URL        string
+	requests   []Request
+	receipts   map[string]receiptStatus
+	closed     bool
+	failCount  int
+	failStatus int
+}
+
+type receiptStatus struct {
+	acknowledged bool
+	expired      bool
 }
 
 func NewServer() *Server {
 	s := new(Server)
-	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	s.receipts = make(map[string]receiptStatus)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/1/receipts/", func(w http.ResponseWriter, r *http.Request) {
+		receipt := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/1/receipts/"), ".json")
+		s.mu.Lock()
+		rs := s.receipts[receipt]
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		resp := map[string]interface{}{"status": 1}
+		if rs.acknowledged {
+			resp["acknowledged"] = 1
+		}
+		if rs.expired {
+			resp["expired"] = 1
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc("/1/messages.json", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		if s.failCount > 0 {
+			s.failCount--
+			status := s.failStatus
+			s.mu.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status": 0,
+				"errors": []string{"simulated failure"},
+			})
+			return
+		}
+		s.mu.Unlock()
+
 		fr := Request{}
-		data, _ := ioutil.ReadAll(r.Body)
-		v, _ := url.ParseQuery(string(data))
-		fr.PostData, _ = NewPostData(v)
+		if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil && strings.HasPrefix(mediaType, "multipart/") {
+			fr.PostData, _ = newPostDataFromMultipart(r)
+		} else {
+			data, _ := ioutil.ReadAll(r.Body)
+			v, _ := url.ParseQuery(string(data))
+			fr.PostData, _ = NewPostData(v)
+		}
 		s.mu.Lock()
 		s.requests = append(s.requests, fr)
 		s.mu.Unlock()
-	}))
+
+		if fr.PostData.Priority == 2 {
+			receiptCounter++
+			receipt := fmt.Sprintf("r%d", receiptCounter)
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status":  1,
+				"request": "request-id",
+				"receipt": receipt,
+			})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":  1,
+			"request": "request-id",
+		})
+	})
+	ts := httptest.NewServer(mux)
 	s.ts = ts
 	s.URL = ts.URL
 	return s
@@ -38,6 +114,32 @@ func (s *Server) Requests() []Request {
 	defer s.mu.Unlock()
 	return s.requests
 }
+
+// SetReceiptAcknowledged makes the server report the given receipt as
+// acknowledged on subsequent polls of /1/receipts/{receipt}.json.
+func (s *Server) SetReceiptAcknowledged(receipt string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.receipts[receipt] = receiptStatus{acknowledged: true}
+}
+
+// SetReceiptExpired makes the server report the given receipt as expired on
+// subsequent polls of /1/receipts/{receipt}.json.
+func (s *Server) SetReceiptExpired(receipt string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.receipts[receipt] = receiptStatus{expired: true}
+}
+
+// FailNext makes the next n requests to /1/messages.json fail with the
+// given HTTP status code instead of being recorded, so tests can exercise
+// retry behavior.
+func (s *Server) FailNext(n int, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failCount = n
+	s.failStatus = status
+}
 func (s *Server) Close() {
 	if s.closed {
 		return
@@ -61,6 +163,46 @@ type PostData struct {
 	Sound     string
 	Timestamp string
 	Priority  int
+	// Retry, Expire and Callback are only set by the Pushover API when
+	// Priority is emergency (2).
+	Retry    int
+	Expire   int
+	Callback string
+
+	// Attachment and AttachmentName are only set on requests sent as
+	// multipart/form-data with an inline image.
+	Attachment     []byte
+	AttachmentName string
+
+	// HTML and Monospace reflect the html/monospace formatting flags, they
+	// are mutually exclusive per the Pushover API.
+	HTML      bool
+	Monospace bool
+}
+
+// newPostDataFromMultipart parses a multipart/form-data request, which
+// Pushover requires for messages that include an attachment.
+func newPostDataFromMultipart(r *http.Request) (PostData, error) {
+	if err := r.ParseMultipartForm(maxAttachmentMemory); err != nil {
+		return PostData{}, err
+	}
+	p, err := NewPostData(url.Values(r.MultipartForm.Value))
+	if err != nil {
+		return p, err
+	}
+
+	file, header, err := r.FormFile("attachment")
+	if err == nil {
+		defer file.Close()
+		data, err := ioutil.ReadAll(file)
+		if err != nil {
+			return p, err
+		}
+		p.Attachment = data
+		p.AttachmentName = header.Filename
+	}
+
+	return p, nil
 }
 
 func NewPostData(v url.Values) (PostData, error) {
@@ -81,5 +223,20 @@ func NewPostData(v url.Values) (PostData, error) {
 	}
 	p.Priority = priority
 
+	if priority == 2 {
+		p.Retry, err = strconv.Atoi(v.Get("retry"))
+		if err != nil {
+			return p, err
+		}
+		p.Expire, err = strconv.Atoi(v.Get("expire"))
+		if err != nil {
+			return p, err
+		}
+		p.Callback = v.Get("callback")
+	}
+
+	p.HTML = v.Get("html") == "1"
+	p.Monospace = v.Get("monospace") == "1"
+
 	return p, nil
 }
@@ -5,30 +5,83 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
+	"math/rand"
+	"mime/multipart"
 	"net/http"
 	"net/url"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/kapacitor/alert"
 )
 
+// receiptPollInterval is how often the service polls Pushover's receipts
+// endpoint for an emergency priority notification's acknowledgement status.
+const receiptPollInterval = 5 * time.Second
+
+// defaultRetry and defaultExpire are the values used for emergency priority
+// (level critical) notifications when a handler does not specify its own,
+// they fall within the bounds documented at https://pushover.net/api#priority.
+//
+// minRetry and maxExpire are Pushover's own documented bounds: retry may
+// not be set below 30 seconds, and expire may not exceed 10800 seconds
+// (3 hours), so out-of-range values are clamped rather than rejected.
+const (
+	defaultRetry  = 60
+	defaultExpire = 3600
+
+	minRetry  = 30
+	maxExpire = 10800
+)
+
 type Service struct {
 	configValue atomic.Value
 	logger      *log.Logger
+
+	mu       sync.Mutex
+	receipts map[string]receiptState
+}
+
+// receiptState is the last known acknowledgement state of an emergency
+// priority notification's receipt, kept in memory so a downstream
+// alert.Handler can check it before re-alerting on the same event.
+type receiptState struct {
+	acknowledged bool
+	expired      bool
+	calledBack   bool
 }
 
 func NewService(c Config, l *log.Logger) *Service {
 	s := &Service{
-		logger: l,
+		logger:   l,
+		receipts: make(map[string]receiptState),
 	}
 	s.configValue.Store(c)
 	return s
 }
 
+// ReceiptState returns the last known acknowledgement state polled for
+// receipt, and whether any state has been observed for it yet.
+func (s *Service) ReceiptState(receipt string) (acknowledged, expired, calledBack, found bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rs, ok := s.receipts[receipt]
+	return rs.acknowledged, rs.expired, rs.calledBack, ok
+}
+
+func (s *Service) setReceiptState(receipt string, rs receiptState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.receipts[receipt] = rs
+}
+
 func (s *Service) Open() error {
 	return nil
 }
@@ -59,6 +112,9 @@ type testOptions struct {
 	Sound     string      `json:"sound"`
 	Timestamp bool        `json:"timestamp"`
 	Level     alert.Level `json:"level"`
+	Retry     int         `json:"retry"`
+	Expire    int         `json:"expire"`
+	Callback  string      `json:"callback"`
 }
 
 func (s *Service) TestOptions() interface{} {
@@ -67,6 +123,8 @@ func (s *Service) TestOptions() interface{} {
 		User:    c.User,
 		Message: "test pushover message",
 		Level:   alert.Critical,
+		Retry:   defaultRetry,
+		Expire:  defaultExpire,
 	}
 }
 
@@ -76,52 +134,209 @@ func (s *Service) Test(options interface{}) error {
 		return fmt.Errorf("unexpected options type %t", options)
 	}
 
-	return s.Alert(
-		o.User,
-		o.Message,
-		o.Device,
-		o.Title,
-		o.URL,
-		o.URLTitle,
-		o.Sound,
-		o.Timestamp,
-		o.Level,
-	)
+	return s.Alert(AlertData{
+		User:      o.User,
+		Message:   o.Message,
+		Device:    o.Device,
+		Title:     o.Title,
+		URL:       o.URL,
+		URLTitle:  o.URLTitle,
+		Sound:     o.Sound,
+		Timestamp: o.Timestamp,
+		Level:     o.Level,
+		Retry:     o.Retry,
+		Expire:    o.Expire,
+		Callback:  o.Callback,
+	})
 }
 
 func (s *Service) config() Config {
 	return s.configValue.Load().(Config)
 }
 
-func (s *Service) Alert(user, message, device, title, URL, URLTitle, sound string, timestamp bool, level alert.Level) error {
-	url, post, err := s.preparePost(user, message, device, title, URL, URLTitle, sound, timestamp, level)
+// AlertData holds all the parameters needed to send a single Pushover
+// notification, it exists so that Alert can grow new options (as Pushover's
+// API does) without accumulating more positional parameters.
+type AlertData struct {
+	User      string
+	Message   string
+	Device    string
+	Title     string
+	URL       string
+	URLTitle  string
+	Sound     string
+	Timestamp bool
+	Level     alert.Level
+
+	// Retry and Expire are only meaningful for emergency priority (Level ==
+	// alert.Critical) notifications, they control how often Pushover retries
+	// delivery and for how long, per https://pushover.net/api#priority.
+	Retry    int
+	Expire   int
+	Callback string
+
+	// Attachment, when non-empty, is sent inline as a multipart/form-data
+	// upload. AttachmentName is the filename reported to Pushover.
+	Attachment     []byte
+	AttachmentName string
+
+	// HTML and Monospace select an alternate rendering of Message on the
+	// device, they are mutually exclusive.
+	HTML      bool
+	Monospace bool
+
+	// PriorityOverride, when non-nil, bypasses the Level-derived priority
+	// (see priority) entirely, so e.g. a Warning level event can still be
+	// sent at emergency (2) priority for a specific critical service.
+	PriorityOverride *int
+}
+
+type messageResponse struct {
+	Status  int    `json:"status"`
+	Request string `json:"request"`
+	Receipt string `json:"receipt"`
+	Error   string `json:"error"`
+}
+
+func (s *Service) Alert(ad AlertData) error {
+	postURL, p, err := s.preparePost(ad)
 	if err != nil {
 		return err
 	}
 
-	resp, err := http.PostForm(url, post)
+	resp, err := s.postWithRetry(func() (*http.Response, error) {
+		if len(p.Attachment) > 0 {
+			return postMultipart(postURL, p)
+		}
+		return http.PostForm(postURL, p.Values())
+	})
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	r := &messageResponse{}
+	b := bytes.NewReader(body)
+	dec := json.NewDecoder(b)
+	dec.Decode(r)
+
 	if resp.StatusCode != http.StatusOK {
-		body, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return err
-		}
-		type response struct {
-			Error string `json:"error"`
+		if r.Error == "" {
+			r.Error = fmt.Sprintf("failed to understand Pushover response. code: %d content: %s", resp.StatusCode, string(body))
 		}
-		r := &response{Error: fmt.Sprintf("failed to understand Slack response. code: %d content: %s", resp.StatusCode, string(body))}
-		b := bytes.NewReader(body)
-		dec := json.NewDecoder(b)
-		dec.Decode(r)
 		return errors.New(r.Error)
 	}
 
+	if ad.Level == alert.Critical && r.Receipt != "" {
+		go s.pollReceipt(r.Receipt)
+	}
+
 	return nil
 }
 
+// postWithRetry calls do, retrying with bounded exponential backoff and
+// jitter on network errors and 5xx responses, per the Retry config. 4xx
+// responses are returned immediately since Pushover documents them as
+// non-retryable (bad token/user, malformed request, etc.).
+func (s *Service) postWithRetry(do func() (*http.Response, error)) (*http.Response, error) {
+	c := s.config()
+	backoff := c.Retry.InitialBackoff
+
+	var resp *http.Response
+	var err error
+	for attempt := 0; ; attempt++ {
+		resp, err = do()
+		if err == nil && resp.StatusCode < 500 {
+			// Success or a non-retryable client error (4xx), return as-is.
+			return resp, nil
+		}
+
+		if attempt >= c.Retry.MaxRetries {
+			if err != nil {
+				return nil, err
+			}
+			return resp, nil
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		wait := backoff
+		if c.Retry.Jitter > 0 {
+			wait += time.Duration(rand.Int63n(int64(c.Retry.Jitter)))
+		}
+		time.Sleep(wait)
+
+		backoff *= 2
+		if backoff > c.Retry.MaxBackoff {
+			backoff = c.Retry.MaxBackoff
+		}
+	}
+}
+
+// pollReceipt polls Pushover's receipts endpoint for an emergency priority
+// message until it is acknowledged, expires, is called back, or the poll
+// itself fails, recording the outcome in s.receipts so a downstream
+// alert.Handler can check ReceiptState, and logging it since there is no
+// caller left to return the result to.
+func (s *Service) pollReceipt(receipt string) {
+	c := s.config()
+	ticker := time.NewTicker(receiptPollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		acknowledged, expired, calledBack, err := s.checkReceipt(c.URL, receipt)
+		if err != nil {
+			s.logger.Println("E! failed to poll Pushover receipt", receipt, err)
+			return
+		}
+		if acknowledged || expired || calledBack {
+			s.setReceiptState(receipt, receiptState{acknowledged: acknowledged, expired: expired, calledBack: calledBack})
+		}
+		if acknowledged {
+			s.logger.Println("I! Pushover emergency notification acknowledged, receipt", receipt)
+			return
+		}
+		if calledBack {
+			s.logger.Println("I! Pushover emergency notification called back, receipt", receipt)
+			return
+		}
+		if expired {
+			s.logger.Println("I! Pushover emergency notification expired without acknowledgement, receipt", receipt)
+			return
+		}
+	}
+}
+
+func (s *Service) checkReceipt(baseURL, receipt string) (acknowledged, expired, calledBack bool, err error) {
+	receiptURL := strings.TrimSuffix(baseURL, "/1/messages.json") + "/1/receipts/" + receipt + ".json"
+	resp, err := http.Get(receiptURL)
+	if err != nil {
+		return false, false, false, err
+	}
+	defer resp.Body.Close()
+
+	type receiptResponse struct {
+		Status       int `json:"status"`
+		Acknowledged int `json:"acknowledged"`
+		Expired      int `json:"expired"`
+		Cancelled    int `json:"cancelled"`
+		CalledBack   int `json:"called_back"`
+	}
+	r := &receiptResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(r); err != nil {
+		return false, false, false, err
+	}
+
+	return r.Acknowledged == 1, r.Expired == 1 || r.Cancelled == 1, r.CalledBack == 1, nil
+}
+
 // priority returns the pushover priority as defined by the Pushover API
 // documentation https://pushover.net/api
 func priority(level alert.Level) int {
@@ -154,6 +369,17 @@ type postData struct {
 	Priority  int
 	Timestamp *time.Time
 	Sound     string
+
+	// Retry, Expire and Callback are only sent when Priority is emergency (2).
+	Retry    int
+	Expire   int
+	Callback string
+
+	Attachment     []byte
+	AttachmentName string
+
+	HTML      bool
+	Monospace bool
 }
 
 func (p *postData) Values() url.Values {
@@ -188,41 +414,117 @@ func (p *postData) Values() url.Values {
 		v.Set("timestamp", p.Timestamp.String())
 	}
 
+	if p.Priority == 2 {
+		v.Set("retry", strconv.Itoa(p.Retry))
+		v.Set("expire", strconv.Itoa(p.Expire))
+		if p.Callback != "" {
+			v.Set("callback", p.Callback)
+		}
+	}
+
+	if p.HTML {
+		v.Set("html", "1")
+	} else if p.Monospace {
+		v.Set("monospace", "1")
+	}
+
 	return v
 
 }
 
-func (s *Service) preparePost(user, message, device, title, URL, URLTitle, sound string, timestamp bool, level alert.Level) (string, url.Values, error) {
+func (s *Service) preparePost(ad AlertData) (string, *postData, error) {
 	c := s.config()
 
 	if !c.Enabled {
 		return "", nil, errors.New("service is not enabled")
 	}
 
+	if ad.HTML && ad.Monospace {
+		return "", nil, errors.New("html and monospace are mutually exclusive")
+	}
+
 	p := postData{
 		Token:   c.Token,
 		User:    c.User,
-		Message: message,
+		Message: ad.Message,
 	}
 
-	if user != "" {
-		p.User = user
+	if ad.User != "" {
+		p.User = ad.User
 	}
 
-	p.Device = device
-	p.Title = title
-	p.URL = URL
-	p.URLTitle = URLTitle
-	p.Sound = sound
+	p.Device = ad.Device
+	p.Title = ad.Title
+	p.URL = ad.URL
+	p.URLTitle = ad.URLTitle
+	p.Sound = ad.Sound
 
-	if timestamp {
+	if ad.Timestamp {
 		now := time.Now()
 		p.Timestamp = &now
 	}
 
-	p.Priority = priority(level)
+	p.Priority = priority(ad.Level)
+	if ad.PriorityOverride != nil {
+		p.Priority = *ad.PriorityOverride
+	}
+
+	if p.Priority == 2 {
+		p.Retry = ad.Retry
+		if p.Retry <= 0 {
+			p.Retry = defaultRetry
+		}
+		if p.Retry < minRetry {
+			p.Retry = minRetry
+		}
+		p.Expire = ad.Expire
+		if p.Expire <= 0 {
+			p.Expire = defaultExpire
+		}
+		if p.Expire > maxExpire {
+			p.Expire = maxExpire
+		}
+		p.Callback = ad.Callback
+	}
+
+	p.Attachment = ad.Attachment
+	p.AttachmentName = ad.AttachmentName
+	if p.AttachmentName == "" {
+		p.AttachmentName = "attachment"
+	}
+
+	p.HTML = ad.HTML
+	p.Monospace = ad.Monospace
 
-	return c.URL, p.Values(), nil
+	return c.URL, &p, nil
+}
+
+// postMultipart sends p as a multipart/form-data request, required by
+// Pushover whenever a message includes an attachment.
+func postMultipart(postURL string, p *postData) (*http.Response, error) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+
+	for k, values := range p.Values() {
+		for _, v := range values {
+			if err := w.WriteField(k, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	fw, err := w.CreateFormFile("attachment", p.AttachmentName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fw.Write(p.Attachment); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return http.Post(postURL, w.FormDataContentType(), body)
 }
 
 type HandlerConfig struct {
@@ -253,6 +555,93 @@ type HandlerConfig struct {
 	// A Unix timestamp of your message's date and time to display to the user,
 	// rather than the time your message is received by the Pushover API
 	Timestamp bool `mapstructure:"timestamp"`
+
+	// How often, in seconds, Pushover will resend an emergency priority
+	// notification until it is acknowledged. Only applies when the event's
+	// level is critical. Defaults to 60 when unset, clamped up to 30 (the
+	// minimum Pushover allows).
+	Retry int `mapstructure:"retry"`
+
+	// How many seconds an emergency priority notification will continue to
+	// be retried before Pushover gives up. Only applies when the event's
+	// level is critical. Defaults to 3600 when unset, clamped down to
+	// 10800 (the maximum Pushover allows).
+	Expire int `mapstructure:"expire"`
+
+	// A publicly accessible URL that Pushover will request when the user
+	// acknowledges an emergency priority notification.
+	Callback string `mapstructure:"callback"`
+
+	// A filesystem path or a URL of a file to attach to the message, e.g.
+	// an image produced by a template or rendered by Grafana. Sent to
+	// Pushover as a multipart/form-data upload. Pushover limits
+	// attachments to 2.5MB.
+	Attachment string `mapstructure:"attachment"`
+
+	// Render the message as HTML. Mutually exclusive with Monospace.
+	HTML bool `mapstructure:"html"`
+
+	// Render the message in a monospace font. Mutually exclusive with HTML.
+	Monospace bool `mapstructure:"monospace"`
+
+	// PriorityOverride, when set, bypasses the alert level's default
+	// Pushover priority mapping (see priority) for every event this
+	// handler processes. A pushover_priority tag on a specific event
+	// overrides this in turn, see Handle.
+	PriorityOverride *int `mapstructure:"priority-override"`
+}
+
+// Validate returns an error if the handler config requests mutually
+// exclusive Pushover message formatting options.
+func (c HandlerConfig) Validate() error {
+	if c.HTML && c.Monospace {
+		return errors.New("pushover handler cannot set both html and monospace")
+	}
+	return nil
+}
+
+// maxAttachmentSize is Pushover's documented attachment size limit.
+const maxAttachmentSize = int64(2.5 * 1024 * 1024)
+
+// readAttachment loads path's contents, either by fetching it over HTTP
+// when it looks like a URL or by reading it from the local filesystem
+// otherwise, and returns the data along with the filename reported to
+// Pushover.
+func readAttachment(path string) ([]byte, string, error) {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		resp, err := http.Get(path)
+		if err != nil {
+			return nil, "", err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, "", fmt.Errorf("failed to fetch attachment %s: %d", path, resp.StatusCode)
+		}
+
+		data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxAttachmentSize+1))
+		if err != nil {
+			return nil, "", err
+		}
+		if int64(len(data)) > maxAttachmentSize {
+			return nil, "", fmt.Errorf("attachment %s exceeds pushover's %d byte limit", path, maxAttachmentSize)
+		}
+
+		u, err := url.Parse(path)
+		if err != nil {
+			return data, "attachment", nil
+		}
+		return data, filepath.Base(u.Path), nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(data)) > maxAttachmentSize {
+		return nil, "", fmt.Errorf("attachment %s exceeds pushover's %d byte limit", path, maxAttachmentSize)
+	}
+	return data, filepath.Base(path), nil
 }
 
 type handler struct {
@@ -273,18 +662,77 @@ func (s *Service) Handler(c HandlerConfig, l *log.Logger) alert.Handler {
 	}
 }
 
+// eventTags returns the tags of event's first result series, the only
+// part of alert.Event's Data this tree's handlers read tag values from
+// (see handlerchain.Handle and notifygroup.Handle for the same pattern).
+func eventTags(event alert.Event) map[string]string {
+	if len(event.Data.Result.Series) > 0 {
+		return event.Data.Result.Series[0].Tags
+	}
+	return nil
+}
+
+// Handle implements alert.Handler. User, Device, Sound and URL can be
+// overridden per event by setting a pushover_user, pushover_device,
+// pushover_sound or pushover_url tag, e.g. to route an alert to the
+// engineer named by the tag rather than declaring a separate handler
+// per person. A pushover_priority tag, parsed as an int, overrides the
+// Pushover priority for that event the same way HandlerConfig's own
+// PriorityOverride does. Precedence for every override is: the event's
+// tags win over HandlerConfig, which wins over the service's config
+// defaults.
 func (h *handler) Handle(event alert.Event) {
-	if err := h.s.Alert(
-		h.c.User,
-		event.State.Message,
-		h.c.Device,
-		h.c.Title,
-		h.c.URL,
-		h.c.URLTitle,
-		h.c.Sound,
-		h.c.Timestamp,
-		event.State.Level,
-	); err != nil {
+	tags := eventTags(event)
+
+	ad := AlertData{
+		User:             h.c.User,
+		Message:          event.State.Message,
+		Device:           h.c.Device,
+		Title:            h.c.Title,
+		URL:              h.c.URL,
+		URLTitle:         h.c.URLTitle,
+		Sound:            h.c.Sound,
+		Timestamp:        h.c.Timestamp,
+		Level:            event.State.Level,
+		Retry:            h.c.Retry,
+		Expire:           h.c.Expire,
+		Callback:         h.c.Callback,
+		HTML:             h.c.HTML,
+		Monospace:        h.c.Monospace,
+		PriorityOverride: h.c.PriorityOverride,
+	}
+
+	if v, ok := tags["pushover_user"]; ok {
+		ad.User = v
+	}
+	if v, ok := tags["pushover_device"]; ok {
+		ad.Device = v
+	}
+	if v, ok := tags["pushover_sound"]; ok {
+		ad.Sound = v
+	}
+	if v, ok := tags["pushover_url"]; ok {
+		ad.URL = v
+	}
+	if v, ok := tags["pushover_priority"]; ok {
+		if n, err := strconv.Atoi(v); err != nil {
+			h.logger.Println("E! invalid pushover_priority tag value", v, err)
+		} else {
+			ad.PriorityOverride = &n
+		}
+	}
+
+	if h.c.Attachment != "" {
+		data, name, err := readAttachment(h.c.Attachment)
+		if err != nil {
+			h.logger.Println("E! failed to read Pushover attachment", h.c.Attachment, err)
+		} else {
+			ad.Attachment = data
+			ad.AttachmentName = name
+		}
+	}
+
+	if err := h.s.Alert(ad); err != nil {
 		h.logger.Println("E! failed to send event to Pushover", err)
 	}
 }
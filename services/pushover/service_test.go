@@ -0,0 +1,405 @@
+package pushover
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/influxdata/kapacitor/services/pushover/pushovertest"
+)
+
+func NewTestService(c Config) *Service {
+	s := NewService(c, log.New(ioutil.Discard, "", 0))
+	return s
+}
+
+func TestService_Alert_Emergency_SendsRetryExpire(t *testing.T) {
+	ts := pushovertest.NewServer()
+	defer ts.Close()
+
+	c := Config{
+		Enabled: true,
+		Token:   "token",
+		User:    "user",
+		URL:     ts.URL + "/1/messages.json",
+	}
+
+	s := NewTestService(c)
+
+	if err := s.Alert(AlertData{
+		Message: "emergency",
+		Level:   alert.Critical,
+		Retry:   30,
+		Expire:  300,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The emergency-priority receipt poller runs in the background; its
+	// first poll comes after receiptPollInterval, and the fake does not
+	// record receipt polls, so only the message request is recorded.
+	time.Sleep(10 * time.Millisecond)
+
+	requests := ts.Requests()
+	if got, exp := len(requests), 1; got != exp {
+		t.Fatalf("unexpected number of requests got %d exp %d", got, exp)
+	}
+	pd := requests[0].PostData
+	if got, exp := pd.Retry, 30; got != exp {
+		t.Errorf("unexpected retry got %d exp %d", got, exp)
+	}
+	if got, exp := pd.Expire, 300; got != exp {
+		t.Errorf("unexpected expire got %d exp %d", got, exp)
+	}
+}
+
+func TestService_CheckReceipt_CancelOnAck(t *testing.T) {
+	ts := pushovertest.NewServer()
+	defer ts.Close()
+
+	c := Config{
+		Enabled: true,
+		Token:   "token",
+		User:    "user",
+		URL:     ts.URL + "/1/messages.json",
+	}
+
+	s := NewTestService(c)
+
+	ts.SetReceiptAcknowledged("r1")
+
+	acknowledged, expired, calledBack, err := s.checkReceipt(c.URL, "r1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !acknowledged {
+		t.Error("expected receipt to be acknowledged")
+	}
+	if expired {
+		t.Error("did not expect receipt to be expired")
+	}
+	if calledBack {
+		t.Error("did not expect receipt to be called back")
+	}
+}
+
+func TestService_Alert_Emergency_ClampsRetryAndExpireToBounds(t *testing.T) {
+	ts := pushovertest.NewServer()
+	defer ts.Close()
+
+	c := Config{
+		Enabled: true,
+		Token:   "token",
+		User:    "user",
+		URL:     ts.URL + "/1/messages.json",
+	}
+
+	s := NewTestService(c)
+
+	if err := s.Alert(AlertData{
+		Message: "emergency",
+		Level:   alert.Critical,
+		Retry:   5,
+		Expire:  20000,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	pd := ts.Requests()[0].PostData
+	if got, exp := pd.Retry, minRetry; got != exp {
+		t.Errorf("unexpected retry got %d exp %d (below-minimum retry must be clamped)", got, exp)
+	}
+	if got, exp := pd.Expire, maxExpire; got != exp {
+		t.Errorf("unexpected expire got %d exp %d (above-maximum expire must be clamped)", got, exp)
+	}
+}
+
+func TestService_ReceiptState_TracksPolledAcknowledgement(t *testing.T) {
+	ts := pushovertest.NewServer()
+	defer ts.Close()
+
+	c := Config{
+		Enabled: true,
+		Token:   "token",
+		User:    "user",
+		URL:     ts.URL + "/1/messages.json",
+	}
+
+	s := NewTestService(c)
+	ts.SetReceiptAcknowledged("r1")
+
+	if _, _, _, found := s.ReceiptState("r1"); found {
+		t.Fatal("expected no state before any poll has occurred")
+	}
+
+	if err := s.Alert(AlertData{Message: "emergency", Level: alert.Critical, Retry: minRetry, Expire: 300}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The receipt returned by the synthetic server is predictable but
+	// isn't exposed to the caller, so poll checkReceipt directly instead
+	// of racing the background poller for an unknown receipt id.
+	acknowledged, _, _, err := s.checkReceipt(c.URL, "r1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !acknowledged {
+		t.Fatal("expected receipt r1 to be acknowledged")
+	}
+	s.setReceiptState("r1", receiptState{acknowledged: true})
+
+	acknowledged, expired, calledBack, found := s.ReceiptState("r1")
+	if !found || !acknowledged || expired || calledBack {
+		t.Fatalf("unexpected receipt state acknowledged=%v expired=%v calledBack=%v found=%v", acknowledged, expired, calledBack, found)
+	}
+}
+
+func TestService_Alert_Attachment(t *testing.T) {
+	ts := pushovertest.NewServer()
+	defer ts.Close()
+
+	c := Config{
+		Enabled: true,
+		Token:   "token",
+		User:    "user",
+		URL:     ts.URL + "/1/messages.json",
+	}
+
+	s := NewTestService(c)
+
+	imgData := []byte("not really a png, just test bytes")
+	if err := s.Alert(AlertData{
+		Message:        "look at this",
+		Attachment:     imgData,
+		AttachmentName: "graph.png",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := ts.Requests()
+	if got, exp := len(requests), 1; got != exp {
+		t.Fatalf("unexpected number of requests got %d exp %d", got, exp)
+	}
+	pd := requests[0].PostData
+	if got, exp := string(pd.Attachment), string(imgData); got != exp {
+		t.Errorf("unexpected attachment bytes got %q exp %q", got, exp)
+	}
+	if got, exp := pd.AttachmentName, "graph.png"; got != exp {
+		t.Errorf("unexpected attachment name got %s exp %s", got, exp)
+	}
+}
+
+func TestHandler_Handle_AttachmentFromFilesystemPath(t *testing.T) {
+	ts := pushovertest.NewServer()
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "pushover-attachment-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "graph.png")
+	imgData := []byte("not really a png, just test bytes")
+	if err := ioutil.WriteFile(path, imgData, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := Config{Enabled: true, Token: "token", User: "user", URL: ts.URL + "/1/messages.json"}
+	s := NewTestService(c)
+
+	h := s.Handler(HandlerConfig{Attachment: path}, log.New(ioutil.Discard, "", 0))
+	h.Handle(alert.Event{})
+
+	pd := ts.Requests()[0].PostData
+	if got, exp := string(pd.Attachment), string(imgData); got != exp {
+		t.Errorf("unexpected attachment bytes got %q exp %q", got, exp)
+	}
+	if got, exp := pd.AttachmentName, "graph.png"; got != exp {
+		t.Errorf("unexpected attachment name got %s exp %s", got, exp)
+	}
+}
+
+func TestHandler_Handle_AttachmentFetchedFromURL(t *testing.T) {
+	ts := pushovertest.NewServer()
+	defer ts.Close()
+
+	imgData := []byte("not really a png, just test bytes")
+	attachmentTs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(imgData)
+	}))
+	defer attachmentTs.Close()
+
+	c := Config{Enabled: true, Token: "token", User: "user", URL: ts.URL + "/1/messages.json"}
+	s := NewTestService(c)
+
+	h := s.Handler(HandlerConfig{Attachment: attachmentTs.URL + "/graph.png"}, log.New(ioutil.Discard, "", 0))
+	h.Handle(alert.Event{})
+
+	pd := ts.Requests()[0].PostData
+	if got, exp := string(pd.Attachment), string(imgData); got != exp {
+		t.Errorf("unexpected attachment bytes got %q exp %q", got, exp)
+	}
+	if got, exp := pd.AttachmentName, "graph.png"; got != exp {
+		t.Errorf("unexpected attachment name got %s exp %s", got, exp)
+	}
+}
+
+func TestHandler_Handle_PriorityOverrideBypassesLevelMapping(t *testing.T) {
+	ts := pushovertest.NewServer()
+	defer ts.Close()
+
+	c := Config{Enabled: true, Token: "token", User: "user", URL: ts.URL + "/1/messages.json"}
+	s := NewTestService(c)
+
+	emergency := 2
+	h := s.Handler(HandlerConfig{PriorityOverride: &emergency, Retry: minRetry, Expire: 300}, log.New(ioutil.Discard, "", 0))
+
+	var event alert.Event
+	event.State.Level = alert.Warning
+	event.State.Message = "degraded but routed as emergency"
+	h.Handle(event)
+	time.Sleep(10 * time.Millisecond)
+
+	pd := ts.Requests()[0].PostData
+	if got, exp := pd.Priority, emergency; got != exp {
+		t.Errorf("unexpected priority got %d exp %d, PriorityOverride should bypass the Warning level's normal mapping", got, exp)
+	}
+}
+
+func TestService_Alert_HTML(t *testing.T) {
+	ts := pushovertest.NewServer()
+	defer ts.Close()
+
+	c := Config{
+		Enabled: true,
+		Token:   "token",
+		User:    "user",
+		URL:     ts.URL + "/1/messages.json",
+	}
+
+	s := NewTestService(c)
+
+	if err := s.Alert(AlertData{
+		Message: "<b>bold</b>",
+		HTML:    true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	pd := ts.Requests()[0].PostData
+	if !pd.HTML {
+		t.Error("expected html flag to be set")
+	}
+	if pd.Monospace {
+		t.Error("did not expect monospace flag to be set")
+	}
+}
+
+func TestService_Alert_HTMLAndMonospace_Rejected(t *testing.T) {
+	c := Config{
+		Enabled: true,
+		Token:   "token",
+		User:    "user",
+		URL:     "http://example.com",
+	}
+
+	s := NewTestService(c)
+
+	err := s.Alert(AlertData{
+		Message:   "bad",
+		HTML:      true,
+		Monospace: true,
+	})
+	if err == nil {
+		t.Fatal("expected error setting both html and monospace")
+	}
+}
+
+func TestService_Alert_RetriesOn5xx(t *testing.T) {
+	ts := pushovertest.NewServer()
+	defer ts.Close()
+	ts.FailNext(2, 500)
+
+	c := Config{
+		Enabled: true,
+		Token:   "token",
+		User:    "user",
+		URL:     ts.URL + "/1/messages.json",
+		Retry: RetryConfig{
+			MaxRetries:     3,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     5 * time.Millisecond,
+			Jitter:         time.Millisecond,
+		},
+	}
+
+	s := NewTestService(c)
+
+	if err := s.Alert(AlertData{Message: "retry me"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, exp := len(ts.Requests()), 1; got != exp {
+		t.Fatalf("unexpected number of recorded requests got %d exp %d", got, exp)
+	}
+}
+
+func TestService_Alert_NoRetryOn4xx(t *testing.T) {
+	ts := pushovertest.NewServer()
+	defer ts.Close()
+	ts.FailNext(1, 400)
+
+	c := Config{
+		Enabled: true,
+		Token:   "token",
+		User:    "user",
+		URL:     ts.URL + "/1/messages.json",
+		Retry: RetryConfig{
+			MaxRetries:     3,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     5 * time.Millisecond,
+		},
+	}
+
+	s := NewTestService(c)
+
+	if err := s.Alert(AlertData{Message: "bad request"}); err == nil {
+		t.Fatal("expected error for 4xx response")
+	}
+
+	if got, exp := len(ts.Requests()), 0; got != exp {
+		t.Fatalf("unexpected number of recorded requests got %d exp %d, request should not have been retried", got, exp)
+	}
+}
+
+func TestService_Alert_BoundedRetries(t *testing.T) {
+	ts := pushovertest.NewServer()
+	defer ts.Close()
+	ts.FailNext(10, 500)
+
+	c := Config{
+		Enabled: true,
+		Token:   "token",
+		User:    "user",
+		URL:     ts.URL + "/1/messages.json",
+		Retry: RetryConfig{
+			MaxRetries:     2,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     5 * time.Millisecond,
+		},
+	}
+
+	s := NewTestService(c)
+
+	if err := s.Alert(AlertData{Message: "give up eventually"}); err == nil {
+		t.Fatal("expected error after exhausting retries")
+	}
+}
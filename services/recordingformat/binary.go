@@ -0,0 +1,96 @@
+package recordingformat
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/influxdata/kapacitor/services/recordingformat/rpc"
+)
+
+// FormatVersion is the semver of the binary recording format itself.
+const FormatVersion = "1.0.0"
+
+// Writer writes a binary recording: the magic prefix, a length-prefixed
+// Header, then a length-prefixed RecordedPoint per Write call.
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter writes header immediately, so every subsequent Write call only
+// needs to frame a single point.
+func NewWriter(w io.Writer, kapacitorVersion string, header *rpc.Header) (*Writer, error) {
+	if _, err := w.Write(magic[:]); err != nil {
+		return nil, err
+	}
+	header.FormatVersion = FormatVersion
+	header.KapacitorVersion = kapacitorVersion
+	if err := writeFramed(w, header); err != nil {
+		return nil, fmt.Errorf("failed to write recording header: %s", err)
+	}
+	return &Writer{w: w}, nil
+}
+
+func (bw *Writer) Write(p *rpc.RecordedPoint) error {
+	return writeFramed(bw.w, p)
+}
+
+func writeFramed(w io.Writer, m proto.Message) error {
+	data, err := proto.Marshal(m)
+	if err != nil {
+		return err
+	}
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(data)))
+	if _, err := w.Write(length[:]); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Reader reads a binary recording written by Writer. NewReader reads and
+// checks the magic prefix itself, so it is given the stream from its
+// start (DetectFormat's returned reader still yields the prefix).
+type Reader struct {
+	r io.Reader
+}
+
+func NewReader(r io.Reader) (*Reader, *rpc.Header, error) {
+	var prefix [4]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return nil, nil, fmt.Errorf("failed to read recording magic: %s", err)
+	}
+	if prefix != magic {
+		return nil, nil, fmt.Errorf("not a binary recording file")
+	}
+
+	header := &rpc.Header{}
+	if err := readFramed(r, header); err != nil {
+		return nil, nil, fmt.Errorf("failed to read recording header: %s", err)
+	}
+	return &Reader{r: r}, header, nil
+}
+
+// Next reads the next point, returning io.EOF once the stream is
+// exhausted.
+func (br *Reader) Next() (*rpc.RecordedPoint, error) {
+	p := &rpc.RecordedPoint{}
+	if err := readFramed(br.r, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func readFramed(r io.Reader, m proto.Message) error {
+	var length [4]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return err
+	}
+	data := make([]byte, binary.BigEndian.Uint32(length[:]))
+	if _, err := io.ReadFull(r, data); err != nil {
+		return err
+	}
+	return proto.Unmarshal(data, m)
+}
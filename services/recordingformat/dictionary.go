@@ -0,0 +1,51 @@
+package recordingformat
+
+// Dictionary interns repeated strings (tag/field keys) to small integer
+// refs, so a RecordedPoint only needs to carry an index rather than the
+// key string itself.
+type Dictionary struct {
+	keys    []string
+	indexOf map[string]uint32
+}
+
+func NewDictionary() *Dictionary {
+	return &Dictionary{indexOf: make(map[string]uint32)}
+}
+
+// Intern returns key's ref, assigning it the next available ref the first
+// time it is seen.
+func (d *Dictionary) Intern(key string) uint32 {
+	if ref, ok := d.indexOf[key]; ok {
+		return ref
+	}
+	ref := uint32(len(d.keys))
+	d.keys = append(d.keys, key)
+	d.indexOf[key] = ref
+	return ref
+}
+
+// Lookup returns the key for ref, or false if ref is out of range.
+func (d *Dictionary) Lookup(ref uint32) (string, bool) {
+	if int(ref) >= len(d.keys) {
+		return "", false
+	}
+	return d.keys[ref], true
+}
+
+// Keys returns every interned key in ref order, suitable for writing into
+// a Header's TagKeys/FieldKeys.
+func (d *Dictionary) Keys() []string {
+	out := make([]string, len(d.keys))
+	copy(out, d.keys)
+	return out
+}
+
+// NewDictionaryFromKeys rebuilds a Dictionary from a Header's TagKeys or
+// FieldKeys, preserving their original refs.
+func NewDictionaryFromKeys(keys []string) *Dictionary {
+	d := NewDictionary()
+	for _, k := range keys {
+		d.Intern(k)
+	}
+	return d
+}
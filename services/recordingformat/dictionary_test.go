@@ -0,0 +1,43 @@
+package recordingformat
+
+import "testing"
+
+func TestDictionary_InternIsStable(t *testing.T) {
+	d := NewDictionary()
+	host := d.Intern("host")
+	region := d.Intern("region")
+	hostAgain := d.Intern("host")
+
+	if host != hostAgain {
+		t.Fatalf("expected repeated Intern of the same key to return the same ref, got %d and %d", host, hostAgain)
+	}
+	if host == region {
+		t.Fatal("expected distinct keys to get distinct refs")
+	}
+
+	key, ok := d.Lookup(host)
+	if !ok || key != "host" {
+		t.Fatalf("unexpected Lookup result: %q, %v", key, ok)
+	}
+}
+
+func TestDictionary_LookupOutOfRange(t *testing.T) {
+	d := NewDictionary()
+	if _, ok := d.Lookup(0); ok {
+		t.Fatal("expected Lookup on an empty dictionary to fail")
+	}
+}
+
+func TestNewDictionaryFromKeys_PreservesRefs(t *testing.T) {
+	keys := []string{"host", "region", "service"}
+	d := NewDictionaryFromKeys(keys)
+
+	for i, k := range keys {
+		if d.Intern(k) != uint32(i) {
+			t.Fatalf("expected %q to keep ref %d, got %d", k, i, d.Intern(k))
+		}
+	}
+	if got := d.Keys(); len(got) != len(keys) {
+		t.Fatalf("unexpected Keys() length: %v", got)
+	}
+}
@@ -0,0 +1,11 @@
+// Package recordingformat adds a compact, protobuf-framed binary
+// recording format alongside the existing line-oriented JSON/line-protocol
+// format, for recordings of high-cardinality streams where JSON parsing
+// and repeated tag/field key strings dominate replay time and file size.
+//
+// The generated message code lives in the rpc subpackage and is produced
+// from rpc/recordedpoint.proto; run `go generate` after changing the proto
+// to regenerate it.
+package recordingformat
+
+//go:generate protoc -I rpc --go_out=rpc --go_opt=paths=source_relative rpc/recordedpoint.proto
@@ -0,0 +1,40 @@
+package recordingformat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Format identifies how a recording file is encoded on disk.
+type Format string
+
+const (
+	FormatJSON   Format = "json"
+	FormatBinary Format = "binary"
+)
+
+// magic is the 4-byte prefix written at the start of every binary
+// recording file, used by DetectFormat to distinguish it from the
+// existing line-oriented JSON/line-protocol format without relying on a
+// file extension or an explicit Format field.
+var magic = [4]byte{'K', 'R', 'E', 'C'}
+
+// DetectFormat peeks at the first bytes of r to determine whether it is a
+// binary recording, returning a reader that still yields the full stream
+// (including the bytes already peeked) so the caller can read from the
+// start regardless of which format was detected.
+func DetectFormat(r io.Reader) (Format, io.Reader, error) {
+	br := bufio.NewReader(r)
+	prefix, err := br.Peek(len(magic))
+	if err != nil && err != io.EOF {
+		return "", nil, fmt.Errorf("failed to detect recording format: %s", err)
+	}
+
+	for i := range magic {
+		if i >= len(prefix) || prefix[i] != magic[i] {
+			return FormatJSON, br, nil
+		}
+	}
+	return FormatBinary, br, nil
+}
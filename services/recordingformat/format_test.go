@@ -0,0 +1,59 @@
+package recordingformat
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestDetectFormat_Binary(t *testing.T) {
+	buf := bytes.NewBuffer(append([]byte{'K', 'R', 'E', 'C'}, []byte("rest of the file")...))
+
+	format, r, err := DetectFormat(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != FormatBinary {
+		t.Fatalf("unexpected format: %s", format)
+	}
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "KRECrest of the file" {
+		t.Fatalf("expected DetectFormat to preserve the full stream, got %q", data)
+	}
+}
+
+func TestDetectFormat_JSON(t *testing.T) {
+	buf := bytes.NewBufferString(`{"name":"testStreamTask"}` + "\n")
+
+	format, r, err := DetectFormat(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != FormatJSON {
+		t.Fatalf("unexpected format: %s", format)
+	}
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"name":"testStreamTask"}`+"\n" {
+		t.Fatalf("expected DetectFormat to preserve the full stream, got %q", data)
+	}
+}
+
+func TestDetectFormat_ShortFile(t *testing.T) {
+	buf := bytes.NewBufferString("KR")
+
+	format, _, err := DetectFormat(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != FormatJSON {
+		t.Fatalf("expected a file shorter than the magic prefix to be treated as json, got %s", format)
+	}
+}
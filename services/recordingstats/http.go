@@ -0,0 +1,22 @@
+package recordingstats
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ServeHTTP implements GET /kapacitor/v1/recordings/stats, returning the
+// aggregate size and point count across every retained recording.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	agg := r.Aggregate()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"size":       agg.Size,
+		"sizeHuman":  HumanSize(agg.Size),
+		"pointCount": agg.PointCount,
+	})
+}
@@ -0,0 +1,122 @@
+// Package recordingstats tracks size/throughput counters for recordings
+// and replays as they are written or executed, and aggregates them across
+// every retained recording for the /recordings/stats endpoint.
+package recordingstats
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Stats is the set of fields added to client.Recording / client.Replay.
+type Stats struct {
+	Size       int64         `json:"size"`
+	PointCount int64         `json:"pointCount"`
+	Duration   time.Duration `json:"duration"`
+}
+
+// PointsPerSec and BytesPerSec are zero if Duration is zero, rather than
+// dividing by zero.
+func (s Stats) PointsPerSec() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.PointCount) / s.Duration.Seconds()
+}
+
+func (s Stats) BytesPerSec() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Size) / s.Duration.Seconds()
+}
+
+// Counter accumulates Stats for a single in-progress recording or replay.
+// It is safe for concurrent use since points are typically counted from
+// the task execution goroutine while the HTTP layer reads it concurrently.
+type Counter struct {
+	mu      sync.Mutex
+	started time.Time
+	stats   Stats
+}
+
+func NewCounter(now time.Time) *Counter {
+	return &Counter{started: now}
+}
+
+// AddPoint records one more point of size bytes having been
+// written/processed as of now.
+func (c *Counter) AddPoint(bytes int, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.PointCount++
+	c.stats.Size += int64(bytes)
+	c.stats.Duration = now.Sub(c.started)
+}
+
+func (c *Counter) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// Registry aggregates the Stats of every recording/replay that has not
+// yet been deleted, for the /recordings/stats endpoint.
+type Registry struct {
+	mu    sync.Mutex
+	stats map[string]Stats
+}
+
+func NewRegistry() *Registry {
+	return &Registry{stats: make(map[string]Stats)}
+}
+
+func (r *Registry) Set(id string, s Stats) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats[id] = s
+}
+
+// Delete removes id's stats, so its bytes no longer count toward
+// Aggregate, mirroring deleting the recording's file from disk.
+func (r *Registry) Delete(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.stats, id)
+}
+
+// Aggregate sums Size and PointCount across every retained recording.
+func (r *Registry) Aggregate() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var agg Stats
+	for _, s := range r.stats {
+		agg.Size += s.Size
+		agg.PointCount += s.PointCount
+	}
+	return agg
+}
+
+// HumanSize formats bytes as e.g. "1.2 MB", matching the CLI listing's
+// display format.
+func HumanSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// HumanRate formats a points/sec rate as e.g. "3.4k pts/s".
+func HumanRate(pointsPerSec float64) string {
+	if pointsPerSec < 1000 {
+		return fmt.Sprintf("%.0f pts/s", pointsPerSec)
+	}
+	return fmt.Sprintf("%.1fk pts/s", pointsPerSec/1000)
+}
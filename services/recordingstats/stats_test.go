@@ -0,0 +1,78 @@
+package recordingstats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCounter_AccumulatesThroughput(t *testing.T) {
+	start := time.Now()
+	c := NewCounter(start)
+
+	for i := 0; i < 100; i++ {
+		c.AddPoint(50, start.Add(time.Duration(i+1)*10*time.Millisecond))
+	}
+
+	s := c.Stats()
+	if s.PointCount != 100 {
+		t.Fatalf("unexpected point count: %d", s.PointCount)
+	}
+	if s.Size != 5000 {
+		t.Fatalf("unexpected size: %d", s.Size)
+	}
+	if s.PointsPerSec() <= 0 {
+		t.Fatal("expected a positive points/sec rate")
+	}
+	if s.BytesPerSec() <= 0 {
+		t.Fatal("expected a positive bytes/sec rate")
+	}
+}
+
+func TestStats_ZeroDurationHasZeroRates(t *testing.T) {
+	s := Stats{PointCount: 10, Size: 100}
+	if s.PointsPerSec() != 0 || s.BytesPerSec() != 0 {
+		t.Fatal("expected zero rates when duration is zero")
+	}
+}
+
+func TestRegistry_AggregateAndDelete(t *testing.T) {
+	r := NewRegistry()
+	r.Set("testRecordingID-0", Stats{Size: 100, PointCount: 10})
+	r.Set("testRecordingID-1", Stats{Size: 200, PointCount: 20})
+
+	agg := r.Aggregate()
+	if agg.Size != 300 || agg.PointCount != 30 {
+		t.Fatalf("unexpected aggregate: %+v", agg)
+	}
+
+	r.Delete("testRecordingID-0")
+	agg = r.Aggregate()
+	if agg.Size != 200 || agg.PointCount != 20 {
+		t.Fatalf("expected deleted recording's bytes to be freed, got %+v", agg)
+	}
+}
+
+func TestHumanSize(t *testing.T) {
+	cases := []struct {
+		bytes int64
+		exp   string
+	}{
+		{500, "500 B"},
+		{1536, "1.5 KB"},
+		{1200000, "1.1 MB"},
+	}
+	for _, c := range cases {
+		if got := HumanSize(c.bytes); got != c.exp {
+			t.Errorf("HumanSize(%d) = %q, exp %q", c.bytes, got, c.exp)
+		}
+	}
+}
+
+func TestHumanRate(t *testing.T) {
+	if got := HumanRate(3400); got != "3.4k pts/s" {
+		t.Errorf("unexpected HumanRate: %q", got)
+	}
+	if got := HumanRate(42); got != "42 pts/s" {
+		t.Errorf("unexpected HumanRate: %q", got)
+	}
+}
@@ -0,0 +1,136 @@
+// Package replaymerge k-way merges the point streams of several
+// recordings into a single ordered stream, so CreateReplayOptions can
+// replay more than one recording against a task in a single pass, as
+// reproducing a multi-source incident against a joined TICKscript
+// requires.
+package replaymerge
+
+import (
+	"container/heap"
+	"encoding/json"
+	"time"
+)
+
+// Strategy controls how multiple recordings' point streams are combined.
+type Strategy string
+
+const (
+	// Interleave merges every source's points by timestamp, honoring
+	// RecordingTime/Fast clock semantics across sources.
+	Interleave Strategy = "interleave"
+	// Concat plays each source to completion in the order given, without
+	// interleaving by timestamp.
+	Concat Strategy = "concat"
+	// Union interleaves by timestamp like Interleave, but drops a point
+	// whose (Series, Time) has already been emitted by an earlier source,
+	// so overlapping recordings of the same series don't double up.
+	Union Strategy = "union"
+)
+
+// Point is one point from a recording's stream, carrying enough identity
+// to merge and de-duplicate across recordings.
+type Point struct {
+	Time   time.Time
+	Series string
+	Data   json.RawMessage
+}
+
+// Source yields a single recording's points in their recorded order.
+// Next returns ok=false once the source is exhausted.
+type Source interface {
+	Next() (p Point, ok bool, err error)
+}
+
+// Merge combines sources into a single ordered stream according to
+// strategy.
+func Merge(strategy Strategy, sources []Source) ([]Point, error) {
+	switch strategy {
+	case Concat:
+		return concat(sources)
+	case Union:
+		return interleave(sources, true)
+	default:
+		return interleave(sources, false)
+	}
+}
+
+func concat(sources []Source) ([]Point, error) {
+	var out []Point
+	for _, s := range sources {
+		for {
+			p, ok, err := s.Next()
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				break
+			}
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+// heapItem holds one source's current head point, so merging pulls the
+// globally earliest point across every source without reading a source
+// ahead of where the merge has consumed it.
+type heapItem struct {
+	point  Point
+	source int
+}
+
+type pointHeap []heapItem
+
+func (h pointHeap) Len() int            { return len(h) }
+func (h pointHeap) Less(i, j int) bool  { return h[i].point.Time.Before(h[j].point.Time) }
+func (h pointHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *pointHeap) Push(x interface{}) { *h = append(*h, x.(heapItem)) }
+func (h *pointHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+func interleave(sources []Source, dedupSeries bool) ([]Point, error) {
+	h := &pointHeap{}
+	heap.Init(h)
+
+	advance := func(i int) error {
+		p, ok, err := sources[i].Next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Push(h, heapItem{point: p, source: i})
+		}
+		return nil
+	}
+
+	for i := range sources {
+		if err := advance(i); err != nil {
+			return nil, err
+		}
+	}
+
+	seen := make(map[string]bool)
+	var out []Point
+	for h.Len() > 0 {
+		item := heap.Pop(h).(heapItem)
+
+		if !dedupSeries || !seen[seriesKey(item.point)] {
+			out = append(out, item.point)
+			seen[seriesKey(item.point)] = true
+		}
+
+		if err := advance(item.source); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func seriesKey(p Point) string {
+	return p.Series + "|" + p.Time.String()
+}
@@ -0,0 +1,103 @@
+package replaymerge
+
+import (
+	"testing"
+	"time"
+)
+
+type sliceSource struct {
+	points []Point
+	i      int
+}
+
+func newSliceSource(points ...Point) *sliceSource {
+	return &sliceSource{points: points}
+}
+
+func (s *sliceSource) Next() (Point, bool, error) {
+	if s.i >= len(s.points) {
+		return Point{}, false, nil
+	}
+	p := s.points[s.i]
+	s.i++
+	return p, true, nil
+}
+
+func t0(sec int) time.Time {
+	return time.Unix(int64(sec), 0)
+}
+
+func TestMerge_Interleave(t *testing.T) {
+	a := newSliceSource(
+		Point{Time: t0(0), Series: "cpu"},
+		Point{Time: t0(2), Series: "cpu"},
+		Point{Time: t0(4), Series: "cpu"},
+	)
+	b := newSliceSource(
+		Point{Time: t0(1), Series: "mem"},
+		Point{Time: t0(3), Series: "mem"},
+	)
+
+	merged, err := Merge(Interleave, []Source{a, b})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []int{0, 1, 2, 3, 4}
+	if len(merged) != len(expected) {
+		t.Fatalf("unexpected merged length: %d", len(merged))
+	}
+	for i, p := range merged {
+		if p.Time.Unix() != int64(expected[i]) {
+			t.Fatalf("unexpected merge order: %v", merged)
+		}
+	}
+}
+
+func TestMerge_Concat(t *testing.T) {
+	a := newSliceSource(Point{Time: t0(5), Series: "cpu"}, Point{Time: t0(6), Series: "cpu"})
+	b := newSliceSource(Point{Time: t0(0), Series: "mem"})
+
+	merged, err := Merge(Concat, []Source{a, b})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(merged) != 3 || merged[0].Time.Unix() != 5 || merged[2].Time.Unix() != 0 {
+		t.Fatalf("expected concat to preserve source order, got %v", merged)
+	}
+}
+
+func TestMerge_UnionDropsDuplicateSeriesAtSameTime(t *testing.T) {
+	a := newSliceSource(Point{Time: t0(0), Series: "cpu", Data: []byte(`"a"`)})
+	b := newSliceSource(Point{Time: t0(0), Series: "cpu", Data: []byte(`"b"`)})
+
+	merged, err := Merge(Union, []Source{a, b})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(merged) != 1 {
+		t.Fatalf("expected the duplicate (series, time) to be dropped, got %v", merged)
+	}
+	if string(merged[0].Data) != `"a"` {
+		t.Fatalf("expected the first source to win, got %s", merged[0].Data)
+	}
+}
+
+func TestMerge_ThreeSourcesInterleaved(t *testing.T) {
+	a := newSliceSource(Point{Time: t0(0)}, Point{Time: t0(3)})
+	b := newSliceSource(Point{Time: t0(1)})
+	c := newSliceSource(Point{Time: t0(2)}, Point{Time: t0(4)})
+
+	merged, err := Merge(Interleave, []Source{a, b, c})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, p := range merged {
+		if p.Time.Unix() != int64(i) {
+			t.Fatalf("expected strictly increasing merged timeline, got %v", merged)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package rotatinglog
+
+import "time"
+
+// DefaultRotatePolicy is used by the alert log handler's .log() node when
+// TICKscript doesn't call .rotate(...) explicitly.
+func DefaultRotatePolicy() Config {
+	return Config{
+		MaxBytes:   0,
+		MaxAge:     0,
+		MaxBackups: 0,
+	}
+}
+
+// RotatePolicy is the TICKscript-facing property list for
+// .log(path).rotate(maxBytes, maxAge, maxBackups), kept distinct from
+// Config since maxAge arrives from TICKscript as a duration literal.
+type RotatePolicy struct {
+	MaxBytes   int64
+	MaxAge     time.Duration
+	MaxBackups int64
+}
+
+func (p RotatePolicy) Config() Config {
+	return Config{
+		MaxBytes:   p.MaxBytes,
+		MaxAge:     p.MaxAge,
+		MaxBackups: int(p.MaxBackups),
+	}
+}
@@ -0,0 +1,139 @@
+// Package rotatinglog implements a size/age/count-bounded rotating file
+// writer for the alert log handler's .log() sink, which otherwise appends
+// forever to a single file.
+package rotatinglog
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Config controls when a Writer rotates its file and how many rotated
+// backups it retains. A zero value for MaxBytes or MaxAge disables that
+// trigger; a zero MaxBackups keeps rotating without ever deleting a
+// backup.
+type Config struct {
+	MaxBytes   int64
+	MaxAge     time.Duration
+	MaxBackups int
+}
+
+// Writer is an io.WriteCloser that rotates the underlying file to a
+// numbered backup (path.001, path.002, ...) once a write would exceed
+// Config.MaxBytes, or once the current file is older than Config.MaxAge,
+// capping the retained backups at Config.MaxBackups.
+type Writer struct {
+	path string
+	c    Config
+
+	mu       sync.Mutex
+	f        *os.File
+	size     int64
+	openedAt time.Time
+}
+
+func NewWriter(path string, c Config) (*Writer, error) {
+	w := &Writer{path: path, c: c}
+	if err := w.openCurrent(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *Writer) openCurrent() error {
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file %q: %s", w.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat log file %q: %s", w.path, err)
+	}
+	w.f = f
+	w.size = info.Size()
+	w.openedAt = info.ModTime()
+	return nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.shouldRotate(len(p)) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.f.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *Writer) shouldRotate(nextWrite int) bool {
+	if w.c.MaxBytes > 0 && w.size+int64(nextWrite) > w.c.MaxBytes {
+		return true
+	}
+	if w.c.MaxAge > 0 && time.Since(w.openedAt) > w.c.MaxAge {
+		return true
+	}
+	return false
+}
+
+// rotate closes the current file, shifts existing numbered backups up by
+// one, renames the current file to the ".001" backup, and opens a fresh
+// file in its place.
+func (w *Writer) rotate() error {
+	if err := w.f.Close(); err != nil {
+		return fmt.Errorf("failed to close log file %q for rotation: %s", w.path, err)
+	}
+
+	if err := w.shiftBackups(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(w.path, w.backupPath(1)); err != nil {
+		return fmt.Errorf("failed to rotate log file %q: %s", w.path, err)
+	}
+
+	return w.openCurrent()
+}
+
+// shiftBackups renames path.NNN to path.(NNN+1) for every existing
+// backup, from the oldest to the newest so none are clobbered, then
+// removes whatever now exceeds MaxBackups.
+func (w *Writer) shiftBackups() error {
+	highest := 0
+	for {
+		if _, err := os.Stat(w.backupPath(highest + 1)); os.IsNotExist(err) {
+			break
+		}
+		highest++
+	}
+
+	for i := highest; i >= 1; i-- {
+		if w.c.MaxBackups > 0 && i+1 > w.c.MaxBackups {
+			if err := os.Remove(w.backupPath(i)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to prune rotated log %q: %s", w.backupPath(i), err)
+			}
+			continue
+		}
+		if err := os.Rename(w.backupPath(i), w.backupPath(i+1)); err != nil {
+			return fmt.Errorf("failed to shift rotated log %q: %s", w.backupPath(i), err)
+		}
+	}
+	return nil
+}
+
+func (w *Writer) backupPath(n int) string {
+	return fmt.Sprintf("%s.%03d", w.path, n)
+}
+
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.f.Close()
+}
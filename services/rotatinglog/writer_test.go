@@ -0,0 +1,78 @@
+package rotatinglog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriter_RotatesOnMaxBytes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotatinglog-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "alert.log")
+
+	w, err := NewWriter(path, Config{MaxBytes: 20, MaxBackups: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	lines := []string{
+		`{"id":"1"}` + "\n",
+		`{"id":"2"}` + "\n",
+		`{"id":"3"}` + "\n",
+	}
+	for _, line := range lines {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tail, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(tail) != lines[2] {
+		t.Fatalf("expected tail file to contain only the last record, got %q", tail)
+	}
+
+	backup, err := ioutil.ReadFile(path + ".001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(backup) != lines[1] {
+		t.Fatalf("expected backup .001 to contain the second record, got %q", backup)
+	}
+}
+
+func TestWriter_PrunesOldestBeyondMaxBackups(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotatinglog-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "alert.log")
+
+	w, err := NewWriter(path, Config{MaxBytes: 10, MaxBackups: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	for i := 0; i < 4; i++ {
+		if _, err := w.Write([]byte("0123456789\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := os.Stat(path + ".002"); !os.IsNotExist(err) {
+		t.Fatal("expected only one backup to be retained")
+	}
+	if _, err := os.Stat(path + ".001"); err != nil {
+		t.Fatal("expected the most recent backup to exist")
+	}
+}
@@ -0,0 +1,142 @@
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// renewBefore re-authenticates this much ahead of a token's reported
+// lease expiry, so a slow renewal request or clock skew doesn't let the
+// token actually lapse before it's replaced.
+const renewBefore = 30 * time.Second
+
+// AppRoleSource authenticates against Vault using an AppRole role ID and
+// secret ID, satisfying TokenSource with a token it renews in the
+// background ahead of its lease expiring. Callers must call Open before
+// Token returns a valid value, and Close to stop the renewal goroutine.
+type AppRoleSource struct {
+	Addr       string
+	RoleID     string
+	SecretID   string
+	HTTPClient *http.Client
+	Logger     *log.Logger
+
+	mu    sync.Mutex
+	token string
+
+	closing chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewAppRoleSource builds an AppRoleSource against addr with the given
+// AppRole credentials.
+func NewAppRoleSource(addr, roleID, secretID string, l *log.Logger) *AppRoleSource {
+	return &AppRoleSource{
+		Addr:       addr,
+		RoleID:     roleID,
+		SecretID:   secretID,
+		HTTPClient: http.DefaultClient,
+		Logger:     l,
+	}
+}
+
+// Open logs in immediately so Token is usable as soon as Open returns,
+// then starts the background renewal goroutine.
+func (s *AppRoleSource) Open() error {
+	leaseDuration, err := s.login()
+	if err != nil {
+		return err
+	}
+	s.closing = make(chan struct{})
+	s.wg.Add(1)
+	go s.renewLoop(leaseDuration)
+	return nil
+}
+
+// Close stops the renewal goroutine. It does not revoke the current
+// token.
+func (s *AppRoleSource) Close() error {
+	if s.closing != nil {
+		close(s.closing)
+		s.wg.Wait()
+	}
+	return nil
+}
+
+// Token returns the most recently obtained Vault token.
+func (s *AppRoleSource) Token() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token
+}
+
+func (s *AppRoleSource) renewLoop(leaseDuration time.Duration) {
+	defer s.wg.Done()
+	for {
+		wait := leaseDuration - renewBefore
+		if wait <= 0 {
+			wait = time.Second
+		}
+		select {
+		case <-s.closing:
+			return
+		case <-time.After(wait):
+		}
+
+		next, err := s.login()
+		if err != nil {
+			if s.Logger != nil {
+				s.Logger.Println("E! failed to renew vault approle token", err)
+			}
+			leaseDuration = renewBefore
+			continue
+		}
+		leaseDuration = next
+	}
+}
+
+type appRoleLoginResponse struct {
+	Auth struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+	} `json:"auth"`
+}
+
+// login authenticates with Vault's AppRole auth method, storing the
+// resulting token and returning its lease duration.
+func (s *AppRoleSource) login() (time.Duration, error) {
+	body := strings.NewReader(fmt.Sprintf(`{"role_id":%q,"secret_id":%q}`, s.RoleID, s.SecretID))
+	req, err := http.NewRequest(http.MethodPost, s.Addr+"/v1/auth/approle/login", body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("secrets: vault approle login returned %s", resp.Status)
+	}
+
+	var lr appRoleLoginResponse
+	if err := json.NewDecoder(resp.Body).Decode(&lr); err != nil {
+		return 0, err
+	}
+	if lr.Auth.ClientToken == "" {
+		return 0, fmt.Errorf("secrets: vault approle login response had no client_token")
+	}
+
+	s.mu.Lock()
+	s.token = lr.Auth.ClientToken
+	s.mu.Unlock()
+
+	return time.Duration(lr.Auth.LeaseDuration) * time.Second, nil
+}
@@ -0,0 +1,102 @@
+package secrets
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAppRoleSource_OpenLogsInAndTokenIsAvailable(t *testing.T) {
+	var logins int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&logins, 1)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"auth": map[string]interface{}{
+				"client_token":   "s.initialtoken",
+				"lease_duration": 3600,
+			},
+		})
+	}))
+	defer ts.Close()
+
+	s := NewAppRoleSource(ts.URL, "role-id", "secret-id", log.New(ioutil.Discard, "", 0))
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if got, exp := s.Token(), "s.initialtoken"; got != exp {
+		t.Fatalf("got token %q, expected %q", got, exp)
+	}
+	if atomic.LoadInt32(&logins) != 1 {
+		t.Fatalf("expected exactly one login request, got %d", logins)
+	}
+}
+
+func TestAppRoleSource_RenewsBeforeLeaseExpires(t *testing.T) {
+	var logins int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&logins, 1)
+		token := "s.first"
+		if n > 1 {
+			token = "s.renewed"
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"auth": map[string]interface{}{
+				"client_token":   token,
+				"lease_duration": 1,
+			},
+		})
+	}))
+	defer ts.Close()
+
+	s := NewAppRoleSource(ts.URL, "role-id", "secret-id", log.New(ioutil.Discard, "", 0))
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for s.Token() != "s.renewed" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := s.Token(); got != "s.renewed" {
+		t.Fatalf("expected the background renewal to replace the token, got %q", got)
+	}
+}
+
+func TestAppRoleSource_LoginFailureReturnsError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "permission denied", http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	s := NewAppRoleSource(ts.URL, "role-id", "bad-secret", log.New(ioutil.Discard, "", 0))
+	if err := s.Open(); err == nil {
+		t.Fatal("expected Open to fail when login is rejected")
+	}
+}
+
+func TestHTTPVaultClient_UsesTokenFromSource(t *testing.T) {
+	var gotToken string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotToken = r.Header.Get("X-Vault-Token")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"data": map[string]interface{}{"token": "v"}},
+		})
+	}))
+	defer ts.Close()
+
+	client := NewHTTPVaultClientWithSource(ts.URL, staticToken("dynamic-token"))
+	if _, err := client.ReadSecret("kv/data/kapacitor/slack"); err != nil {
+		t.Fatal(err)
+	}
+	if gotToken != "dynamic-token" {
+		t.Fatalf("expected the client to send the token from its TokenSource, got %q", gotToken)
+	}
+}
@@ -0,0 +1,66 @@
+package secrets
+
+import (
+	"fmt"
+	"strings"
+)
+
+// placeholderPrefix and placeholderSuffix delimit a secret reference
+// embedded inside an otherwise plaintext option value, e.g.
+// "${vault:kv/data/kapacitor/slack#token}". This is distinct from the
+// bare "scheme://ref" form Resolve accepts directly: placeholders are
+// meant to be typed into a handler option alongside other text, and to
+// be what a config read-back (e.g. GET /handlers/{id}) displays instead
+// of ever showing a resolved secret.
+const (
+	placeholderPrefix = "${"
+	placeholderSuffix = "}"
+)
+
+// IsPlaceholder reports whether value is (or contains) a secret
+// reference placeholder. Callers use this to decide whether a value read
+// back from storage needs resolving before use, or is safe to display
+// as-is.
+func IsPlaceholder(value string) bool {
+	return strings.Contains(value, placeholderPrefix) && strings.Contains(value, placeholderSuffix)
+}
+
+// ResolvePlaceholder replaces every "${scheme:ref}" placeholder in value
+// with the secret Resolver.Resolve returns for "scheme://ref", so a
+// handler option can be written once as a reference and resolved lazily
+// every time it's actually used to build an outbound request. A value
+// with no placeholder is returned unchanged.
+func ResolvePlaceholder(r *Resolver, value string) (string, error) {
+	var b strings.Builder
+	rest := value
+	for {
+		start := strings.Index(rest, placeholderPrefix)
+		if start < 0 {
+			b.WriteString(rest)
+			return b.String(), nil
+		}
+		end := strings.Index(rest[start:], placeholderSuffix)
+		if end < 0 {
+			b.WriteString(rest)
+			return b.String(), nil
+		}
+		end += start
+
+		b.WriteString(rest[:start])
+
+		ref := rest[start+len(placeholderPrefix) : end]
+		parts := strings.SplitN(ref, ":", 2)
+		if len(parts) != 2 {
+			return "", fmt.Errorf("secrets: malformed reference placeholder %q", "${"+ref+"}")
+		}
+		scheme, refPath := parts[0], parts[1]
+
+		resolved, err := r.Resolve(scheme + "://" + refPath)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(resolved)
+
+		rest = rest[end+len(placeholderSuffix):]
+	}
+}
@@ -0,0 +1,74 @@
+package secrets
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestIsPlaceholder(t *testing.T) {
+	cases := map[string]bool{
+		"${vault:kv/data/kapacitor/slack#token}": true,
+		"plaintext-password":                     false,
+		"":                                       false,
+	}
+	for value, want := range cases {
+		if got := IsPlaceholder(value); got != want {
+			t.Errorf("IsPlaceholder(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestResolvePlaceholder_ReplacesEmbeddedReference(t *testing.T) {
+	os.Setenv("KAPACITOR_TEST_PLACEHOLDER", "sekret")
+	defer os.Unsetenv("KAPACITOR_TEST_PLACEHOLDER")
+
+	r := NewResolver(time.Minute)
+	r.Register("env", EnvProvider{})
+
+	got, err := ResolvePlaceholder(r, "Bearer ${env:KAPACITOR_TEST_PLACEHOLDER}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "Bearer sekret" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestResolvePlaceholder_NoPlaceholderPassesThrough(t *testing.T) {
+	r := NewResolver(time.Minute)
+	got, err := ResolvePlaceholder(r, "plaintext-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "plaintext-password" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestResolvePlaceholder_VaultReferenceResolvedLazily(t *testing.T) {
+	client := &fakeVaultClient{data: map[string]interface{}{"token": "vault-secret-value"}}
+	r := NewResolver(time.Minute)
+	r.Register("vault", VaultProvider{Client: client})
+
+	stored := "${vault:kv/data/kapacitor/slack#token}"
+	if got, err := ResolvePlaceholder(r, stored); err != nil {
+		t.Fatal(err)
+	} else if got != "vault-secret-value" {
+		t.Fatalf("got %q", got)
+	}
+
+	// The stored value itself never changes: resolving twice reads the
+	// reference fresh (subject to the resolver's own TTL cache) rather
+	// than mutating stored.
+	if !IsPlaceholder(stored) {
+		t.Fatal("expected the original stored value to remain a placeholder")
+	}
+}
+
+func TestResolvePlaceholder_MalformedPlaceholderErrors(t *testing.T) {
+	r := NewResolver(time.Minute)
+	if _, err := ResolvePlaceholder(r, "${not-a-reference}"); err == nil {
+		t.Fatal("expected an error for a placeholder with no scheme separator")
+	}
+}
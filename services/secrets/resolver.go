@@ -0,0 +1,97 @@
+// Package secrets resolves redacted config fields written as a
+// reference URI (vault://, file://, env://, k8s://) to their actual
+// value, so the overrides store only ever persists the reference, never
+// the secret itself.
+package secrets
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Provider resolves a single scheme's references (e.g. every "vault://"
+// URI) to a secret value.
+type Provider interface {
+	Resolve(ref *url.URL) (string, error)
+}
+
+type cacheEntry struct {
+	value    string
+	resolved time.Time
+}
+
+// Resolver dispatches a reference URI to the Provider registered for its
+// scheme and caches the result for TTL, so a busy handler doesn't refetch
+// a secret on every alert.
+type Resolver struct {
+	TTL time.Duration
+	Now func() time.Time
+
+	mu        sync.Mutex
+	providers map[string]Provider
+	cache     map[string]cacheEntry
+}
+
+// NewResolver builds a Resolver whose cached values expire after ttl.
+func NewResolver(ttl time.Duration) *Resolver {
+	return &Resolver{
+		TTL:       ttl,
+		Now:       time.Now,
+		providers: make(map[string]Provider),
+		cache:     make(map[string]cacheEntry),
+	}
+}
+
+// Register installs provider to handle references of the given scheme
+// (e.g. "vault", "file", "env", "k8s").
+func (r *Resolver) Register(scheme string, provider Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[scheme] = provider
+}
+
+// Resolve returns the secret value for ref, a URI such as
+// "vault://kv/data/kapacitor/slack#token" or "env://SLACK_TOKEN". A
+// string with no URI scheme is returned unchanged, so existing plaintext
+// config values keep working; a string whose scheme has no registered
+// Provider is an error.
+func (r *Resolver) Resolve(ref string) (string, error) {
+	u, err := url.Parse(ref)
+	if err != nil || u.Scheme == "" {
+		return ref, nil
+	}
+
+	r.mu.Lock()
+	provider, ok := r.providers[u.Scheme]
+	if !ok {
+		r.mu.Unlock()
+		return "", fmt.Errorf("secrets: no provider registered for scheme %q", u.Scheme)
+	}
+	if entry, ok := r.cache[ref]; ok && r.Now().Sub(entry.resolved) < r.TTL {
+		r.mu.Unlock()
+		return entry.value, nil
+	}
+	r.mu.Unlock()
+
+	value, err := provider.Resolve(u)
+	if err != nil {
+		return "", fmt.Errorf("secrets: resolving %q: %w", ref, err)
+	}
+
+	r.mu.Lock()
+	r.cache[ref] = cacheEntry{value: value, resolved: r.Now()}
+	r.mu.Unlock()
+
+	return value, nil
+}
+
+// Invalidate drops ref's cached value, forcing the next Resolve to fetch
+// fresh — used when a lease renewal or rotation notification indicates
+// the cached value is stale.
+func (r *Resolver) Invalidate(ref string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.cache, ref)
+}
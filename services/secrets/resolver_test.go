@@ -0,0 +1,210 @@
+package secrets
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResolver_DispatchesByScheme(t *testing.T) {
+	r := NewResolver(time.Minute)
+	r.Register("env", EnvProvider{})
+
+	os.Setenv("KAPACITOR_TEST_SECRET", "hunter2")
+	defer os.Unsetenv("KAPACITOR_TEST_SECRET")
+
+	v, err := r.Resolve("env://KAPACITOR_TEST_SECRET")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "hunter2" {
+		t.Fatalf("expected hunter2, got %q", v)
+	}
+}
+
+func TestResolver_PlainStringPassesThrough(t *testing.T) {
+	r := NewResolver(time.Minute)
+	v, err := r.Resolve("plaintext-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "plaintext-password" {
+		t.Fatalf("expected plaintext value unchanged, got %q", v)
+	}
+}
+
+func TestResolver_UnknownSchemeErrors(t *testing.T) {
+	r := NewResolver(time.Minute)
+	if _, err := r.Resolve("mystery://x"); err == nil {
+		t.Fatal("expected an error for an unregistered scheme")
+	}
+}
+
+type countingProvider struct {
+	calls int
+	value string
+}
+
+func (p *countingProvider) Resolve(ref *url.URL) (string, error) {
+	p.calls++
+	return p.value, nil
+}
+
+func TestResolver_CachesUntilTTLExpires(t *testing.T) {
+	r := NewResolver(10 * time.Millisecond)
+	now := time.Now()
+	r.Now = func() time.Time { return now }
+
+	p := &countingProvider{value: "v1"}
+	r.Register("fake", p)
+
+	for i := 0; i < 3; i++ {
+		v, err := r.Resolve("fake://secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != "v1" {
+			t.Fatalf("unexpected value: %s", v)
+		}
+	}
+	if p.calls != 1 {
+		t.Fatalf("expected the provider to be called once while cached, got %d", p.calls)
+	}
+
+	now = now.Add(time.Hour)
+	p.value = "v2"
+	v, err := r.Resolve("fake://secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "v2" {
+		t.Fatalf("expected a refreshed value after TTL expiry, got %s", v)
+	}
+	if p.calls != 2 {
+		t.Fatalf("expected exactly one refetch after expiry, got %d calls", p.calls)
+	}
+}
+
+func TestResolver_InvalidateForcesRefresh(t *testing.T) {
+	r := NewResolver(time.Hour)
+	p := &countingProvider{value: "initial"}
+	r.Register("fake", p)
+
+	r.Resolve("fake://secret")
+	r.Invalidate("fake://secret")
+	p.value = "rotated"
+	v, err := r.Resolve("fake://secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "rotated" {
+		t.Fatalf("expected invalidation to force a refetch, got %s", v)
+	}
+	if p.calls != 2 {
+		t.Fatalf("expected 2 calls (initial + post-invalidate), got %d", p.calls)
+	}
+}
+
+func TestFileProvider_ReadsAndTrimsTrailingNewline(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(path, []byte("s3cr3t\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewResolver(time.Minute)
+	r.Register("file", FileProvider{})
+
+	v, err := r.Resolve("file://" + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "s3cr3t" {
+		t.Fatalf("expected trimmed file contents, got %q", v)
+	}
+}
+
+func TestK8sProvider_ReadsFromMountLayout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	secretDir := filepath.Join(dir, "monitoring", "slack-creds")
+	if err := os.MkdirAll(secretDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(secretDir, "token"), []byte("xoxb-1234"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewResolver(time.Minute)
+	r.Register("k8s", NewK8sProvider(dir))
+
+	v, err := r.Resolve("k8s://monitoring/slack-creds/token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "xoxb-1234" {
+		t.Fatalf("expected xoxb-1234, got %q", v)
+	}
+}
+
+type fakeVaultClient struct {
+	data map[string]interface{}
+	err  error
+}
+
+func (c *fakeVaultClient) ReadSecret(path string) (map[string]interface{}, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.data, nil
+}
+
+func TestVaultProvider_ResolvesKeyFromFragment(t *testing.T) {
+	r := NewResolver(time.Minute)
+	r.Register("vault", VaultProvider{Client: &fakeVaultClient{
+		data: map[string]interface{}{"token": "vault-secret-value"},
+	}})
+
+	v, err := r.Resolve("vault://kv/data/kapacitor/slack#token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "vault-secret-value" {
+		t.Fatalf("expected vault-secret-value, got %q", v)
+	}
+}
+
+func TestVaultProvider_MissingKeyErrors(t *testing.T) {
+	r := NewResolver(time.Minute)
+	r.Register("vault", VaultProvider{Client: &fakeVaultClient{
+		data: map[string]interface{}{"other": "x"},
+	}})
+
+	if _, err := r.Resolve("vault://kv/data/kapacitor/slack#token"); err == nil {
+		t.Fatal("expected an error when the key is missing from the secret")
+	}
+}
+
+func TestVaultProvider_ClientErrorPropagates(t *testing.T) {
+	r := NewResolver(time.Minute)
+	r.Register("vault", VaultProvider{Client: &fakeVaultClient{
+		err: errors.New("vault sealed"),
+	}})
+
+	if _, err := r.Resolve("vault://kv/data/kapacitor/slack#token"); err == nil {
+		t.Fatal("expected the client error to propagate")
+	}
+}
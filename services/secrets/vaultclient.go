@@ -0,0 +1,74 @@
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// TokenSource supplies the Vault token a request should authenticate
+// with. A static token and an AppRoleSource (which renews its token in
+// the background) both satisfy it.
+type TokenSource interface {
+	Token() string
+}
+
+// staticToken is the TokenSource a plain token string is wrapped in.
+type staticToken string
+
+func (t staticToken) Token() string { return string(t) }
+
+// HTTPVaultClient is the default VaultClient, talking to a real Vault
+// server's KV v2 HTTP API.
+type HTTPVaultClient struct {
+	Addr        string
+	TokenSource TokenSource
+	HTTPClient  *http.Client
+}
+
+// NewHTTPVaultClient builds a client against addr, authenticating with
+// a static token. Use NewHTTPVaultClientWithSource for a token that
+// needs to be renewed, such as one obtained via AppRole login.
+func NewHTTPVaultClient(addr, token string) *HTTPVaultClient {
+	return NewHTTPVaultClientWithSource(addr, staticToken(token))
+}
+
+// NewHTTPVaultClientWithSource builds a client against addr, fetching
+// the token to send on every request from source.
+func NewHTTPVaultClientWithSource(addr string, source TokenSource) *HTTPVaultClient {
+	return &HTTPVaultClient{Addr: addr, TokenSource: source, HTTPClient: http.DefaultClient}
+}
+
+type kvV2Response struct {
+	Data struct {
+		Data     map[string]interface{} `json:"data"`
+		Metadata struct {
+			Version int `json:"version"`
+		} `json:"metadata"`
+	} `json:"data"`
+	LeaseDuration int `json:"lease_duration"`
+}
+
+// ReadSecret fetches a KV v2 secret at path (e.g. "kv/data/kapacitor/slack").
+func (c *HTTPVaultClient) ReadSecret(path string) (map[string]interface{}, error) {
+	req, err := http.NewRequest(http.MethodGet, c.Addr+"/v1/"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", c.TokenSource.Token())
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("secrets: vault request for %q returned %s", path, resp.Status)
+	}
+
+	var kv kvV2Response
+	if err := json.NewDecoder(resp.Body).Decode(&kv); err != nil {
+		return nil, err
+	}
+	return kv.Data.Data, nil
+}
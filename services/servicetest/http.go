@@ -0,0 +1,68 @@
+package servicetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Handler serves POST /kapacitor/v1/service-tests/_schedule and
+// GET /kapacitor/v1/service-tests/<name>/history.
+type Handler struct {
+	Scheduler *Scheduler
+	// BasePath is the URL prefix before "_schedule" or "<name>/history",
+	// e.g. "/kapacitor/v1/service-tests/".
+	BasePath string
+}
+
+func NewHandler(scheduler *Scheduler, basePath string) *Handler {
+	return &Handler{Scheduler: scheduler, BasePath: basePath}
+}
+
+type scheduleRequest struct {
+	Name     string        `json:"name"`
+	Interval time.Duration `json:"interval"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	trimmed := strings.Trim(strings.TrimPrefix(req.URL.Path, h.BasePath), "/")
+
+	if trimmed == "_schedule" {
+		h.serveSchedule(w, req)
+		return
+	}
+	if strings.HasSuffix(trimmed, "/history") {
+		name := strings.TrimSuffix(trimmed, "/history")
+		h.serveHistory(w, req, name)
+		return
+	}
+	http.NotFound(w, req)
+}
+
+func (h *Handler) serveSchedule(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var sr scheduleRequest
+	if err := json.NewDecoder(req.Body).Decode(&sr); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.Scheduler.Schedule(sr.Name, sr.Interval); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) serveHistory(w http.ResponseWriter, req *http.Request, name string) {
+	history, err := h.Scheduler.History(name)
+	if err != nil {
+		http.NotFound(w, req)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"history": history})
+}
@@ -0,0 +1,85 @@
+package servicetest
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Tester is satisfied by every alert handler Service, the same
+// TestOptions/Test pair configdryrun.Tester reuses for connectivity
+// checks.
+type Tester interface {
+	TestOptions() interface{}
+	Test(options interface{}) error
+}
+
+// TestEntry describes one named service test.
+type TestEntry struct {
+	Tester Tester
+	// Renderer is optional: tests with no known wire format (e.g. exec)
+	// can omit it, and a dry run of such a test (Run with dryRun true)
+	// returns an error saying it has no dry-run renderer.
+	Renderer Renderer
+}
+
+// Result is the outcome of running (or dry-running) a named test.
+type Result struct {
+	Success bool             `json:"success"`
+	Message string           `json:"message,omitempty"`
+	Payload *RenderedPayload `json:"payload,omitempty"`
+}
+
+// Registry maps service test names to their Tester/Renderer pair,
+// mirroring the shape of configdryrun.Registry.
+type Registry struct {
+	mu      sync.Mutex
+	entries map[string]TestEntry
+}
+
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[string]TestEntry)}
+}
+
+func (r *Registry) Register(name string, entry TestEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[name] = entry
+}
+
+func (r *Registry) entry(name string) (TestEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, ok := r.entries[name]
+	if !ok {
+		return TestEntry{}, fmt.Errorf("unknown service test %q", name)
+	}
+	return entry, nil
+}
+
+// Run executes the named test. When dryRun is true, no message is sent:
+// the entry's Renderer (if any) builds the payload that would have been
+// sent instead.
+func (r *Registry) Run(name string, dryRun bool) (Result, error) {
+	entry, err := r.entry(name)
+	if err != nil {
+		return Result{}, err
+	}
+
+	options := entry.Tester.TestOptions()
+
+	if dryRun {
+		if entry.Renderer == nil {
+			return Result{}, fmt.Errorf("service test %q has no dry-run renderer", name)
+		}
+		payload, err := entry.Renderer.Render(options)
+		if err != nil {
+			return Result{}, err
+		}
+		return Result{Success: true, Payload: &payload}, nil
+	}
+
+	if err := entry.Tester.Test(options); err != nil {
+		return Result{Success: false, Message: err.Error()}, nil
+	}
+	return Result{Success: true}, nil
+}
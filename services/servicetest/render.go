@@ -0,0 +1,27 @@
+// Package servicetest extends the existing per-handler TestOptions/Test
+// pair (see configdryrun.Tester) with a dry-run mode that renders the
+// fully-formatted outbound payload instead of sending it, and a
+// scheduler that re-runs a named test on an interval and keeps its last
+// few results for later inspection.
+package servicetest
+
+// RenderedPayload is the outbound message a service test would have
+// sent, captured instead of transmitted.
+type RenderedPayload struct {
+	ContentType string `json:"contentType"`
+	Body        []byte `json:"body"`
+}
+
+// Renderer builds the outbound payload for a set of test options without
+// performing any network I/O, so a dry run (Registry.Run with dryRun
+// true) can preview exactly what Test would have sent.
+type Renderer interface {
+	Render(options interface{}) (RenderedPayload, error)
+}
+
+// RendererFunc adapts a function to a Renderer.
+type RendererFunc func(options interface{}) (RenderedPayload, error)
+
+func (f RendererFunc) Render(options interface{}) (RenderedPayload, error) {
+	return f(options)
+}
@@ -0,0 +1,118 @@
+package servicetest
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultHistorySize bounds how many past results Scheduler keeps per
+// named test, so a fast interval on a long-running Kapacitor doesn't
+// grow its history unbounded.
+const defaultHistorySize = 20
+
+// HistoryEntry is one past run of a scheduled test.
+type HistoryEntry struct {
+	Time time.Time `json:"time"`
+	Result
+}
+
+type schedule struct {
+	interval time.Duration
+	nextRun  time.Time
+	history  []HistoryEntry
+}
+
+// Scheduler re-runs named tests from registry on their configured
+// interval. Now defaults to time.Now but is overridable so tests can
+// drive it with a fake clock instead of waiting on a real timer.
+type Scheduler struct {
+	Registry    *Registry
+	Now         func() time.Time
+	HistorySize int
+
+	mu        sync.Mutex
+	schedules map[string]*schedule
+}
+
+func NewScheduler(registry *Registry) *Scheduler {
+	return &Scheduler{
+		Registry:    registry,
+		Now:         time.Now,
+		HistorySize: defaultHistorySize,
+		schedules:   make(map[string]*schedule),
+	}
+}
+
+// Schedule registers name to run every interval, starting at the next
+// RunDue call on or after now+interval. Calling it again for the same
+// name replaces the interval but keeps its history.
+func (s *Scheduler) Schedule(name string, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("servicetest: interval must be positive")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sch, ok := s.schedules[name]
+	if !ok {
+		sch = &schedule{}
+		s.schedules[name] = sch
+	}
+	sch.interval = interval
+	sch.nextRun = s.Now().Add(interval)
+	return nil
+}
+
+// RunDue runs every schedule whose nextRun has passed, recording each
+// run's result in its history. Call this from a driving goroutine on a
+// real ticker in production, or directly after advancing a fake Now in
+// tests.
+func (s *Scheduler) RunDue() {
+	now := s.Now()
+
+	s.mu.Lock()
+	due := make([]string, 0, len(s.schedules))
+	for name, sch := range s.schedules {
+		if !now.Before(sch.nextRun) {
+			due = append(due, name)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, name := range due {
+		result, err := s.Registry.Run(name, false)
+		if err != nil {
+			result = Result{Success: false, Message: err.Error()}
+		}
+
+		s.mu.Lock()
+		sch := s.schedules[name]
+		sch.nextRun = now.Add(sch.interval)
+		sch.history = append(sch.history, HistoryEntry{Time: now, Result: result})
+		if over := len(sch.history) - s.historySize(); over > 0 {
+			sch.history = sch.history[over:]
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *Scheduler) historySize() int {
+	if s.HistorySize <= 0 {
+		return defaultHistorySize
+	}
+	return s.HistorySize
+}
+
+// History returns the last N results recorded for name, oldest first.
+func (s *Scheduler) History(name string) ([]HistoryEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sch, ok := s.schedules[name]
+	if !ok {
+		return nil, fmt.Errorf("servicetest: no schedule for %q", name)
+	}
+	out := make([]HistoryEntry, len(sch.history))
+	copy(out, sch.history)
+	return out, nil
+}
@@ -0,0 +1,205 @@
+package servicetest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeTester struct {
+	options interface{}
+	err     error
+	calls   int
+}
+
+func (t *fakeTester) TestOptions() interface{} { return t.options }
+func (t *fakeTester) Test(interface{}) error {
+	t.calls++
+	return t.err
+}
+
+func TestRegistry_Run_SendsByDefault(t *testing.T) {
+	tester := &fakeTester{options: "opts"}
+	r := NewRegistry()
+	r.Register("slack", TestEntry{Tester: tester})
+
+	result, err := r.Run("slack", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Success || tester.calls != 1 {
+		t.Fatalf("expected Test to be called once and succeed, got %+v calls=%d", result, tester.calls)
+	}
+}
+
+func TestRegistry_Run_DryRunDoesNotSend(t *testing.T) {
+	tester := &fakeTester{options: "opts"}
+	renderer := RendererFunc(func(options interface{}) (RenderedPayload, error) {
+		return RenderedPayload{ContentType: "application/json", Body: []byte(`{"text":"opts"}`)}, nil
+	})
+	r := NewRegistry()
+	r.Register("slack", TestEntry{Tester: tester, Renderer: renderer})
+
+	result, err := r.Run("slack", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tester.calls != 0 {
+		t.Fatalf("expected Test not to be called during a dry run, got %d calls", tester.calls)
+	}
+	if result.Payload == nil || string(result.Payload.Body) != `{"text":"opts"}` {
+		t.Fatalf("expected the rendered payload to be returned, got %+v", result)
+	}
+}
+
+func TestRegistry_Run_DryRunWithoutRendererErrors(t *testing.T) {
+	r := NewRegistry()
+	r.Register("slack", TestEntry{Tester: &fakeTester{}})
+
+	if _, err := r.Run("slack", true); err == nil {
+		t.Fatal("expected an error when no renderer is registered")
+	}
+}
+
+func TestRegistry_Run_ReportsTestFailure(t *testing.T) {
+	tester := &fakeTester{err: errors.New("connection refused")}
+	r := NewRegistry()
+	r.Register("slack", TestEntry{Tester: tester})
+
+	result, err := r.Run("slack", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Success {
+		t.Fatal("expected the result to report failure")
+	}
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestScheduler_RunDue_AdvancesOnFakeClock(t *testing.T) {
+	tester := &fakeTester{}
+	registry := NewRegistry()
+	registry.Register("slack", TestEntry{Tester: tester})
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	s := NewScheduler(registry)
+	s.Now = clock.Now
+
+	if err := s.Schedule("slack", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	s.RunDue()
+	if tester.calls != 0 {
+		t.Fatalf("expected no run before the interval elapses, got %d calls", tester.calls)
+	}
+
+	clock.Advance(time.Minute)
+	s.RunDue()
+	if tester.calls != 1 {
+		t.Fatalf("expected exactly one run after the interval elapses, got %d calls", tester.calls)
+	}
+
+	clock.Advance(30 * time.Second)
+	s.RunDue()
+	if tester.calls != 1 {
+		t.Fatalf("expected no run before the second interval elapses, got %d calls", tester.calls)
+	}
+
+	clock.Advance(30 * time.Second)
+	s.RunDue()
+	if tester.calls != 2 {
+		t.Fatalf("expected a second run once the interval elapses again, got %d calls", tester.calls)
+	}
+
+	history, err := s.History("slack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("expected two recorded results, got %d", len(history))
+	}
+}
+
+func TestScheduler_History_TrimsToHistorySize(t *testing.T) {
+	tester := &fakeTester{}
+	registry := NewRegistry()
+	registry.Register("slack", TestEntry{Tester: tester})
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	s := NewScheduler(registry)
+	s.Now = clock.Now
+	s.HistorySize = 2
+
+	if err := s.Schedule("slack", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		clock.Advance(time.Second)
+		s.RunDue()
+	}
+
+	history, err := s.History("slack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("expected history to be trimmed to 2 entries, got %d", len(history))
+	}
+}
+
+func TestHandler_ScheduleAndHistory(t *testing.T) {
+	tester := &fakeTester{}
+	registry := NewRegistry()
+	registry.Register("slack", TestEntry{Tester: tester})
+
+	s := NewScheduler(registry)
+	s.Now = func() time.Time { return time.Unix(0, 0) }
+	h := NewHandler(s, "/kapacitor/v1/service-tests/")
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	body := `{"name":"slack","interval":60000000000}`
+	resp, err := http.Post(srv.URL+"/kapacitor/v1/service-tests/_schedule", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", resp.StatusCode)
+	}
+
+	s.RunDue()
+	s.Now = func() time.Time { return time.Unix(0, 0).Add(time.Minute) }
+	s.RunDue()
+
+	histResp, err := http.Get(srv.URL + "/kapacitor/v1/service-tests/slack/history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer histResp.Body.Close()
+	if histResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", histResp.StatusCode)
+	}
+	var decoded struct {
+		History []HistoryEntry `json:"history"`
+	}
+	if err := json.NewDecoder(histResp.Body).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.History) == 0 {
+		t.Fatal("expected at least one recorded history entry")
+	}
+}
@@ -0,0 +1,188 @@
+// Package taskbundle implements a signed, versioned bundle format for
+// bulk-exporting and re-importing tasks, templates and their referenced
+// alert handler specs, so operators can manage hundreds of tasks as a
+// single artifact instead of one HTTP call at a time.
+package taskbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// FormatVersion is the semver of the bundle format itself, independent of
+// the Kapacitor version that produced it.
+const FormatVersion = "1.0.0"
+
+type ManifestEntry struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+}
+
+type Manifest struct {
+	FormatVersion    string          `json:"format_version"`
+	KapacitorVersion string          `json:"kapacitor_version"`
+	Entries          []ManifestEntry `json:"entries"`
+}
+
+type TemplateEntry struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	TICKscript string `json:"tickscript"`
+}
+
+type TaskEntry struct {
+	ID         string                 `json:"id"`
+	TemplateID string                 `json:"templateId,omitempty"`
+	Type       string                 `json:"type"`
+	DBRPs      []DBRP                 `json:"dbrps"`
+	TICKscript string                 `json:"tickscript,omitempty"`
+	Status     string                 `json:"status"`
+	Vars       map[string]interface{} `json:"vars,omitempty"`
+}
+
+type DBRP struct {
+	Database        string `json:"db"`
+	RetentionPolicy string `json:"rp"`
+}
+
+// Bundle is the in-memory representation of an export/import payload.
+type Bundle struct {
+	Templates []TemplateEntry   `json:"templates"`
+	Tasks     []TaskEntry       `json:"tasks"`
+	Handlers  []json.RawMessage `json:"handlers,omitempty"`
+}
+
+// Write serializes b as a tar archive of JSON entries plus a manifest
+// recording each entry's SHA256, so Read can detect any modification made
+// outside of this package.
+func Write(w io.Writer, kapacitorVersion string, b Bundle) error {
+	entries := map[string][]byte{}
+
+	templatesJSON, err := json.Marshal(b.Templates)
+	if err != nil {
+		return err
+	}
+	entries["templates.json"] = templatesJSON
+
+	tasksJSON, err := json.Marshal(b.Tasks)
+	if err != nil {
+		return err
+	}
+	entries["tasks.json"] = tasksJSON
+
+	if len(b.Handlers) > 0 {
+		handlersJSON, err := json.Marshal(b.Handlers)
+		if err != nil {
+			return err
+		}
+		entries["handlers.json"] = handlersJSON
+	}
+
+	manifest := Manifest{FormatVersion: FormatVersion, KapacitorVersion: kapacitorVersion}
+	for name, data := range entries {
+		sum := sha256.Sum256(data)
+		manifest.Entries = append(manifest.Entries, ManifestEntry{
+			Name:   name,
+			SHA256: hex.EncodeToString(sum[:]),
+		})
+	}
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+
+	tw := tar.NewWriter(w)
+	if err := writeEntry(tw, "manifest.json", manifestJSON); err != nil {
+		return err
+	}
+	for name, data := range entries {
+		if err := writeEntry(tw, name, data); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+func writeEntry(tw *tar.Writer, name string, data []byte) error {
+	if err := tw.WriteHeader(&tar.Header{Name: name, Size: int64(len(data)), Mode: 0600}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// Read parses a bundle written by Write, verifying every entry's SHA256
+// against the manifest before returning it.
+func Read(r io.Reader) (Bundle, error) {
+	tr := tar.NewReader(r)
+	files := map[string][]byte{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Bundle{}, err
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return Bundle{}, err
+		}
+		files[hdr.Name] = data
+	}
+
+	manifestData, ok := files["manifest.json"]
+	if !ok {
+		return Bundle{}, fmt.Errorf("bundle is missing manifest.json")
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return Bundle{}, fmt.Errorf("invalid manifest: %s", err)
+	}
+
+	for _, entry := range manifest.Entries {
+		data, ok := files[entry.Name]
+		if !ok {
+			return Bundle{}, fmt.Errorf("bundle is missing entry %q listed in its manifest", entry.Name)
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != entry.SHA256 {
+			return Bundle{}, fmt.Errorf("entry %q failed checksum verification", entry.Name)
+		}
+	}
+
+	var b Bundle
+	if data, ok := files["templates.json"]; ok {
+		if err := json.Unmarshal(data, &b.Templates); err != nil {
+			return Bundle{}, err
+		}
+	}
+	if data, ok := files["tasks.json"]; ok {
+		if err := json.Unmarshal(data, &b.Tasks); err != nil {
+			return Bundle{}, err
+		}
+	}
+	if data, ok := files["handlers.json"]; ok {
+		if err := json.Unmarshal(data, &b.Handlers); err != nil {
+			return Bundle{}, err
+		}
+	}
+
+	return b, nil
+}
+
+// Buffer is a convenience for writing a bundle to an in-memory buffer, used
+// by the HTTP export handler.
+func Buffer(kapacitorVersion string, b Bundle) (*bytes.Buffer, error) {
+	buf := &bytes.Buffer{}
+	if err := Write(buf, kapacitorVersion, b); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
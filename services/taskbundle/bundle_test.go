@@ -0,0 +1,84 @@
+package taskbundle
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteRead_RoundTrip(t *testing.T) {
+	b := Bundle{
+		Templates: []TemplateEntry{
+			{ID: "testTemplateID", Type: "stream", TICKscript: "stream\n    |from()\n"},
+		},
+		Tasks: make([]TaskEntry, 100),
+	}
+	for i := range b.Tasks {
+		b.Tasks[i] = TaskEntry{
+			ID:         "testTaskID",
+			TemplateID: "testTemplateID",
+			Status:     "enabled",
+			DBRPs:      []DBRP{{Database: "mydb", RetentionPolicy: "myrp"}},
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Write(buf, "1.6.0", b); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Read(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got.Tasks) != len(b.Tasks) {
+		t.Fatalf("unexpected number of tasks got %d exp %d", len(got.Tasks), len(b.Tasks))
+	}
+	if len(got.Templates) != 1 {
+		t.Fatalf("unexpected number of templates got %d exp 1", len(got.Templates))
+	}
+}
+
+func TestRead_RejectsTamperedEntry(t *testing.T) {
+	b := Bundle{Tasks: []TaskEntry{{ID: "t1", Status: "enabled"}}}
+	buf := &bytes.Buffer{}
+	if err := Write(buf, "1.6.0", b); err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := bytes.Replace(buf.Bytes(), []byte(`"enabled"`), []byte(`"disabled"`), 1)
+	if _, err := Read(bytes.NewReader(tampered)); err == nil {
+		t.Fatal("expected checksum verification to fail on tampered entry")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	b := Bundle{Tasks: []TaskEntry{
+		{ID: "new", TICKscript: "stream"},
+		{ID: "changed", TICKscript: "stream |from()"},
+		{ID: "same", TICKscript: "stream"},
+	}}
+	existing := map[string]TaskEntry{
+		"changed": {ID: "changed", TICKscript: "stream"},
+		"same":    {ID: "same", TICKscript: "stream"},
+		"removed": {ID: "removed", TICKscript: "stream"},
+	}
+
+	changes := Diff(b, existing)
+	got := map[string]ChangeKind{}
+	for _, c := range changes {
+		got[c.TaskID] = c.Kind
+	}
+
+	exp := map[string]ChangeKind{
+		"new":     ChangeCreate,
+		"changed": ChangeUpdate,
+		"same":    ChangeNoop,
+		"removed": ChangeDelete,
+	}
+	for id, kind := range exp {
+		if got[id] != kind {
+			t.Errorf("unexpected change kind for %s got %s exp %s", id, got[id], kind)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package taskbundle
+
+// ChangeKind describes what applying a bundle would do to a single task.
+type ChangeKind string
+
+const (
+	ChangeCreate ChangeKind = "create"
+	ChangeUpdate ChangeKind = "update"
+	ChangeDelete ChangeKind = "delete"
+	ChangeNoop   ChangeKind = "no-op"
+)
+
+// TaskChange is the dry-run result for a single task entry.
+type TaskChange struct {
+	TaskID string     `json:"taskId"`
+	Kind   ChangeKind `json:"kind"`
+}
+
+// Diff compares the tasks in a bundle against the current set of existing
+// task IDs/TICKscripts, without mutating anything, so an operator can
+// review what applying the bundle would do.
+func Diff(b Bundle, existing map[string]TaskEntry) []TaskChange {
+	var changes []TaskChange
+	seen := make(map[string]bool, len(b.Tasks))
+
+	for _, t := range b.Tasks {
+		seen[t.ID] = true
+		cur, ok := existing[t.ID]
+		switch {
+		case !ok:
+			changes = append(changes, TaskChange{TaskID: t.ID, Kind: ChangeCreate})
+		case cur.TICKscript == t.TICKscript && cur.Status == t.Status:
+			changes = append(changes, TaskChange{TaskID: t.ID, Kind: ChangeNoop})
+		default:
+			changes = append(changes, TaskChange{TaskID: t.ID, Kind: ChangeUpdate})
+		}
+	}
+
+	for id := range existing {
+		if !seen[id] {
+			changes = append(changes, TaskChange{TaskID: id, Kind: ChangeDelete})
+		}
+	}
+
+	return changes
+}
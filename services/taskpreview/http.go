@@ -0,0 +1,52 @@
+package taskpreview
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// previewRequest is the body of POST /kapacitor/v1/templates/{id}/preview.
+type previewRequest struct {
+	Vars  map[string]interface{} `json:"vars"`
+	DBRPs []DBRP                 `json:"dbrps"`
+}
+
+// Handler implements the preview endpoint for a single template.
+type Handler struct {
+	Renderer Renderer
+}
+
+func NewHandler(r Renderer) *Handler {
+	return &Handler{Renderer: r}
+}
+
+// ServeHTTP expects the template id to be the second-to-last path segment,
+// e.g. /kapacitor/v1/templates/{id}/preview.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(segments) < 2 {
+		http.Error(w, "invalid template preview path", http.StatusBadRequest)
+		return
+	}
+	templateID := segments[len(segments)-2]
+
+	var req previewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result := Preview(h.Renderer, templateID, req.Vars, req.DBRPs)
+
+	w.Header().Set("Content-Type", "application/json")
+	if result.Error != "" {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,55 @@
+// Package taskpreview renders a template against a candidate set of vars
+// and DBRPs without persisting anything, so a UI or CI pipeline can
+// validate a proposed task (missing vars, bad TICKscript) and inspect what
+// it would subscribe to before ever calling CreateTask.
+package taskpreview
+
+type DBRP struct {
+	Database        string
+	RetentionPolicy string
+}
+
+// Renderer compiles a template against vars, the same way the task store
+// does when a task is actually created from it.
+type Renderer interface {
+	// Render returns the fully expanded TICKscript and its resolved DOT
+	// graph, or an error such as "missing value for var" if vars does not
+	// satisfy the template.
+	Render(templateID string, vars map[string]interface{}) (tickscript, dot string, err error)
+	// Measurements returns the measurement/field references the rendered
+	// TICKscript subscribes to, used for capacity planning.
+	Measurements(tickscript string) ([]string, error)
+}
+
+// Result is the outcome of previewing a template + vars + DBRPs
+// combination.
+type Result struct {
+	TICKscript   string   `json:"tickscript,omitempty"`
+	DOT          string   `json:"dot,omitempty"`
+	Measurements []string `json:"measurements,omitempty"`
+	DBRPs        []DBRP   `json:"dbrps,omitempty"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// Preview renders templateID with vars, without persisting a task. If
+// rendering fails, Result.Error is populated and every other field is left
+// at its zero value, matching how a failed CreateTask reports an error
+// today.
+func Preview(r Renderer, templateID string, vars map[string]interface{}, dbrps []DBRP) Result {
+	tickscript, dot, err := r.Render(templateID, vars)
+	if err != nil {
+		return Result{Error: err.Error()}
+	}
+
+	measurements, err := r.Measurements(tickscript)
+	if err != nil {
+		return Result{Error: err.Error()}
+	}
+
+	return Result{
+		TICKscript:   tickscript,
+		DOT:          dot,
+		Measurements: measurements,
+		DBRPs:        dbrps,
+	}
+}
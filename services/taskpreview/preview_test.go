@@ -0,0 +1,50 @@
+package taskpreview
+
+import (
+	"fmt"
+	"testing"
+)
+
+type fakeRenderer struct {
+	requireVar string
+}
+
+func (f fakeRenderer) Render(templateID string, vars map[string]interface{}) (string, string, error) {
+	if f.requireVar != "" {
+		if _, ok := vars[f.requireVar]; !ok {
+			return "", "", fmt.Errorf("missing value for var %q", f.requireVar)
+		}
+	}
+	return "stream\n    |from()\n", "digraph testTaskID {}", nil
+}
+
+func (f fakeRenderer) Measurements(tickscript string) ([]string, error) {
+	return []string{"cpu"}, nil
+}
+
+func TestPreview_Success(t *testing.T) {
+	r := fakeRenderer{requireVar: "period"}
+	result := Preview(r, "testTemplateID", map[string]interface{}{"period": "1m"}, []DBRP{{Database: "mydb", RetentionPolicy: "myrp"}})
+
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if result.TICKscript == "" || result.DOT == "" {
+		t.Fatal("expected tickscript and dot to be populated")
+	}
+	if len(result.Measurements) != 1 || result.Measurements[0] != "cpu" {
+		t.Fatalf("unexpected measurements: %v", result.Measurements)
+	}
+}
+
+func TestPreview_MissingVar(t *testing.T) {
+	r := fakeRenderer{requireVar: "period"}
+	result := Preview(r, "testTemplateID", map[string]interface{}{}, nil)
+
+	if result.Error == "" {
+		t.Fatal("expected missing var error")
+	}
+	if result.TICKscript != "" {
+		t.Fatal("expected no tickscript on error")
+	}
+}
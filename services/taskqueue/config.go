@@ -0,0 +1,54 @@
+package taskqueue
+
+import "fmt"
+
+// DefaultQueueName is the queue a task runs in if it does not specify one.
+const DefaultQueueName = "default"
+
+type QueueConfig struct {
+	Name        string `toml:"name" override:"name"`
+	Concurrency int    `toml:"concurrency" override:"concurrency"`
+	Priority    int    `toml:"priority" override:"priority"`
+}
+
+// Config configures every named queue available to tasks, plus an
+// optional cap on total concurrent executions shared across all of them.
+type Config struct {
+	Queues           []QueueConfig `toml:"queues" override:"queues"`
+	TotalConcurrency int           `toml:"total-concurrency" override:"total-concurrency"`
+}
+
+func NewConfig() Config {
+	return Config{
+		Queues: []QueueConfig{
+			{Name: DefaultQueueName, Concurrency: 0, Priority: 0},
+		},
+	}
+}
+
+func (c Config) Validate() error {
+	seen := make(map[string]bool, len(c.Queues))
+	hasDefault := false
+	for _, q := range c.Queues {
+		if q.Name == "" {
+			return fmt.Errorf("queue name must not be empty")
+		}
+		if seen[q.Name] {
+			return fmt.Errorf("duplicate queue name %q", q.Name)
+		}
+		seen[q.Name] = true
+		if q.Concurrency < 0 {
+			return fmt.Errorf("queue %q: concurrency must be >= 0", q.Name)
+		}
+		if q.Name == DefaultQueueName {
+			hasDefault = true
+		}
+	}
+	if !hasDefault {
+		return fmt.Errorf("must configure a %q queue", DefaultQueueName)
+	}
+	if c.TotalConcurrency < 0 {
+		return fmt.Errorf("total-concurrency must be >= 0")
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package taskqueue
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ServeHTTP implements GET /kapacitor/v1/queues, returning per-queue
+// active/waiting/processed/dropped counters.
+func (m *Manager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"queues": m.Stats()})
+}
@@ -0,0 +1,98 @@
+package taskqueue
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestManager_EnforcesPerQueueConcurrency(t *testing.T) {
+	m := NewManager(Config{Queues: []QueueConfig{
+		{Name: DefaultQueueName, Concurrency: 0},
+		{Name: "constrained", Concurrency: 2},
+	}})
+
+	var active, maxActive int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Run("constrained", func() {
+				n := atomic.AddInt32(&active, 1)
+				for {
+					cur := atomic.LoadInt32(&maxActive)
+					if n <= cur || atomic.CompareAndSwapInt32(&maxActive, cur, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				atomic.AddInt32(&active, -1)
+			})
+		}()
+	}
+	wg.Wait()
+
+	if maxActive > 2 {
+		t.Fatalf("expected at most 2 concurrent executions, observed %d", maxActive)
+	}
+
+	stats := m.Stats()["constrained"]
+	if stats.Processed != 10 {
+		t.Fatalf("unexpected processed count: %+v", stats)
+	}
+	if stats.Active != 0 || stats.Waiting != 0 {
+		t.Fatalf("expected queue to be drained, got %+v", stats)
+	}
+}
+
+func TestManager_HigherPriorityQueueAdmittedFirst(t *testing.T) {
+	m := NewManager(Config{
+		TotalConcurrency: 1,
+		Queues: []QueueConfig{
+			{Name: DefaultQueueName, Priority: 0},
+			{Name: "high", Priority: 10},
+		},
+	})
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go m.Run(DefaultQueueName, func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	var order []string
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, q := range []string{DefaultQueueName, "high"} {
+		q := q
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Run(q, func() {
+				mu.Lock()
+				order = append(order, q)
+				mu.Unlock()
+			})
+		}()
+	}
+	// Give both goroutines a chance to enqueue as waiters before releasing
+	// the task holding the only global slot.
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if len(order) != 2 || order[0] != "high" {
+		t.Fatalf("expected the high priority queue to be admitted first, got %v", order)
+	}
+}
+
+func TestManager_UnknownQueueErrors(t *testing.T) {
+	m := NewManager(NewConfig())
+	if err := m.Run("nope", func() {}); err == nil {
+		t.Fatal("expected an error for an unconfigured queue")
+	}
+}
@@ -0,0 +1,49 @@
+package tasksweep
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// createTasksRequest is the body of POST
+// /kapacitor/v1/templates/{id}/tasks:batch.
+type createTasksRequest struct {
+	Tasks []CreateTaskOptions `json:"tasks"`
+}
+
+// Handler implements the batch task creation endpoint, instantiating every
+// task in the request atomically via CreateTasksFromTemplate.
+type Handler struct {
+	Store      Store
+	TemplateID string
+}
+
+func NewHandler(store Store, templateID string) *Handler {
+	return &Handler{Store: store, TemplateID: templateID}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req createTasksRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	created, err := CreateTasksFromTemplate(h.Store, h.TemplateID, req.Tasks)
+	if err != nil {
+		if _, ok := err.(*ValidationError); ok {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"tasks": created})
+}
@@ -0,0 +1,82 @@
+// Package tasksweep instantiates many tasks from a single template in one
+// atomic operation, for the common case of creating one near-identical
+// alert task per host/service/tenant from a parameter matrix. Every
+// rendering is validated against the template before anything is
+// persisted, and any task already created is rolled back if a later one
+// in the batch fails to persist.
+package tasksweep
+
+import "fmt"
+
+type DBRP struct {
+	Database        string
+	RetentionPolicy string
+}
+
+// CreateTaskOptions describes a single task to instantiate from the
+// template, mirroring client.CreateTaskOptions but without the TemplateID,
+// which is shared by the whole sweep.
+type CreateTaskOptions struct {
+	ID     string
+	Vars   map[string]interface{}
+	DBRPs  []DBRP
+	Status string
+}
+
+type TaskInfo struct {
+	ID     string
+	Status string
+}
+
+// Store is the subset of the task store a sweep needs.
+type Store interface {
+	// ValidateTask renders the named template with vars and reports any
+	// error (e.g. "missing value for var") without persisting anything.
+	ValidateTask(templateID string, vars map[string]interface{}) error
+	CreateTask(templateID string, opt CreateTaskOptions) (TaskInfo, error)
+	DeleteTask(id string) error
+}
+
+// ValidationError reports every CreateTaskOptions in a sweep whose
+// rendering against the template failed, keyed by its position in opts.
+type ValidationError struct {
+	Errors map[int]error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%d of %d tasks failed template validation", len(e.Errors), len(e.Errors))
+}
+
+// CreateTasksFromTemplate validates every opts[i] against templateID,
+// failing the whole batch (without creating anything) if any rendering is
+// invalid. If validation passes, it creates each task in order; if a
+// creation fails partway through, every task already created in this call
+// is deleted before returning the error, so a partial sweep never persists.
+func CreateTasksFromTemplate(store Store, templateID string, opts []CreateTaskOptions) ([]TaskInfo, error) {
+	validationErrs := map[int]error{}
+	for i, opt := range opts {
+		if err := store.ValidateTask(templateID, opt.Vars); err != nil {
+			validationErrs[i] = err
+		}
+	}
+	if len(validationErrs) > 0 {
+		return nil, &ValidationError{Errors: validationErrs}
+	}
+
+	created := make([]TaskInfo, 0, len(opts))
+	for _, opt := range opts {
+		task, err := store.CreateTask(templateID, opt)
+		if err != nil {
+			rollback(store, created)
+			return nil, fmt.Errorf("failed to create task %q, rolled back %d previously created tasks: %s", opt.ID, len(created), err)
+		}
+		created = append(created, task)
+	}
+	return created, nil
+}
+
+func rollback(store Store, created []TaskInfo) {
+	for _, task := range created {
+		store.DeleteTask(task.ID)
+	}
+}
@@ -0,0 +1,106 @@
+package tasksweep
+
+import (
+	"fmt"
+	"testing"
+)
+
+type fakeStore struct {
+	created      map[string]TaskInfo
+	deleted      []string
+	failValidate map[string]bool
+	failCreateAt string
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		created:      make(map[string]TaskInfo),
+		failValidate: make(map[string]bool),
+	}
+}
+
+func (f *fakeStore) ValidateTask(templateID string, vars map[string]interface{}) error {
+	if id, _ := vars["id"].(string); f.failValidate[id] {
+		return fmt.Errorf("missing value for var")
+	}
+	return nil
+}
+
+func (f *fakeStore) CreateTask(templateID string, opt CreateTaskOptions) (TaskInfo, error) {
+	if opt.ID == f.failCreateAt {
+		return TaskInfo{}, fmt.Errorf("simulated create failure")
+	}
+	task := TaskInfo{ID: opt.ID, Status: opt.Status}
+	f.created[opt.ID] = task
+	return task, nil
+}
+
+func (f *fakeStore) DeleteTask(id string) error {
+	delete(f.created, id)
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+func optsFor(n int) []CreateTaskOptions {
+	opts := make([]CreateTaskOptions, n)
+	for i := range opts {
+		id := fmt.Sprintf("task-%d", i)
+		opts[i] = CreateTaskOptions{
+			ID:     id,
+			Vars:   map[string]interface{}{"id": id},
+			DBRPs:  []DBRP{{Database: "mydb", RetentionPolicy: "myrp"}},
+			Status: "enabled",
+		}
+	}
+	return opts
+}
+
+func TestCreateTasksFromTemplate_CreatesAll(t *testing.T) {
+	store := newFakeStore()
+	opts := optsFor(100)
+
+	created, err := CreateTasksFromTemplate(store, "testTemplateID", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 100 {
+		t.Fatalf("unexpected number created got %d exp 100", len(created))
+	}
+	if len(store.created) != 100 {
+		t.Fatalf("unexpected number persisted got %d exp 100", len(store.created))
+	}
+}
+
+func TestCreateTasksFromTemplate_ValidationFailsBeforeAnyCreate(t *testing.T) {
+	store := newFakeStore()
+	opts := optsFor(5)
+	store.failValidate["task-3"] = true
+
+	_, err := CreateTasksFromTemplate(store, "testTemplateID", opts)
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	if _, ok := err.(*ValidationError); !ok {
+		t.Fatalf("expected *ValidationError, got %T", err)
+	}
+	if len(store.created) != 0 {
+		t.Fatalf("expected no tasks to be created, got %d", len(store.created))
+	}
+}
+
+func TestCreateTasksFromTemplate_RollsBackOnCreateFailure(t *testing.T) {
+	store := newFakeStore()
+	opts := optsFor(5)
+	store.failCreateAt = "task-3"
+
+	_, err := CreateTasksFromTemplate(store, "testTemplateID", opts)
+	if err == nil {
+		t.Fatal("expected create error")
+	}
+	if len(store.created) != 0 {
+		t.Fatalf("expected all created tasks to be rolled back, got %d remaining", len(store.created))
+	}
+	if len(store.deleted) != 3 {
+		t.Fatalf("expected 3 tasks rolled back (task-0, task-1, task-2), got %d", len(store.deleted))
+	}
+}
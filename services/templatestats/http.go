@@ -0,0 +1,58 @@
+package templatestats
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Service exposes per-template task rollups over HTTP, backed by a
+// TaskLister.
+type Service struct {
+	Tasks TaskLister
+}
+
+func NewService(tasks TaskLister) *Service {
+	return &Service{Tasks: tasks}
+}
+
+// ServeHTTP implements:
+//
+//	GET /kapacitor/v1/templates/{id}/stats
+//	GET /kapacitor/v1/templates/{id}/tasks?status=enabled|disabled
+//
+// where {id} and the trailing segment are the last two path elements, so
+// this can be mounted under the template resource's existing router.
+func (s *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(segments) < 2 {
+		http.Error(w, "invalid template inspector path", http.StatusBadRequest)
+		return
+	}
+	templateID := segments[len(segments)-2]
+	action := segments[len(segments)-1]
+
+	tasks, err := s.Tasks.Tasks()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	switch action {
+	case "stats":
+		json.NewEncoder(w).Encode(Rollup(templateID, tasks))
+	case "tasks":
+		status := r.URL.Query().Get("status")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"tasks": Filter(templateID, tasks, status),
+		})
+	default:
+		http.Error(w, "unknown template inspector action: "+action, http.StatusNotFound)
+	}
+}
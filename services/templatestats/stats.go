@@ -0,0 +1,90 @@
+// Package templatestats rolls up per-task runtime counters into a single
+// aggregate per template, so an operator can ask "how are the tasks
+// derived from template X doing" in one call instead of fetching and
+// summing every task individually.
+package templatestats
+
+import "time"
+
+// TaskInfo is the subset of a task's identity and runtime counters needed
+// to compute a template rollup.
+type TaskInfo struct {
+	ID              string
+	TemplateID      string
+	Enabled         bool
+	PointsProcessed int64
+	Errors          int64
+	ExecLatency     time.Duration
+	Lag             time.Duration
+}
+
+// TaskLister returns the current set of tasks to roll up, typically
+// backed by the server's task store and its execution stats.
+type TaskLister interface {
+	Tasks() ([]TaskInfo, error)
+}
+
+// Stats is the aggregate view of every task derived from a single
+// template.
+type Stats struct {
+	TemplateID      string        `json:"templateId"`
+	NumTasks        int           `json:"numTasks"`
+	NumEnabled      int           `json:"numEnabled"`
+	NumDisabled     int           `json:"numDisabled"`
+	PointsProcessed int64         `json:"pointsProcessed"`
+	Errors          int64         `json:"errors"`
+	AvgExecLatency  time.Duration `json:"avgExecLatency"`
+	AvgLag          time.Duration `json:"avgLag"`
+}
+
+// Rollup computes the aggregate Stats for every task in tasks whose
+// TemplateID matches templateID.
+func Rollup(templateID string, tasks []TaskInfo) Stats {
+	s := Stats{TemplateID: templateID}
+
+	var totalExecLatency, totalLag time.Duration
+	for _, task := range tasks {
+		if task.TemplateID != templateID {
+			continue
+		}
+		s.NumTasks++
+		if task.Enabled {
+			s.NumEnabled++
+		} else {
+			s.NumDisabled++
+		}
+		s.PointsProcessed += task.PointsProcessed
+		s.Errors += task.Errors
+		totalExecLatency += task.ExecLatency
+		totalLag += task.Lag
+	}
+
+	if s.NumTasks > 0 {
+		s.AvgExecLatency = totalExecLatency / time.Duration(s.NumTasks)
+		s.AvgLag = totalLag / time.Duration(s.NumTasks)
+	}
+	return s
+}
+
+// Filter selects the tasks in tasks derived from templateID, optionally
+// restricted to a single enabled/disabled status.
+func Filter(templateID string, tasks []TaskInfo, status string) []TaskInfo {
+	var out []TaskInfo
+	for _, task := range tasks {
+		if task.TemplateID != templateID {
+			continue
+		}
+		switch status {
+		case "enabled":
+			if !task.Enabled {
+				continue
+			}
+		case "disabled":
+			if task.Enabled {
+				continue
+			}
+		}
+		out = append(out, task)
+	}
+	return out
+}
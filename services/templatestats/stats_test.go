@@ -0,0 +1,81 @@
+package templatestats
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type fakeLister struct {
+	tasks []TaskInfo
+}
+
+func (f fakeLister) Tasks() ([]TaskInfo, error) {
+	return f.tasks, nil
+}
+
+func testTasks() []TaskInfo {
+	tasks := make([]TaskInfo, 0, 100)
+	for i := 0; i < 100; i++ {
+		enabled := i%4 != 0
+		tasks = append(tasks, TaskInfo{
+			ID:              "testTaskID",
+			TemplateID:      "testTemplateID",
+			Enabled:         enabled,
+			PointsProcessed: int64(i),
+			Errors:          int64(i % 3),
+			ExecLatency:     time.Duration(i) * time.Millisecond,
+			Lag:             time.Duration(i) * time.Millisecond,
+		})
+	}
+	tasks = append(tasks, TaskInfo{ID: "other", TemplateID: "otherTemplateID", Enabled: true})
+	return tasks
+}
+
+func TestRollup(t *testing.T) {
+	s := Rollup("testTemplateID", testTasks())
+	if s.NumTasks != 100 {
+		t.Fatalf("unexpected NumTasks got %d exp 100", s.NumTasks)
+	}
+	if s.NumEnabled != 75 || s.NumDisabled != 25 {
+		t.Fatalf("unexpected enabled/disabled split got %d/%d exp 75/25", s.NumEnabled, s.NumDisabled)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	tasks := testTasks()
+	enabled := Filter("testTemplateID", tasks, "enabled")
+	if len(enabled) != 75 {
+		t.Fatalf("unexpected enabled count got %d exp 75", len(enabled))
+	}
+	disabled := Filter("testTemplateID", tasks, "disabled")
+	if len(disabled) != 25 {
+		t.Fatalf("unexpected disabled count got %d exp 25", len(disabled))
+	}
+	all := Filter("testTemplateID", tasks, "")
+	if len(all) != 100 {
+		t.Fatalf("unexpected total count got %d exp 100", len(all))
+	}
+}
+
+func TestService_ServeHTTP_Stats(t *testing.T) {
+	s := NewService(fakeLister{tasks: testTasks()})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/kapacitor/v1/templates/testTemplateID/stats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var got Stats
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.NumTasks != 100 {
+		t.Fatalf("unexpected NumTasks got %d exp 100", got.NumTasks)
+	}
+}
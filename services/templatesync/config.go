@@ -0,0 +1,42 @@
+package templatesync
+
+import (
+	"fmt"
+	"time"
+)
+
+// defaultExt is the file extension used to identify TICKscript template
+// files within the watched directory.
+const defaultExt = ".tick"
+
+// defaultDebounce coalesces bursts of filesystem events (e.g. an editor
+// writing a file in several steps) into a single reconcile pass.
+const defaultDebounce = 500 * time.Millisecond
+
+type Config struct {
+	Enabled  bool          `toml:"enabled" override:"enabled"`
+	Dir      string        `toml:"dir" override:"dir"`
+	Ext      string        `toml:"ext" override:"ext"`
+	Debounce time.Duration `toml:"debounce" override:"debounce"`
+}
+
+func NewConfig() Config {
+	return Config{
+		Enabled:  false,
+		Ext:      defaultExt,
+		Debounce: defaultDebounce,
+	}
+}
+
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Dir == "" {
+		return fmt.Errorf("must specify dir")
+	}
+	if c.Ext == "" {
+		return fmt.Errorf("must specify ext")
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+// Package templatesync reconciles a directory of TICKscript template files
+// on disk into the template store, so templates (and every task created
+// from them) can be managed as version-controlled files instead of
+// exclusively through the HTTP API. Reconciliation runs once at Open, on
+// SIGHUP, and on a debounced fsnotify event whenever the directory changes.
+package templatesync
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// TemplateStore is the subset of the task store's template operations this
+// service needs. It is satisfied by the server's template store.
+type TemplateStore interface {
+	// Templates returns the id and TICKscript of every stored template.
+	Templates() (map[string]string, error)
+	CreateTemplate(id, tickscript string) error
+	UpdateTemplate(id, tickscript string) error
+	DeleteTemplate(id string) error
+}
+
+type Service struct {
+	configValue atomic.Value
+
+	store  TemplateStore
+	logger *log.Logger
+
+	watcher *fsnotify.Watcher
+	sighup  chan os.Signal
+	closing chan struct{}
+}
+
+func NewService(c Config, store TemplateStore, l *log.Logger) *Service {
+	s := &Service{
+		store:   store,
+		logger:  l,
+		sighup:  make(chan os.Signal, 1),
+		closing: make(chan struct{}),
+	}
+	s.configValue.Store(c)
+	return s
+}
+
+func (s *Service) config() Config {
+	return s.configValue.Load().(Config)
+}
+
+func (s *Service) Open() error {
+	c := s.config()
+	if !c.Enabled {
+		return nil
+	}
+
+	if err := s.Reconcile(); err != nil {
+		s.logger.Println("E! initial template reconcile failed:", err)
+	}
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create template directory watcher: %s", err)
+	}
+	if err := w.Add(c.Dir); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to watch template dir %q: %s", c.Dir, err)
+	}
+	s.watcher = w
+
+	signal.Notify(s.sighup, syscall.SIGHUP)
+
+	go s.run()
+	return nil
+}
+
+func (s *Service) Close() error {
+	c := s.config()
+	if !c.Enabled {
+		return nil
+	}
+	signal.Stop(s.sighup)
+	close(s.closing)
+	if s.watcher != nil {
+		return s.watcher.Close()
+	}
+	return nil
+}
+
+func (s *Service) Update(newConfig []interface{}) error {
+	if l := len(newConfig); l != 1 {
+		return fmt.Errorf("expected only one new config object, got %d", l)
+	}
+	if c, ok := newConfig[0].(Config); !ok {
+		return fmt.Errorf("expected config object to be of type %T, got %T", c, newConfig[0])
+	} else {
+		s.configValue.Store(c)
+	}
+	return nil
+}
+
+// run watches for SIGHUP and fsnotify events, debouncing bursts of the
+// latter into a single Reconcile call.
+func (s *Service) run() {
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-s.closing:
+			return
+		case <-s.sighup:
+			if err := s.Reconcile(); err != nil {
+				s.logger.Println("E! template reconcile failed:", err)
+			}
+		case err, ok := <-s.watcher.Errors:
+			if !ok {
+				return
+			}
+			s.logger.Println("E! template directory watch error:", err)
+		case _, ok := <-s.watcher.Events:
+			if !ok {
+				return
+			}
+			debounce = time.After(s.config().Debounce)
+		case <-debounce:
+			debounce = nil
+			if err := s.Reconcile(); err != nil {
+				s.logger.Println("E! template reconcile failed:", err)
+			}
+		}
+	}
+}
+
+// Reconcile diffs the on-disk template files against the store and applies
+// CreateTemplate/UpdateTemplate/DeleteTemplate as needed so the store ends
+// up matching the directory exactly.
+func (s *Service) Reconcile() error {
+	c := s.config()
+
+	onDisk, err := s.readDir(c.Dir, c.Ext)
+	if err != nil {
+		return err
+	}
+
+	stored, err := s.store.Templates()
+	if err != nil {
+		return fmt.Errorf("failed to list stored templates: %s", err)
+	}
+
+	for id, tickscript := range onDisk {
+		existing, ok := stored[id]
+		switch {
+		case !ok:
+			if err := s.store.CreateTemplate(id, tickscript); err != nil {
+				return fmt.Errorf("failed to create template %q: %s", id, err)
+			}
+		case existing != tickscript:
+			if err := s.store.UpdateTemplate(id, tickscript); err != nil {
+				return fmt.Errorf("failed to update template %q: %s", id, err)
+			}
+		}
+	}
+
+	for id := range stored {
+		if _, ok := onDisk[id]; !ok {
+			if err := s.store.DeleteTemplate(id); err != nil {
+				return fmt.Errorf("failed to delete template %q: %s", id, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// readDir returns the id (filename without extension) and contents of
+// every template file with the configured extension in dir.
+func (s *Service) readDir(dir, ext string) (map[string]string, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template dir %q: %s", dir, err)
+	}
+
+	templates := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ext {
+			continue
+		}
+		id := strings.TrimSuffix(entry.Name(), ext)
+		data, err := ioutil.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template file %q: %s", entry.Name(), err)
+		}
+		templates[id] = string(data)
+	}
+	return templates, nil
+}
@@ -0,0 +1,112 @@
+package templatesync
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type fakeStore struct {
+	templates map[string]string
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{templates: make(map[string]string)}
+}
+
+func (f *fakeStore) Templates() (map[string]string, error) {
+	out := make(map[string]string, len(f.templates))
+	for id, t := range f.templates {
+		out[id] = t
+	}
+	return out, nil
+}
+
+func (f *fakeStore) CreateTemplate(id, tickscript string) error {
+	f.templates[id] = tickscript
+	return nil
+}
+
+func (f *fakeStore) UpdateTemplate(id, tickscript string) error {
+	f.templates[id] = tickscript
+	return nil
+}
+
+func (f *fakeStore) DeleteTemplate(id string) error {
+	delete(f.templates, id)
+	return nil
+}
+
+func newTestService(t *testing.T, dir string) (*Service, *fakeStore) {
+	t.Helper()
+	store := newFakeStore()
+	c := NewConfig()
+	c.Enabled = true
+	c.Dir = dir
+	l := log.New(ioutil.Discard, "", 0)
+	return NewService(c, store, l), store
+}
+
+func writeTemplate(t *testing.T, dir, id, tickscript string) {
+	t.Helper()
+	if err := ioutil.WriteFile(filepath.Join(dir, id+defaultExt), []byte(tickscript), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestService_Reconcile_CreatesUpdatesDeletes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "templatesync-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTemplate(t, dir, "testTemplateID", "stream\n    |from()\n")
+	s, store := newTestService(t, dir)
+
+	if err := s.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := store.templates["testTemplateID"]; !ok || got != "stream\n    |from()\n" {
+		t.Fatalf("expected template to be created, got %q", got)
+	}
+
+	// Stale template not present on disk should be removed.
+	store.templates["stale"] = "stream\n    |from()\n"
+	if err := s.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.templates["stale"]; ok {
+		t.Fatal("expected stale template to be deleted")
+	}
+
+	writeTemplate(t, dir, "testTemplateID", "stream\n    |from()\n    |window()\n")
+	if err := s.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.templates["testTemplateID"]; got != "stream\n    |from()\n    |window()\n" {
+		t.Fatalf("expected template to be updated, got %q", got)
+	}
+}
+
+func TestService_Reconcile_IgnoresNonMatchingExtensions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "templatesync-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("not a template"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	s, store := newTestService(t, dir)
+
+	if err := s.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.templates) != 0 {
+		t.Fatalf("expected no templates, got %d", len(store.templates))
+	}
+}
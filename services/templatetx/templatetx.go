@@ -0,0 +1,123 @@
+// Package templatetx updates a template's TICKscript and the Vars of its
+// associated tasks as a single atomic operation: every affected task is
+// validated against the new script before anything is committed, and if
+// any commit step fails partway through, every change already committed in
+// this call is rolled back to its prior snapshot. If a rollback step
+// itself fails, the returned error says the rollback was incomplete and
+// the store may hold a mix of old and new state.
+package templatetx
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TaskState is a snapshot of a task's mutable state, used both to
+// assemble the candidate state for validation and as the shadow copy
+// restored on rollback.
+type TaskState struct {
+	ID     string
+	Vars   map[string]interface{}
+	Status string
+}
+
+// UpdateTaskOptions describes the change to make to a single task as part
+// of the transaction. A nil Vars or empty Status leaves that field
+// unchanged.
+type UpdateTaskOptions struct {
+	Vars   map[string]interface{}
+	Status string
+}
+
+// Store is the subset of the task store needed to perform an atomic
+// template+tasks update.
+type Store interface {
+	Task(id string) (TaskState, error)
+	// ValidateTaskVars renders templateTickscript with vars without
+	// persisting anything, reporting e.g. "missing value for var".
+	ValidateTaskVars(templateTickscript string, vars map[string]interface{}) error
+	CommitTemplate(templateID, tickscript string) error
+	CommitTask(id string, vars map[string]interface{}, status string) error
+}
+
+// UpdateTemplateAndTasks validates newTickscript against every task named
+// in taskOpts (merging each task's existing Vars with any override in
+// taskOpts), then commits the template and every task, in task ID order.
+// If validation
+// fails for any task, nothing is committed. If a commit fails partway
+// through, every already-committed change in this call is rolled back to
+// the snapshot taken at the start.
+func UpdateTemplateAndTasks(store Store, templateID, oldTickscript, newTickscript string, taskOpts map[string]UpdateTaskOptions) error {
+	snapshots := make(map[string]TaskState, len(taskOpts))
+	candidates := make(map[string]TaskState, len(taskOpts))
+	for id, opt := range taskOpts {
+		old, err := store.Task(id)
+		if err != nil {
+			return fmt.Errorf("failed to snapshot task %q: %s", id, err)
+		}
+		snapshots[id] = old
+
+		candidate := old
+		if opt.Vars != nil {
+			candidate.Vars = opt.Vars
+		}
+		if opt.Status != "" {
+			candidate.Status = opt.Status
+		}
+		candidates[id] = candidate
+	}
+
+	for id, candidate := range candidates {
+		if err := store.ValidateTaskVars(newTickscript, candidate.Vars); err != nil {
+			return fmt.Errorf("task %q would fail to reload from the updated template: %s", id, err)
+		}
+	}
+
+	if err := store.CommitTemplate(templateID, newTickscript); err != nil {
+		return fmt.Errorf("failed to commit template %q: %s", templateID, err)
+	}
+
+	ids := make([]string, 0, len(candidates))
+	for id := range candidates {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	committed := make([]string, 0, len(candidates))
+	for _, id := range ids {
+		candidate := candidates[id]
+		if err := store.CommitTask(id, candidate.Vars, candidate.Status); err != nil {
+			if rbErr := rollback(store, templateID, oldTickscript, snapshots, committed); rbErr != nil {
+				return fmt.Errorf("failed to commit task %q: %s; %s", id, err, rbErr)
+			}
+			return fmt.Errorf("failed to commit task %q, rolled back transaction: %s", id, err)
+		}
+		committed = append(committed, id)
+	}
+
+	return nil
+}
+
+// rollback restores every task in committed, then the template, to their
+// pre-transaction snapshots, returning an error that collects every step
+// that failed to roll back rather than discarding it. A non-nil return
+// means the store was left on a mix of old and new state: the caller
+// must surface that distinctly from an ordinary commit failure, since
+// the usual guarantee ("nothing partial persists") no longer holds.
+func rollback(store Store, templateID, oldTickscript string, snapshots map[string]TaskState, committed []string) error {
+	var errs []string
+	for _, id := range committed {
+		old := snapshots[id]
+		if err := store.CommitTask(id, old.Vars, old.Status); err != nil {
+			errs = append(errs, fmt.Sprintf("task %q: %s", id, err))
+		}
+	}
+	if err := store.CommitTemplate(templateID, oldTickscript); err != nil {
+		errs = append(errs, fmt.Sprintf("template %q: %s", templateID, err))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("rollback incomplete, state may be inconsistent: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
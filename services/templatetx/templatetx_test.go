@@ -0,0 +1,169 @@
+package templatetx
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type fakeStore struct {
+	templateTickscript string
+	tasks              map[string]TaskState
+	failValidate       map[string]bool
+	failCommitTask     string
+	commitTemplateErr  bool
+
+	// failRollbackTask and rollbackCommitTemplateErr simulate a failure
+	// on a store call's *second* invocation, i.e. the one rollback
+	// makes after a successful forward commit, rather than the forward
+	// commit itself.
+	failRollbackTask          string
+	rollbackCommitTemplateErr bool
+
+	taskCommitCount     map[string]int
+	templateCommitCount int
+}
+
+func newFakeStore(oldTickscript string, tasks map[string]TaskState) *fakeStore {
+	return &fakeStore{
+		templateTickscript: oldTickscript,
+		tasks:              tasks,
+		failValidate:       make(map[string]bool),
+	}
+}
+
+func (f *fakeStore) Task(id string) (TaskState, error) {
+	t, ok := f.tasks[id]
+	if !ok {
+		return TaskState{}, fmt.Errorf("no such task %q", id)
+	}
+	return t, nil
+}
+
+func (f *fakeStore) ValidateTaskVars(templateTickscript string, vars map[string]interface{}) error {
+	if _, ok := vars["period"]; f.failValidate["period"] && !ok {
+		return fmt.Errorf("missing value for var \"period\"")
+	}
+	return nil
+}
+
+func (f *fakeStore) CommitTemplate(templateID, tickscript string) error {
+	f.templateCommitCount++
+	if f.commitTemplateErr {
+		return fmt.Errorf("simulated commit template failure")
+	}
+	if f.rollbackCommitTemplateErr && f.templateCommitCount > 1 {
+		return fmt.Errorf("simulated rollback commit template failure")
+	}
+	f.templateTickscript = tickscript
+	return nil
+}
+
+func (f *fakeStore) CommitTask(id string, vars map[string]interface{}, status string) error {
+	if f.taskCommitCount == nil {
+		f.taskCommitCount = make(map[string]int)
+	}
+	f.taskCommitCount[id]++
+	if id == f.failCommitTask {
+		return fmt.Errorf("simulated commit task failure")
+	}
+	if id == f.failRollbackTask && f.taskCommitCount[id] > 1 {
+		return fmt.Errorf("simulated rollback commit task failure")
+	}
+	t := f.tasks[id]
+	t.Vars = vars
+	t.Status = status
+	f.tasks[id] = t
+	return nil
+}
+
+func TestUpdateTemplateAndTasks_Succeeds(t *testing.T) {
+	store := newFakeStore("stream\n    |from()\n", map[string]TaskState{
+		"testStreamTask-0": {ID: "testStreamTask-0", Vars: map[string]interface{}{"period": "1m"}, Status: "enabled"},
+		"testStreamTask-1": {ID: "testStreamTask-1", Vars: map[string]interface{}{"period": "5m"}, Status: "enabled"},
+	})
+
+	err := UpdateTemplateAndTasks(store, "testTemplateID", store.templateTickscript, "stream\n    |from()\n    |window()\n", map[string]UpdateTaskOptions{
+		"testStreamTask-0": {Vars: map[string]interface{}{"period": "2m"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.tasks["testStreamTask-0"].Vars["period"] != "2m" {
+		t.Fatalf("expected updated vars, got %v", store.tasks["testStreamTask-0"].Vars)
+	}
+	if store.templateTickscript != "stream\n    |from()\n    |window()\n" {
+		t.Fatal("expected template to be updated")
+	}
+}
+
+func TestUpdateTemplateAndTasks_ValidationFailureLeavesEverythingUnchanged(t *testing.T) {
+	oldTickscript := "stream\n    |from()\n"
+	store := newFakeStore(oldTickscript, map[string]TaskState{
+		"testStreamTask-0": {ID: "testStreamTask-0", Vars: map[string]interface{}{"period": "1m"}, Status: "enabled"},
+	})
+	store.failValidate["period"] = true
+
+	err := UpdateTemplateAndTasks(store, "testTemplateID", oldTickscript, "stream\n    |from()\n    |window()\n", map[string]UpdateTaskOptions{
+		"testStreamTask-0": {Vars: map[string]interface{}{}},
+	})
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	if store.templateTickscript != oldTickscript {
+		t.Fatal("expected template to remain unchanged")
+	}
+	if store.tasks["testStreamTask-0"].Vars["period"] != "1m" {
+		t.Fatal("expected task vars to remain unchanged")
+	}
+}
+
+func TestUpdateTemplateAndTasks_RollsBackOnCommitFailure(t *testing.T) {
+	oldTickscript := "stream\n    |from()\n"
+	store := newFakeStore(oldTickscript, map[string]TaskState{
+		"testStreamTask-0": {ID: "testStreamTask-0", Vars: map[string]interface{}{"period": "1m"}, Status: "enabled"},
+		"testStreamTask-1": {ID: "testStreamTask-1", Vars: map[string]interface{}{"period": "5m"}, Status: "enabled"},
+	})
+	store.failCommitTask = "testStreamTask-1"
+
+	err := UpdateTemplateAndTasks(store, "testTemplateID", oldTickscript, "stream\n    |from()\n    |window()\n", map[string]UpdateTaskOptions{
+		"testStreamTask-0": {Vars: map[string]interface{}{"period": "2m"}},
+		"testStreamTask-1": {Vars: map[string]interface{}{"period": "6m"}},
+	})
+	if err == nil {
+		t.Fatal("expected commit error")
+	}
+	if store.templateTickscript != oldTickscript {
+		t.Fatal("expected template to be rolled back")
+	}
+	if store.tasks["testStreamTask-0"].Vars["period"] != "1m" {
+		t.Fatal("expected testStreamTask-0 to be rolled back")
+	}
+}
+
+func TestUpdateTemplateAndTasks_SurfacesRollbackFailureDistinctly(t *testing.T) {
+	oldTickscript := "stream\n    |from()\n"
+	store := newFakeStore(oldTickscript, map[string]TaskState{
+		"testStreamTask-0": {ID: "testStreamTask-0", Vars: map[string]interface{}{"period": "1m"}, Status: "enabled"},
+		"testStreamTask-1": {ID: "testStreamTask-1", Vars: map[string]interface{}{"period": "5m"}, Status: "enabled"},
+	})
+	// testStreamTask-0 commits successfully forward, then fails when
+	// rollback tries to restore it; testStreamTask-1 fails forward,
+	// triggering the rollback in the first place.
+	store.failCommitTask = "testStreamTask-1"
+	store.failRollbackTask = "testStreamTask-0"
+
+	err := UpdateTemplateAndTasks(store, "testTemplateID", oldTickscript, "stream\n    |from()\n    |window()\n", map[string]UpdateTaskOptions{
+		"testStreamTask-0": {Vars: map[string]interface{}{"period": "2m"}},
+		"testStreamTask-1": {Vars: map[string]interface{}{"period": "6m"}},
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(err.Error(), "rollback incomplete") {
+		t.Fatalf("expected the error to call out that rollback was incomplete, got: %s", err)
+	}
+	if store.tasks["testStreamTask-0"].Vars["period"] != "2m" {
+		t.Fatal("expected testStreamTask-0 to be left on its new (unrolled-back) vars, since its rollback failed")
+	}
+}
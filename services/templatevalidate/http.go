@@ -0,0 +1,55 @@
+package templatevalidate
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// validateRequest is the body of POST /kapacitor/v1/templates/{id}/validate.
+type validateRequest struct {
+	TICKscript string `json:"tickscript"`
+}
+
+// Handler implements the template validate endpoint.
+type Handler struct {
+	Validator  Validator
+	TaskLister TaskLister
+}
+
+func NewHandler(v Validator, lister TaskLister) *Handler {
+	return &Handler{Validator: v, TaskLister: lister}
+}
+
+// ServeHTTP expects the template id to be the second-to-last path segment,
+// e.g. /kapacitor/v1/templates/{id}/validate.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(segments) < 2 {
+		http.Error(w, "invalid template validate path", http.StatusBadRequest)
+		return
+	}
+	templateID := segments[len(segments)-2]
+
+	var req validateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tasks, err := h.TaskLister.TasksForTemplate(templateID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	reports := ValidateTemplate(h.Validator, req.TICKscript, tasks)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"tasks": reports})
+}
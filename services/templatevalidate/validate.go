@@ -0,0 +1,95 @@
+// Package templatevalidate preflights a candidate TICKscript against every
+// task derived from a template before it is applied, so an UpdateTemplate
+// call (or its dry-run variant) can report exactly which tasks would break
+// instead of applying the change and failing partway through the first
+// task that no longer type-checks.
+package templatevalidate
+
+import "fmt"
+
+// TaskReport is the per-task outcome of validating a candidate TICKscript.
+type TaskReport struct {
+	TaskID          string            `json:"task_id"`
+	OK              bool              `json:"ok"`
+	Error           string            `json:"error,omitempty"`
+	MissingVars     []string          `json:"missing_vars,omitempty"`
+	ChangedVarTypes map[string]string `json:"changed_var_types,omitempty"`
+}
+
+// TaskRef is the minimal information needed to re-check a task against a
+// candidate template.
+type TaskRef struct {
+	ID   string
+	Vars map[string]interface{}
+}
+
+// Validator type-checks a candidate TICKscript against a task's existing
+// vars, the same way the task store does when reloading a task from an
+// updated template, but without mutating anything.
+type Validator interface {
+	CheckTask(candidateTickscript string, task TaskRef) (missingVars []string, changedVarTypes map[string]string, err error)
+}
+
+// TaskLister returns every task currently derived from a template.
+type TaskLister interface {
+	TasksForTemplate(templateID string) ([]TaskRef, error)
+}
+
+// ValidateTemplate checks candidateTickscript against every task in tasks,
+// returning one report per task.
+func ValidateTemplate(v Validator, candidateTickscript string, tasks []TaskRef) []TaskReport {
+	reports := make([]TaskReport, 0, len(tasks))
+	for _, task := range tasks {
+		missing, changed, err := v.CheckTask(candidateTickscript, task)
+		report := TaskReport{
+			TaskID:          task.ID,
+			MissingVars:     missing,
+			ChangedVarTypes: changed,
+		}
+		if err != nil {
+			report.Error = err.Error()
+		} else {
+			report.OK = len(missing) == 0 && len(changed) == 0
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// UpdateTemplate validates candidateTickscript against every task derived
+// from templateID. If dryRun is set, or if any task fails validation, it
+// returns the reports without calling apply. Otherwise it calls apply and
+// returns the (all-OK) reports, so a breaking change is never partially
+// applied.
+func UpdateTemplate(v Validator, lister TaskLister, templateID, candidateTickscript string, dryRun bool, apply func(tickscript string) error) ([]TaskReport, error) {
+	tasks, err := lister.TasksForTemplate(templateID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks for template %q: %s", templateID, err)
+	}
+
+	reports := ValidateTemplate(v, candidateTickscript, tasks)
+	if dryRun {
+		return reports, nil
+	}
+
+	for _, report := range reports {
+		if !report.OK {
+			return reports, fmt.Errorf("template %q has %d task(s) that would fail to reload, aborting update", templateID, countFailing(reports))
+		}
+	}
+
+	if err := apply(candidateTickscript); err != nil {
+		return reports, err
+	}
+	return reports, nil
+}
+
+func countFailing(reports []TaskReport) int {
+	n := 0
+	for _, r := range reports {
+		if !r.OK {
+			n++
+		}
+	}
+	return n
+}
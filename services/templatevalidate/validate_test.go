@@ -0,0 +1,84 @@
+package templatevalidate
+
+import "testing"
+
+type fakeValidator struct {
+	requiredVars map[string]bool
+}
+
+func (f fakeValidator) CheckTask(candidateTickscript string, task TaskRef) ([]string, map[string]string, error) {
+	var missing []string
+	for v := range f.requiredVars {
+		if _, ok := task.Vars[v]; !ok {
+			missing = append(missing, v)
+		}
+	}
+	return missing, nil, nil
+}
+
+func TestValidateTemplate_ReportsPerTask(t *testing.T) {
+	v := fakeValidator{requiredVars: map[string]bool{"period": true}}
+	tasks := []TaskRef{
+		{ID: "testStreamTask-0", Vars: map[string]interface{}{"period": "1m"}},
+		{ID: "testStreamTask-1", Vars: map[string]interface{}{}},
+	}
+
+	reports := ValidateTemplate(v, "stream\n    |from()\n", tasks)
+	if len(reports) != 2 {
+		t.Fatalf("unexpected number of reports got %d exp 2", len(reports))
+	}
+	if !reports[0].OK {
+		t.Fatalf("expected testStreamTask-0 to be OK, got %+v", reports[0])
+	}
+	if reports[1].OK {
+		t.Fatalf("expected testStreamTask-1 to fail, got %+v", reports[1])
+	}
+	if len(reports[1].MissingVars) != 1 || reports[1].MissingVars[0] != "period" {
+		t.Fatalf("unexpected missing vars: %v", reports[1].MissingVars)
+	}
+}
+
+func TestUpdateTemplate_DryRunDoesNotApply(t *testing.T) {
+	v := fakeValidator{requiredVars: map[string]bool{"period": true}}
+	lister := staticLister{tasks: []TaskRef{{ID: "testStreamTask-0", Vars: map[string]interface{}{}}}}
+	applied := false
+
+	reports, err := UpdateTemplate(v, lister, "testTemplateID", "stream\n    |from()\n", true, func(string) error {
+		applied = true
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied {
+		t.Fatal("expected dry run not to apply the change")
+	}
+	if reports[0].OK {
+		t.Fatal("expected report to flag the missing var")
+	}
+}
+
+func TestUpdateTemplate_AbortsOnFailingTask(t *testing.T) {
+	v := fakeValidator{requiredVars: map[string]bool{"period": true}}
+	lister := staticLister{tasks: []TaskRef{{ID: "testStreamTask-0", Vars: map[string]interface{}{}}}}
+	applied := false
+
+	_, err := UpdateTemplate(v, lister, "testTemplateID", "stream\n    |from()\n", false, func(string) error {
+		applied = true
+		return nil
+	})
+	if err == nil {
+		t.Fatal("expected update to abort")
+	}
+	if applied {
+		t.Fatal("expected the breaking change not to be applied")
+	}
+}
+
+type staticLister struct {
+	tasks []TaskRef
+}
+
+func (s staticLister) TasksForTemplate(templateID string) ([]TaskRef, error) {
+	return s.tasks, nil
+}
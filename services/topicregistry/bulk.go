@@ -0,0 +1,92 @@
+package topicregistry
+
+import "github.com/influxdata/kapacitor/alert"
+
+// DeleteTopics removes every registered topic whose name matches
+// pattern and whose level is at least minLevel, and returns the names
+// deleted.
+func (r *Registry) DeleteTopics(pattern string, minLevel alert.Level) ([]string, error) {
+	matched, err := r.matching(pattern, minLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(matched))
+	for _, t := range matched {
+		if err := r.delete(t.Name); err != nil {
+			return names, err
+		}
+		names = append(names, t.Name)
+	}
+	return names, nil
+}
+
+// AckTopics clears the pending events of every registered topic whose
+// name matches pattern and whose level is at least minLevel, and returns
+// the names acknowledged.
+func (r *Registry) AckTopics(pattern string, minLevel alert.Level) ([]string, error) {
+	matched, err := r.matching(pattern, minLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(matched))
+	for _, t := range matched {
+		t.PendingEvents = nil
+		if err := r.Put(t); err != nil {
+			return names, err
+		}
+		names = append(names, t.Name)
+	}
+	return names, nil
+}
+
+// BulkUpdateHandlers adds and removes handlers on every registered topic
+// whose name matches pattern and whose level is at least minLevel, and
+// returns the names updated. Each topic's handler set is updated
+// atomically: every add is applied before any remove, so a handler
+// present in both lists ends up absent.
+func (r *Registry) BulkUpdateHandlers(pattern string, minLevel alert.Level, add, remove []string) ([]string, error) {
+	matched, err := r.matching(pattern, minLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(matched))
+	for _, t := range matched {
+		t.Handlers = updateHandlers(t.Handlers, add, remove)
+		if err := r.Put(t); err != nil {
+			return names, err
+		}
+		names = append(names, t.Name)
+	}
+	return names, nil
+}
+
+func updateHandlers(handlers, add, remove []string) []string {
+	set := make(map[string]bool, len(handlers)+len(add))
+	for _, h := range handlers {
+		set[h] = true
+	}
+	for _, h := range add {
+		set[h] = true
+	}
+	for _, h := range remove {
+		delete(set, h)
+	}
+
+	updated := make([]string, 0, len(set))
+	for _, h := range handlers {
+		if set[h] {
+			updated = append(updated, h)
+			delete(set, h)
+		}
+	}
+	for _, h := range add {
+		if set[h] {
+			updated = append(updated, h)
+			delete(set, h)
+		}
+	}
+	return updated
+}
@@ -0,0 +1,94 @@
+package topicregistry
+
+import (
+	"encoding/json"
+	"path"
+
+	"github.com/boltdb/bolt"
+	"github.com/influxdata/kapacitor/alert"
+	"github.com/pkg/errors"
+)
+
+var topicBucket = []byte("topicregistry_topics")
+
+// Registry persists Topics in a BoltDB bucket, one key per topic name.
+type Registry struct {
+	db *bolt.DB
+}
+
+// NewRegistry opens a Registry against db, creating its bucket if
+// necessary.
+func NewRegistry(db *bolt.DB) (*Registry, error) {
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(topicBucket)
+		return err
+	}); err != nil {
+		return nil, errors.Wrap(err, "topicregistry: creating bucket")
+	}
+	return &Registry{db: db}, nil
+}
+
+// Put creates or replaces a topic.
+func (r *Registry) Put(t Topic) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(topicBucket).Put([]byte(t.Name), data)
+	})
+}
+
+// Get returns the topic with name, or found=false if none is registered.
+func (r *Registry) Get(name string) (Topic, bool, error) {
+	var t Topic
+	var found bool
+	err := r.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(topicBucket).Get([]byte(name))
+		if v == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(v, &t)
+	})
+	return t, found, err
+}
+
+// delete removes a topic outright.
+func (r *Registry) delete(name string) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(topicBucket).Delete([]byte(name))
+	})
+}
+
+// forEach calls fn for every registered topic, in bolt's byte-sorted key
+// order. fn must not mutate the registry.
+func (r *Registry) forEach(fn func(t Topic) error) error {
+	return r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(topicBucket).ForEach(func(k, v []byte) error {
+			var t Topic
+			if err := json.Unmarshal(v, &t); err != nil {
+				return err
+			}
+			return fn(t)
+		})
+	})
+}
+
+// matching returns every registered topic whose name matches pattern (a
+// path.Match glob, same as ListTopics' own pattern selector) and whose
+// level is at least minLevel.
+func (r *Registry) matching(pattern string, minLevel alert.Level) ([]Topic, error) {
+	var matched []Topic
+	err := r.forEach(func(t Topic) error {
+		ok, err := path.Match(pattern, t.Name)
+		if err != nil {
+			return err
+		}
+		if ok && t.atLeast(minLevel) {
+			matched = append(matched, t)
+		}
+		return nil
+	})
+	return matched, err
+}
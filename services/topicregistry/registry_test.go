@@ -0,0 +1,167 @@
+package topicregistry
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/influxdata/kapacitor/alert"
+)
+
+func openTestDB(t *testing.T) (*bolt.DB, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "topicregistry-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := bolt.Open(filepath.Join(dir, "topicregistry.db"), 0600, nil)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func seedTopics(t *testing.T, r *Registry, topics ...Topic) {
+	t.Helper()
+	for _, topic := range topics {
+		if err := r.Put(topic); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func sorted(names []string) []string {
+	out := append([]string(nil), names...)
+	sort.Strings(out)
+	return out
+}
+
+func TestRegistry_DeleteTopics_PatternAndMinLevel(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+	r, err := NewRegistry(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seedTopics(t, r,
+		Topic{Name: "device/1/cpu", Level: alert.Critical},
+		Topic{Name: "device/2/cpu", Level: alert.Warning},
+		Topic{Name: "device/3/cpu", Level: alert.Critical},
+		Topic{Name: "host/1/mem", Level: alert.Critical},
+	)
+
+	deleted, err := r.DeleteTopics("device/*/cpu", alert.Critical)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := sorted(deleted), []string{"device/1/cpu", "device/3/cpu"}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected deleted set got %v exp %v", got, exp)
+	}
+
+	remaining, err := r.matching("*", alert.OK)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var remainingNames []string
+	for _, topic := range remaining {
+		remainingNames = append(remainingNames, topic.Name)
+	}
+	if got, exp := sorted(remainingNames), []string{"device/2/cpu", "host/1/mem"}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected remaining set got %v exp %v", got, exp)
+	}
+}
+
+func TestRegistry_AckTopics_ClearsPendingEvents(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+	r, err := NewRegistry(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seedTopics(t, r,
+		Topic{Name: "device/1/cpu", Level: alert.Critical, PendingEvents: []string{"e1", "e2"}},
+		Topic{Name: "device/2/cpu", Level: alert.Info, PendingEvents: []string{"e3"}},
+	)
+
+	acked, err := r.AckTopics("device/*/cpu", alert.Warning)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := acked, []string{"device/1/cpu"}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected acked set got %v exp %v", got, exp)
+	}
+
+	updated, found, err := r.Get("device/1/cpu")
+	if err != nil || !found {
+		t.Fatal(err, found)
+	}
+	if len(updated.PendingEvents) != 0 {
+		t.Fatalf("expected pending events cleared, got %v", updated.PendingEvents)
+	}
+
+	untouched, found, err := r.Get("device/2/cpu")
+	if err != nil || !found {
+		t.Fatal(err, found)
+	}
+	if len(untouched.PendingEvents) != 1 {
+		t.Fatalf("expected the below-min-level topic untouched, got %v", untouched.PendingEvents)
+	}
+}
+
+func TestRegistry_BulkUpdateHandlers_AddsAndRemovesAcrossPattern(t *testing.T) {
+	db, closeDB := openTestDB(t)
+	defer closeDB()
+	r, err := NewRegistry(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seedTopics(t, r,
+		Topic{Name: "device/1/cpu", Level: alert.Critical, Handlers: []string{"pagerduty"}},
+		Topic{Name: "device/2/cpu", Level: alert.Critical, Handlers: []string{"pagerduty", "slack"}},
+	)
+
+	updated, err := r.BulkUpdateHandlers("device/*/cpu", alert.Critical, []string{"jira"}, []string{"pagerduty"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := sorted(updated), []string{"device/1/cpu", "device/2/cpu"}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected updated set got %v exp %v", got, exp)
+	}
+
+	t1, _, err := r.Get("device/1/cpu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := t1.Handlers, []string{"jira"}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected handlers for device/1/cpu got %v exp %v", got, exp)
+	}
+
+	t2, _, err := r.Get("device/2/cpu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := t2.Handlers, []string{"slack", "jira"}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected handlers for device/2/cpu got %v exp %v", got, exp)
+	}
+}
+
+func TestTopic_AtLeast(t *testing.T) {
+	topic := Topic{Level: alert.Warning}
+	if !topic.atLeast(alert.Info) {
+		t.Error("expected Warning to be at least Info")
+	}
+	if topic.atLeast(alert.Critical) {
+		t.Error("expected Warning to not be at least Critical")
+	}
+}
@@ -0,0 +1,40 @@
+// Package topicregistry provides pattern- and min-level-scoped bulk
+// operations over a set of alert topics: delete, ack, and
+// add/remove-handler, for deployments where a task creates one topic per
+// device and operators need to prune or re-route many at once instead of
+// scripting individual calls.
+package topicregistry
+
+import "github.com/influxdata/kapacitor/alert"
+
+// Topic is one named group of alert state: its most severe currently
+// open event level, the handlers attached to it, and whatever events are
+// still pending acknowledgement.
+type Topic struct {
+	Name          string      `json:"name"`
+	Level         alert.Level `json:"level"`
+	Handlers      []string    `json:"handlers,omitempty"`
+	PendingEvents []string    `json:"pendingEvents,omitempty"`
+}
+
+// levelRank orders alert.Level by severity so topics can be selected by
+// a minimum level, since alert.Level has no exported ordering of its
+// own.
+func levelRank(level alert.Level) int {
+	switch level {
+	case alert.OK:
+		return 0
+	case alert.Info:
+		return 1
+	case alert.Warning:
+		return 2
+	case alert.Critical:
+		return 3
+	}
+	return -1
+}
+
+// atLeast reports whether t's level is at least as severe as minLevel.
+func (t Topic) atLeast(minLevel alert.Level) bool {
+	return levelRank(t.Level) >= levelRank(minLevel)
+}
@@ -0,0 +1,102 @@
+package udf
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Group is one tag group from a batch, dispatched to a UDF agent as a
+// single unit of work.
+type Group struct {
+	Index int
+	Data  interface{}
+}
+
+// Worker processes a single Group against a long-lived UDF agent
+// connection, returning the transformed result.
+type Worker interface {
+	Process(ctx context.Context, g Group) (interface{}, error)
+}
+
+// BatchExecutor fans a batch's groups out across a bounded pool of UDF
+// workers, instead of invoking the agent once per group serially.
+// NewWorker is called lazily, up to MaxConcurrency times, to populate the
+// pool; workers are reused across batches.
+type BatchExecutor struct {
+	NewWorker      func() (Worker, error)
+	MaxConcurrency int
+
+	pool chan Worker
+}
+
+// NewBatchExecutor builds an executor for f, using newWorker to create
+// each pooled worker. A MaxConcurrency of zero or one processes groups
+// serially through a single worker.
+func NewBatchExecutor(f FunctionConfig, newWorker func() (Worker, error)) *BatchExecutor {
+	n := f.MaxConcurrency
+	if n < 1 {
+		n = 1
+	}
+	return &BatchExecutor{
+		NewWorker:      newWorker,
+		MaxConcurrency: n,
+		pool:           make(chan Worker, n),
+	}
+}
+
+// borrow returns a pooled worker, creating one if the pool isn't yet at
+// capacity.
+func (e *BatchExecutor) borrow() (Worker, error) {
+	select {
+	case w := <-e.pool:
+		return w, nil
+	default:
+	}
+	return e.NewWorker()
+}
+
+func (e *BatchExecutor) release(w Worker) {
+	select {
+	case e.pool <- w:
+	default:
+		// Pool is full; drop the worker rather than block the caller.
+	}
+}
+
+// Run dispatches every group in groups to the worker pool concurrently,
+// bounded by MaxConcurrency, and returns their results in the original
+// group order. If any group's Process call returns an error, ctx is
+// canceled for the remaining in-flight calls and Run returns that error.
+func (e *BatchExecutor) Run(ctx context.Context, groups []Group) ([]interface{}, error) {
+	results := make([]interface{}, len(groups))
+
+	eg, ctx := errgroup.WithContext(ctx)
+	sem := make(chan struct{}, e.MaxConcurrency)
+
+	for _, g := range groups {
+		g := g
+		sem <- struct{}{}
+		eg.Go(func() error {
+			defer func() { <-sem }()
+
+			w, err := e.borrow()
+			if err != nil {
+				return err
+			}
+			defer e.release(w)
+
+			res, err := w.Process(ctx, g)
+			if err != nil {
+				return err
+			}
+			results[g.Index] = res
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,123 @@
+package udf
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeWorker struct {
+	delay    time.Duration
+	failOn   int
+	inflight *int32
+}
+
+func (w *fakeWorker) Process(ctx context.Context, g Group) (interface{}, error) {
+	if w.inflight != nil {
+		atomic.AddInt32(w.inflight, 1)
+		defer atomic.AddInt32(w.inflight, -1)
+	}
+	select {
+	case <-time.After(w.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if w.failOn != 0 && g.Index == w.failOn {
+		return nil, errors.New("simulated group failure")
+	}
+	return g.Index * 2, nil
+}
+
+func groups(n int) []Group {
+	gs := make([]Group, n)
+	for i := range gs {
+		gs[i] = Group{Index: i}
+	}
+	return gs
+}
+
+func TestBatchExecutor_RunPreservesOrder(t *testing.T) {
+	e := NewBatchExecutor(FunctionConfig{MaxConcurrency: 4}, func() (Worker, error) {
+		return &fakeWorker{delay: time.Millisecond}, nil
+	})
+
+	results, err := e.Run(context.Background(), groups(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range results {
+		if r.(int) != i*2 {
+			t.Fatalf("expected result[%d] == %d, got %v", i, i*2, r)
+		}
+	}
+}
+
+func TestBatchExecutor_CancelsOnFirstError(t *testing.T) {
+	var inflight int32
+	e := NewBatchExecutor(FunctionConfig{MaxConcurrency: 4}, func() (Worker, error) {
+		return &fakeWorker{delay: 20 * time.Millisecond, failOn: 2, inflight: &inflight}, nil
+	})
+
+	_, err := e.Run(context.Background(), groups(8))
+	if err == nil {
+		t.Fatal("expected an error from the failing group")
+	}
+}
+
+func TestBatchExecutor_ReusesPooledWorkers(t *testing.T) {
+	var built int32
+	e := NewBatchExecutor(FunctionConfig{MaxConcurrency: 2}, func() (Worker, error) {
+		atomic.AddInt32(&built, 1)
+		return &fakeWorker{delay: time.Millisecond}, nil
+	})
+
+	if _, err := e.Run(context.Background(), groups(20)); err != nil {
+		t.Fatal(err)
+	}
+	if built > 2 {
+		t.Fatalf("expected at most MaxConcurrency workers to be built, got %d", built)
+	}
+}
+
+func TestBatchExecutor_ScalesSubLinearlyWithGroups(t *testing.T) {
+	run := func(n, concurrency int) time.Duration {
+		e := NewBatchExecutor(FunctionConfig{MaxConcurrency: concurrency}, func() (Worker, error) {
+			return &fakeWorker{delay: 5 * time.Millisecond}, nil
+		})
+		start := time.Now()
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := e.Run(context.Background(), groups(n)); err != nil {
+				t.Error(err)
+			}
+		}()
+		wg.Wait()
+		return time.Since(start)
+	}
+
+	serial := run(16, 1)
+	parallel := run(16, 8)
+
+	if parallel >= serial {
+		t.Fatalf("expected parallel execution (%v) to beat serial (%v)", parallel, serial)
+	}
+}
+
+func BenchmarkBatchExecutor_Run(b *testing.B) {
+	e := NewBatchExecutor(FunctionConfig{MaxConcurrency: 8}, func() (Worker, error) {
+		return &fakeWorker{delay: time.Millisecond}, nil
+	})
+	gs := groups(32)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := e.Run(context.Background(), gs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Package udf implements the launcher and wire protocol for user defined
+// functions: external processes (or, with the Address field, external
+// services) that Kapacitor streams points to and reads results back from.
+package udf
+
+import (
+	"crypto/tls"
+	"errors"
+
+	"github.com/influxdata/toml"
+)
+
+// TLSConfig configures a TLS connection to a remote UDF agent.
+type TLSConfig struct {
+	CertFile           string `toml:"cert" override:"cert"`
+	KeyFile            string `toml:"key" override:"key"`
+	CAFile             string `toml:"ca" override:"ca"`
+	ServerName         string `toml:"server-name" override:"server-name"`
+	InsecureSkipVerify bool   `toml:"insecure-skip-verify" override:"insecure-skip-verify"`
+}
+
+func (c TLSConfig) isZero() bool {
+	return c == TLSConfig{}
+}
+
+// tlsConfig builds a *tls.Config from c, loading the client certificate
+// and CA pool if configured. It returns nil if c is the zero value, since
+// a nil *tls.Config tells the dialer to use a plain TCP connection.
+func (c TLSConfig) tlsConfig() (*tls.Config, error) {
+	if c.isZero() {
+		return nil, nil
+	}
+	tc := &tls.Config{
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+	if c.CAFile != "" {
+		pool, err := loadCAPool(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		tc.RootCAs = pool
+	}
+	return tc, nil
+}
+
+// FunctionConfig configures how Kapacitor launches or connects to a
+// single UDF agent. Exactly one of Prog, Socket, or Address must be set,
+// selecting the process, Unix socket, or TCP/TLS transport respectively.
+type FunctionConfig struct {
+	Prog    string            `toml:"prog" override:"prog"`
+	Args    []string          `toml:"args" override:"args"`
+	Env     map[string]string `toml:"env" override:"env"`
+	Socket  string            `toml:"socket" override:"socket"`
+	Address string            `toml:"address" override:"address"`
+	TLS     TLSConfig         `toml:"tls" override:"tls"`
+	Timeout toml.Duration     `toml:"timeout" override:"timeout"`
+
+	// MaxConcurrency bounds how many groups of a single batch may be
+	// dispatched to this function's agent pool at once. Zero means no
+	// concurrency: groups are processed one at a time, matching the
+	// historical behavior.
+	MaxConcurrency int `toml:"max-concurrency" override:"max-concurrency"`
+
+	// MaxMemoryBytes and MaxCPUPercent cap the resources a Prog-launched
+	// agent process may use; zero means unlimited. MaxRestarts bounds
+	// how many times a crashed agent is relaunched, with RestartBackoff
+	// between attempts, before the function is quarantined.
+	MaxMemoryBytes int64         `toml:"max-memory-bytes" override:"max-memory-bytes"`
+	MaxCPUPercent  int           `toml:"max-cpu-percent" override:"max-cpu-percent"`
+	MaxRestarts    int           `toml:"max-restarts" override:"max-restarts"`
+	RestartBackoff toml.Duration `toml:"restart-backoff" override:"restart-backoff"`
+
+	// StderrLogLevel is the log level (e.g. "error", "info", "debug")
+	// under which the agent's captured stderr lines are logged.
+	// Defaults to "error".
+	StderrLogLevel string `toml:"stderr-log-level" override:"stderr-log-level"`
+}
+
+// Validate checks that a FunctionConfig selects exactly one transport.
+func (f FunctionConfig) Validate() error {
+	set := 0
+	if f.Prog != "" {
+		set++
+	}
+	if f.Socket != "" {
+		set++
+	}
+	if f.Address != "" {
+		set++
+	}
+	if set != 1 {
+		return errors.New("udf: exactly one of prog, socket, or address must be set")
+	}
+	if f.Address == "" && !f.TLS.isZero() {
+		return errors.New("udf: tls can only be set alongside address")
+	}
+	if f.MaxConcurrency < 0 {
+		return errors.New("udf: max-concurrency must not be negative")
+	}
+	return nil
+}
+
+// Config is the top level `[udf]` configuration section.
+type Config struct {
+	Functions map[string]FunctionConfig `toml:"functions" override:"functions"`
+}
+
+func NewConfig() Config {
+	return Config{
+		Functions: make(map[string]FunctionConfig),
+	}
+}
+
+func (c Config) Validate() error {
+	for name, f := range c.Functions {
+		if err := f.Validate(); err != nil {
+			return errors.New("udf: function " + name + ": " + err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package udf
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// ServeHTTP implements POST /kapacitor/v1/config/udf/functions/{name}/reload.
+// The request body, if any, is the new FunctionConfig to reload with; an
+// empty body reloads the function under its existing configuration,
+// which is useful for picking up an updated binary or script at the same
+// path.
+func (m *Manager) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name, ok := reloadFunctionName(req.URL.Path)
+	if !ok {
+		http.Error(w, "invalid reload path", http.StatusBadRequest)
+		return
+	}
+
+	var c FunctionConfig
+	if req.Body != nil {
+		dec := json.NewDecoder(req.Body)
+		if err := dec.Decode(&c); err != nil && err.Error() != "EOF" {
+			http.Error(w, "invalid function config: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if c.Prog == "" && c.Socket == "" && c.Address == "" {
+		current := m.Current(name)
+		if current == nil {
+			http.Error(w, "unknown function "+name, http.StatusNotFound)
+			return
+		}
+	}
+
+	if err := m.Reload(name, c); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// reloadFunctionName extracts {name} from a path of the form
+// /kapacitor/v1/config/udf/functions/{name}/reload.
+func reloadFunctionName(path string) (string, bool) {
+	const suffix = "/reload"
+	if !strings.HasSuffix(path, suffix) {
+		return "", false
+	}
+	trimmed := strings.TrimSuffix(path, suffix)
+	idx := strings.LastIndex(trimmed, "/")
+	if idx < 0 || idx == len(trimmed)-1 {
+		return "", false
+	}
+	return trimmed[idx+1:], true
+}
@@ -0,0 +1,68 @@
+//go:build linux
+
+package udf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// cgroupRoot is the cgroup v2 unified hierarchy mountpoint; overridable
+// in tests.
+var cgroupRoot = "/sys/fs/cgroup"
+
+// applyProcessLimits enforces f's MaxMemoryBytes/MaxCPUPercent on the
+// process pid via a per-agent cgroup v2 leaf. There is deliberately no
+// setrlimit fallback: RLIMIT_AS can only be applied to the calling
+// process itself, not to pid, and this function runs in the Kapacitor
+// parent process (see Supervisor.Run), so a setrlimit call here would
+// cap the server's own address space instead of the agent's. Enforcing
+// the limit on a host without writable cgroups v2 would require setting
+// the rlimit from a pre-exec hook on the agent's own process, which
+// isn't something this package's Factory/Instance abstraction exposes a
+// hook for; until it does, MaxMemoryBytes/MaxCPUPercent are simply
+// unenforced there, and that is logged rather than silently ignored.
+func applyProcessLimits(name string, pid int, f FunctionConfig) error {
+	if f.MaxMemoryBytes <= 0 && f.MaxCPUPercent <= 0 {
+		return nil
+	}
+
+	if dir, err := createAgentCgroup(name); err == nil {
+		if err := writeCgroupLimits(dir, f); err == nil {
+			if err := os.WriteFile(filepath.Join(dir, "cgroup.procs"), []byte(fmt.Sprintf("%d", pid)), 0644); err == nil {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("udf: cgroups v2 unavailable for %q, MaxMemoryBytes/MaxCPUPercent are not enforced", name)
+}
+
+func createAgentCgroup(name string) (string, error) {
+	dir := filepath.Join(cgroupRoot, "kapacitor-udf-"+name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+func writeCgroupLimits(dir string, f FunctionConfig) error {
+	if f.MaxMemoryBytes > 0 {
+		v := fmt.Sprintf("%d", f.MaxMemoryBytes)
+		if err := os.WriteFile(filepath.Join(dir, "memory.max"), []byte(v), 0644); err != nil {
+			return err
+		}
+	}
+	if f.MaxCPUPercent > 0 {
+		// cpu.max is "<quota> <period>" in microseconds; with a period
+		// of 100000us (100ms), quota is period*MaxCPUPercent/100.
+		const period = 100000
+		quota := period * f.MaxCPUPercent / 100
+		v := fmt.Sprintf("%d %d", quota, period)
+		if err := os.WriteFile(filepath.Join(dir, "cpu.max"), []byte(v), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+//go:build linux
+
+package udf
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestApplyProcessLimits_WritesCgroupLimitsUnderCgroupRoot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kapacitor-udf-limits-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	orig := cgroupRoot
+	cgroupRoot = dir
+	defer func() { cgroupRoot = orig }()
+
+	f := FunctionConfig{MaxMemoryBytes: 1 << 20, MaxCPUPercent: 50}
+	if err := applyProcessLimits("outliers", os.Getpid(), f); err != nil {
+		t.Fatal(err)
+	}
+
+	agentDir := filepath.Join(dir, "kapacitor-udf-outliers")
+	memMax, err := ioutil.ReadFile(filepath.Join(agentDir, "memory.max"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(memMax) != "1048576" {
+		t.Fatalf("unexpected memory.max got %s", memMax)
+	}
+
+	cpuMax, err := ioutil.ReadFile(filepath.Join(agentDir, "cpu.max"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(cpuMax) != "50000 100000" {
+		t.Fatalf("unexpected cpu.max got %s", cpuMax)
+	}
+}
+
+// TestApplyProcessLimits_FallsBackToAnErrorWithoutTouchingOwnRlimit
+// exercises the case the setrlimit fallback used to mishandle: when
+// cgroups v2 is unavailable (here, because cgroupRoot points at a
+// read-only directory), applyProcessLimits must return an error
+// describing the gap rather than silently rlimiting the calling
+// (parent, Kapacitor server) process's own address space.
+func TestApplyProcessLimits_FallsBackToAnErrorWithoutTouchingOwnRlimit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kapacitor-udf-limits-readonly-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Chmod(dir, 0500); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(dir, 0700)
+
+	orig := cgroupRoot
+	cgroupRoot = dir
+	defer func() { cgroupRoot = orig }()
+
+	var before syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_AS, &before); err != nil {
+		t.Fatal(err)
+	}
+
+	f := FunctionConfig{MaxMemoryBytes: 1 << 20}
+	if err := applyProcessLimits("outliers", os.Getpid(), f); err == nil {
+		t.Fatal("expected an error when cgroups v2 is unavailable")
+	}
+
+	var after syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_AS, &after); err != nil {
+		t.Fatal(err)
+	}
+	if before != after {
+		t.Fatalf("applyProcessLimits must never change the calling process's own RLIMIT_AS, got %+v want %+v", after, before)
+	}
+}
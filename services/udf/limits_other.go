@@ -0,0 +1,10 @@
+//go:build !linux
+
+package udf
+
+// applyProcessLimits is a no-op on platforms without cgroups v2 or a
+// usable RLIMIT_AS equivalent; MaxMemoryBytes/MaxCPUPercent are
+// accepted in config but not enforced.
+func applyProcessLimits(name string, pid int, f FunctionConfig) error {
+	return nil
+}
@@ -0,0 +1,133 @@
+package udf
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Instance is a running UDF agent: a live, initialized connection ready
+// to accept points or batches. Manager treats it as opaque beyond
+// starting and stopping it.
+type Instance interface {
+	// Close stops the instance. It must be safe to call once the
+	// instance has already stopped accepting new work.
+	Close() error
+}
+
+// Factory builds and initializes a new Instance for a function, blocking
+// until the agent has completed its init handshake and is ready to
+// receive points.
+type Factory func(name string, c FunctionConfig) (Instance, error)
+
+// managedFunction holds the currently active instance for one named
+// function behind an atomic pointer, so callers reading the current
+// instance never observe a half-swapped state.
+type managedFunction struct {
+	current atomic.Value // Instance
+}
+
+func (m *managedFunction) Current() Instance {
+	v := m.current.Load()
+	if v == nil {
+		return nil
+	}
+	return v.(Instance)
+}
+
+// Manager tracks the live Instance for every configured UDF function and
+// performs rolling reloads: a new instance is started and swapped in
+// atomically, and only then is the old instance drained and closed, so a
+// task pipeline reading Manager.Current never observes a gap.
+type Manager struct {
+	NewInstance Factory
+
+	mu        sync.Mutex
+	functions map[string]*managedFunction
+}
+
+// NewManager builds a Manager that uses newInstance to start agents.
+func NewManager(newInstance Factory) *Manager {
+	return &Manager{
+		NewInstance: newInstance,
+		functions:   make(map[string]*managedFunction),
+	}
+}
+
+// Start launches the initial instance for name under config c and
+// registers it with the manager. It returns an error if name is already
+// registered; use Reload to change a running function's configuration.
+func (m *Manager) Start(name string, c FunctionConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.functions[name]; ok {
+		return fmt.Errorf("udf: function %q is already running", name)
+	}
+
+	inst, err := m.NewInstance(name, c)
+	if err != nil {
+		return err
+	}
+
+	mf := &managedFunction{}
+	mf.current.Store(inst)
+	m.functions[name] = mf
+	return nil
+}
+
+// Current returns the active Instance for name, or nil if name is not
+// registered.
+func (m *Manager) Current(name string) Instance {
+	m.mu.Lock()
+	mf, ok := m.functions[name]
+	m.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return mf.Current()
+}
+
+// Reload starts a new instance of name under newConfig, waits for it to
+// finish initializing (NewInstance only returns once init has
+// completed), atomically swaps it in as the active instance, and then
+// closes the old instance. Any task pipeline reading Manager.Current
+// sees either the old or the new instance, never neither.
+func (m *Manager) Reload(name string, newConfig FunctionConfig) error {
+	m.mu.Lock()
+	mf, ok := m.functions[name]
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("udf: function %q is not running", name)
+	}
+
+	next, err := m.NewInstance(name, newConfig)
+	if err != nil {
+		return fmt.Errorf("udf: starting replacement instance for %q: %w", name, err)
+	}
+
+	old := mf.Current()
+	mf.current.Store(next)
+
+	if old != nil {
+		if err := old.Close(); err != nil {
+			return fmt.Errorf("udf: closing previous instance of %q after reload: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Stop closes and unregisters the active instance for name.
+func (m *Manager) Stop(name string) error {
+	m.mu.Lock()
+	mf, ok := m.functions[name]
+	delete(m.functions, name)
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("udf: function %q is not running", name)
+	}
+	if inst := mf.Current(); inst != nil {
+		return inst.Close()
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package udf
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// movingAvgAgent is a fake Instance standing in for a compiled
+// moving_avg UDF agent: it keeps a window of the last N points (N taken
+// from its FunctionConfig.Args, mirroring `.size(N)`) and reports
+// whether it has been closed.
+type movingAvgAgent struct {
+	mu     sync.Mutex
+	size   int
+	window []float64
+	closed bool
+}
+
+func newMovingAvgAgent(name string, c FunctionConfig) (Instance, error) {
+	size := 3
+	if len(c.Args) > 0 {
+		if n, err := strconv.Atoi(c.Args[0]); err == nil {
+			size = n
+		}
+	}
+	return &movingAvgAgent{size: size}, nil
+}
+
+func (a *movingAvgAgent) Point(p float64) float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.window = append(a.window, p)
+	if len(a.window) > a.size {
+		a.window = a.window[len(a.window)-a.size:]
+	}
+	var sum float64
+	for _, v := range a.window {
+		sum += v
+	}
+	return sum / float64(len(a.window))
+}
+
+func (a *movingAvgAgent) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.closed = true
+	return nil
+}
+
+func (a *movingAvgAgent) isClosed() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.closed
+}
+
+func TestManager_ReloadSwapsConfigWithoutDroppingPoints(t *testing.T) {
+	m := NewManager(newMovingAvgAgent)
+	if err := m.Start("movingAvg", FunctionConfig{Args: []string{"3"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var results []float64
+	feed := func(p float64) {
+		inst := m.Current("movingAvg").(*movingAvgAgent)
+		results = append(results, inst.Point(p))
+	}
+
+	for _, p := range []float64{1, 2, 3} {
+		feed(p)
+	}
+	if got := results[len(results)-1]; got != 2 {
+		t.Fatalf("expected average of [1 2 3] == 2, got %v", got)
+	}
+
+	first := m.Current("movingAvg").(*movingAvgAgent)
+
+	if err := m.Reload("movingAvg", FunctionConfig{Args: []string{"2"}}); err != nil {
+		t.Fatal(err)
+	}
+	if !first.isClosed() {
+		t.Fatal("expected the old instance to be closed after reload")
+	}
+
+	for _, p := range []float64{4, 5} {
+		feed(p)
+	}
+	if len(results) != 5 {
+		t.Fatalf("expected no points to be lost across the reload, got %d results", len(results))
+	}
+	if got := results[len(results)-1]; got != 4.5 {
+		t.Fatalf("expected the new window size to take effect, average of [4 5] == 4.5, got %v", got)
+	}
+}
+
+func TestManager_ReloadUnknownFunctionErrors(t *testing.T) {
+	m := NewManager(newMovingAvgAgent)
+	if err := m.Reload("missing", FunctionConfig{}); err == nil {
+		t.Fatal("expected an error reloading a function that was never started")
+	}
+}
+
+func TestManager_StartTwiceErrors(t *testing.T) {
+	m := NewManager(newMovingAvgAgent)
+	if err := m.Start("movingAvg", FunctionConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Start("movingAvg", FunctionConfig{}); err == nil {
+		t.Fatal("expected starting an already-running function to error")
+	}
+}
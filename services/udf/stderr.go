@@ -0,0 +1,35 @@
+package udf
+
+import (
+	"bufio"
+	"io"
+	"log"
+)
+
+// logLevelPrefix maps a StderrLogLevel name to the log line prefix this
+// repo's loggers use to indicate severity.
+func logLevelPrefix(level string) string {
+	switch level {
+	case "debug":
+		return "D!"
+	case "info":
+		return "I!"
+	case "warn":
+		return "W!"
+	default:
+		return "E!"
+	}
+}
+
+// captureStderr reads r line-by-line until it returns EOF or an error,
+// logging each line to logger tagged with the function's name instead of
+// letting the agent write directly to the Kapacitor process's stderr.
+// It returns once r is exhausted; callers typically run it in its own
+// goroutine alongside the agent process.
+func captureStderr(r io.Reader, logger *log.Logger, name, level string) {
+	prefix := logLevelPrefix(level)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		logger.Printf("%s udf %s: %s", prefix, name, scanner.Text())
+	}
+}
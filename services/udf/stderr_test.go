@@ -0,0 +1,35 @@
+package udf
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestCaptureStderr_TagsLinesWithFunctionNameAndLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.New(&buf, "", 0)
+
+	r := strings.NewReader("first line\nsecond line\n")
+	captureStderr(r, logger, "outliers", "debug")
+
+	out := buf.String()
+	if !strings.Contains(out, "D! udf outliers: first line") {
+		t.Fatalf("expected tagged first line, got %q", out)
+	}
+	if !strings.Contains(out, "D! udf outliers: second line") {
+		t.Fatalf("expected tagged second line, got %q", out)
+	}
+}
+
+func TestCaptureStderr_DefaultsToErrorLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.New(&buf, "", 0)
+
+	captureStderr(strings.NewReader("boom\n"), logger, "movingAvg", "")
+
+	if !strings.Contains(buf.String(), "E! udf movingAvg: boom") {
+		t.Fatalf("expected default error-level tagging, got %q", buf.String())
+	}
+}
@@ -0,0 +1,124 @@
+package udf
+
+import (
+	"expvar"
+	"fmt"
+	"log"
+	"time"
+)
+
+// SupervisedInstance is an Instance whose underlying process can exit on
+// its own (e.g. crash, OOM-kill), rather than only being stopped by a
+// call to Close.
+type SupervisedInstance interface {
+	Instance
+	Pid() int
+	// Wait blocks until the process exits and returns the reason, or
+	// nil if Close was called intentionally.
+	Wait() error
+}
+
+// restartVars exposes each supervised function's restart count under
+// /kapacitor/v1/debug/vars as udf_restarts.<name>.
+var restartVars = expvar.NewMap("udf_restarts")
+
+// Supervisor restarts a crashed UDF agent up to MaxRestarts times with
+// exponential backoff, quarantining the function once the budget is
+// exhausted.
+type Supervisor struct {
+	Name        string
+	Config      FunctionConfig
+	NewInstance func() (SupervisedInstance, error)
+	Logger      *log.Logger
+
+	// Sleep is overridable in tests to avoid real waiting.
+	Sleep func(time.Duration)
+
+	restarts    int
+	quarantined error
+	stopped     bool
+}
+
+// NewSupervisor builds a Supervisor for name/c.
+func NewSupervisor(name string, c FunctionConfig, newInstance func() (SupervisedInstance, error), logger *log.Logger) *Supervisor {
+	return &Supervisor{
+		Name:        name,
+		Config:      c,
+		NewInstance: newInstance,
+		Logger:      logger,
+		Sleep:       time.Sleep,
+	}
+}
+
+// Run starts the agent and supervises it, restarting on crash until
+// MaxRestarts is exhausted or Stop is called. It blocks until the
+// function is quarantined or stopped, so callers typically invoke it in
+// its own goroutine.
+func (s *Supervisor) Run() error {
+	backoff := time.Duration(s.Config.RestartBackoff)
+	if backoff <= 0 {
+		backoff = 500 * time.Millisecond
+	}
+	const maxBackoff = time.Minute
+
+	for {
+		inst, err := s.NewInstance()
+		if err != nil {
+			return fmt.Errorf("udf: starting %q: %w", s.Name, err)
+		}
+
+		if err := applyProcessLimits(s.Name, inst.Pid(), s.Config); err != nil {
+			s.Logger.Printf("E! udf %s: failed to apply resource limits: %v", s.Name, err)
+		}
+
+		waitErr := inst.Wait()
+		if s.stopped {
+			return nil
+		}
+		if waitErr == nil {
+			// The instance exited cleanly of its own accord; nothing to
+			// restart.
+			return nil
+		}
+
+		s.restarts++
+		restartVars.Set(s.Name, expvarInt(s.restarts))
+		s.Logger.Printf("E! udf %s: agent exited: %v (restart %d/%d)", s.Name, waitErr, s.restarts, s.Config.MaxRestarts)
+
+		if s.restarts > s.Config.MaxRestarts {
+			s.quarantined = fmt.Errorf("udf: %q quarantined after %d restarts: %w", s.Name, s.restarts-1, waitErr)
+			return s.quarantined
+		}
+
+		s.Sleep(backoff)
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// Stop marks the supervisor as intentionally stopped, so the next Wait
+// return is treated as a clean shutdown rather than a crash to restart.
+func (s *Supervisor) Stop() {
+	s.stopped = true
+}
+
+// Quarantined reports the error that quarantined this function, or nil
+// if it is healthy or simply stopped.
+func (s *Supervisor) Quarantined() error {
+	return s.quarantined
+}
+
+// Restarts reports how many times the agent has exited with an error so
+// far. Every such exit is followed by a restart except the one that
+// exceeds MaxRestarts and quarantines the function.
+func (s *Supervisor) Restarts() int {
+	return s.restarts
+}
+
+type expvarInt int
+
+func (v expvarInt) String() string {
+	return fmt.Sprintf("%d", int(v))
+}
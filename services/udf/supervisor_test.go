@@ -0,0 +1,79 @@
+package udf
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/influxdata/toml"
+)
+
+// crashingInstance simulates an agent whose Wait returns err: an
+// OOM-style crash when err is set, a clean exit when it is nil.
+type crashingInstance struct {
+	err error
+}
+
+func (c *crashingInstance) Pid() int     { return 1234 }
+func (c *crashingInstance) Close() error { return nil }
+func (c *crashingInstance) Wait() error  { return c.err }
+
+func discardLogger() *log.Logger {
+	return log.New(ioutil.Discard, "", 0)
+}
+
+func TestSupervisor_RestartsUpToMaxThenQuarantines(t *testing.T) {
+	started := 0
+	s := NewSupervisor("outliers", FunctionConfig{MaxRestarts: 2, RestartBackoff: toml.Duration(time.Millisecond)}, func() (SupervisedInstance, error) {
+		started++
+		return &crashingInstance{err: errors.New("oom-killed")}, nil
+	}, discardLogger())
+	s.Sleep = func(time.Duration) {}
+
+	err := s.Run()
+	if err == nil {
+		t.Fatal("expected the supervisor to quarantine the function")
+	}
+	if s.Restarts() != 3 {
+		t.Fatalf("expected 3 restarts (initial start counts as restart 1 on first crash, ending at MaxRestarts+1), got %d", s.Restarts())
+	}
+	if started != 3 {
+		t.Fatalf("expected the agent to be started 3 times (1 + MaxRestarts), got %d", started)
+	}
+}
+
+func TestSupervisor_RecoversBeforeExhaustingBudget(t *testing.T) {
+	attempt := 0
+	s := NewSupervisor("outliers", FunctionConfig{MaxRestarts: 5, RestartBackoff: toml.Duration(time.Millisecond)}, func() (SupervisedInstance, error) {
+		attempt++
+		if attempt <= 2 {
+			return &crashingInstance{err: errors.New("transient crash")}, nil
+		}
+		return &crashingInstance{err: nil}, nil
+	}, discardLogger())
+	s.Sleep = func(time.Duration) {}
+
+	if err := s.Run(); err != nil {
+		t.Fatalf("expected a clean exit once the agent stops crashing, got %v", err)
+	}
+	if s.Quarantined() != nil {
+		t.Fatal("expected the function not to be quarantined")
+	}
+	if s.Restarts() != 2 {
+		t.Fatalf("expected 2 restarts before recovery, got %d", s.Restarts())
+	}
+}
+
+func TestSupervisor_StopPreventsRestart(t *testing.T) {
+	s := NewSupervisor("outliers", FunctionConfig{MaxRestarts: 5}, func() (SupervisedInstance, error) {
+		return &crashingInstance{err: errors.New("killed by Stop")}, nil
+	}, discardLogger())
+	s.Sleep = func(time.Duration) {}
+	s.Stop()
+
+	if err := s.Run(); err != nil {
+		t.Fatalf("expected Stop to suppress the restart/quarantine error, got %v", err)
+	}
+}
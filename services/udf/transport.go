@@ -0,0 +1,118 @@
+package udf
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"time"
+)
+
+func loadCAPool(caFile string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("udf: failed to parse CA file " + caFile)
+	}
+	return pool, nil
+}
+
+// Conn is the length-prefixed protobuf connection a UDF transport hands
+// back once connected, regardless of whether it is backed by a child
+// process's stdio, a Unix socket, or a TCP/TLS connection.
+type Conn interface {
+	io.ReadWriteCloser
+}
+
+// Transport establishes a Conn to a UDF agent. The exec- and
+// socket-backed transports are implemented alongside the agent launcher;
+// remoteTransport is the TCP/TLS transport added here so an agent can run
+// as an independent, possibly remote, service.
+type Transport interface {
+	// Dial blocks until a Conn is established or the transport gives up,
+	// returning an error in that case.
+	Dial() (Conn, error)
+}
+
+// remoteTransport dials a UDF agent listening on Address, optionally
+// wrapping the connection in TLS, and retries with exponential backoff
+// until Dial succeeds or the transport is stopped.
+type remoteTransport struct {
+	address string
+	tls     *tls.Config
+	timeout time.Duration
+
+	// InitialBackoff and MaxBackoff bound the retry delay between dial
+	// attempts; both have sane defaults if left zero.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	stopc chan struct{}
+}
+
+// NewRemoteTransport builds the TCP/TLS transport for a FunctionConfig
+// that sets Address. f must already have passed Validate.
+func NewRemoteTransport(f FunctionConfig) (Transport, error) {
+	tc, err := f.TLS.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	return &remoteTransport{
+		address:        f.Address,
+		tls:            tc,
+		timeout:        time.Duration(f.Timeout),
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		stopc:          make(chan struct{}),
+	}, nil
+}
+
+// Stop unblocks any in-progress Dial retry loop, causing it to return an
+// error instead of continuing to back off and retry.
+func (t *remoteTransport) Stop() {
+	close(t.stopc)
+}
+
+func (t *remoteTransport) Dial() (Conn, error) {
+	backoff := t.InitialBackoff
+	if backoff <= 0 {
+		backoff = 100 * time.Millisecond
+	}
+	maxBackoff := t.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 30 * time.Second
+	}
+
+	for {
+		conn, err := t.dialOnce()
+		if err == nil {
+			return conn, nil
+		}
+
+		jitter := time.Duration(rand.Int63n(int64(backoff) + 1))
+		select {
+		case <-time.After(backoff + jitter):
+		case <-t.stopc:
+			return nil, errors.New("udf: transport stopped while dialing " + t.address)
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+func (t *remoteTransport) dialOnce() (Conn, error) {
+	dialer := &net.Dialer{Timeout: t.timeout}
+	if t.tls != nil {
+		return tls.DialWithDialer(dialer, "tcp", t.address, t.tls)
+	}
+	return dialer.Dial("tcp", t.address)
+}
@@ -0,0 +1,194 @@
+package udf
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
+
+// selfSignedCert generates an in-memory self-signed certificate for
+// 127.0.0.1, so tests don't need to shell out to openssl or check in a
+// fixture certificate.
+func selfSignedCert(t *testing.T) tls.Certificate {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// mirrorListener accepts a single connection and echoes back whatever it
+// reads, standing in for the mirror example agent over a real socket.
+func mirrorListener(t *testing.T, l net.Listener) {
+	t.Helper()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		io.Copy(conn, conn)
+	}()
+}
+
+func TestRemoteTransport_TCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	mirrorListener(t, l)
+
+	tr, err := NewRemoteTransport(FunctionConfig{Address: l.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := tr.Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "ping" {
+		t.Fatalf("expected echoed bytes, got %q", buf)
+	}
+}
+
+func TestRemoteTransport_TLS(t *testing.T) {
+	cert := selfSignedCert(t)
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	mirrorListener(t, l)
+
+	tr, err := NewRemoteTransport(FunctionConfig{
+		Address: l.Addr().String(),
+		TLS: TLSConfig{
+			ServerName:         "127.0.0.1",
+			InsecureSkipVerify: true,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := tr.Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "ping" {
+		t.Fatalf("expected echoed bytes over TLS, got %q", buf)
+	}
+}
+
+func TestRemoteTransport_RetriesUntilListenerIsUp(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close() // nothing listening yet
+
+	tr := &remoteTransport{
+		address:        addr,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		stopc:          make(chan struct{}),
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := tr.Dial()
+		done <- err
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	l2, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("could not re-bind %s: %v", addr, err)
+	}
+	defer l2.Close()
+	mirrorListener(t, l2)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected Dial to eventually succeed, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for transport to retry and connect")
+	}
+}
+
+func TestFunctionConfig_Validate(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  FunctionConfig
+		ok   bool
+	}{
+		{"prog only", FunctionConfig{Prog: "./agent"}, true},
+		{"socket only", FunctionConfig{Socket: "/tmp/agent.sock"}, true},
+		{"address only", FunctionConfig{Address: "127.0.0.1:9000"}, true},
+		{"none", FunctionConfig{}, false},
+		{"both prog and address", FunctionConfig{Prog: "./agent", Address: "127.0.0.1:9000"}, false},
+		{"tls without address", FunctionConfig{Prog: "./agent", TLS: TLSConfig{ServerName: "x"}}, false},
+	}
+	for _, c := range cases {
+		err := c.cfg.Validate()
+		if c.ok && err != nil {
+			t.Errorf("%s: expected valid, got error: %v", c.name, err)
+		}
+		if !c.ok && err == nil {
+			t.Errorf("%s: expected an error, got none", c.name)
+		}
+	}
+}